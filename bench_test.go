@@ -148,6 +148,23 @@ func BenchmarkCodeMarshal(b *testing.B) {
 	b.SetBytes(int64(len(codeJSON)))
 }
 
+func BenchmarkCodeEstimateSize(b *testing.B) {
+	b.ReportAllocs()
+	if codeJSON == nil {
+		b.StopTimer()
+		codeInit()
+		b.StartTimer()
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if EstimateSize(&codeStruct) == 0 {
+				b.Fatal("EstimateSize = 0, want the size of codeJSON")
+			}
+		}
+	})
+	b.SetBytes(int64(len(codeJSON)))
+}
+
 func BenchmarkCodeMarshalError(b *testing.B) {
 	b.ReportAllocs()
 	if codeJSON == nil {
@@ -348,6 +365,23 @@ func BenchmarkCodeUnmarshal(b *testing.B) {
 	b.SetBytes(int64(len(codeJSON)))
 }
 
+func BenchmarkCodeValid(b *testing.B) {
+	b.ReportAllocs()
+	if codeJSON == nil {
+		b.StopTimer()
+		codeInit()
+		b.StartTimer()
+	}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if !Valid(codeJSON) {
+				b.Fatal("Valid = false, want true")
+			}
+		}
+	})
+	b.SetBytes(int64(len(codeJSON)))
+}
+
 func BenchmarkCodeUnmarshalReuse(b *testing.B) {
 	b.ReportAllocs()
 	if codeJSON == nil {
@@ -480,7 +514,7 @@ func BenchmarkTypeFieldsCache(b *testing.B) {
 
 	// clearClear clears the cache. Other JSON operations, must not be running.
 	clearCache := func() {
-		fieldCache = sync.Map{}
+		fieldCache.clear()
 	}
 
 	// MissTypes tests the performance of repeated cache misses.
@@ -558,6 +592,28 @@ func BenchmarkEncoderEncode(b *testing.B) {
 	})
 }
 
+func BenchmarkAppendNoEscape(b *testing.B) {
+	b.ReportAllocs()
+	buf := make([]byte, 0, 256)
+	for i := range b.N {
+		v := noEscapeSample{"name", i, 0.5, true}
+		var err error
+		if buf, err = AppendNoEscape(buf[:0], &v); err != nil {
+			b.Fatalf("AppendNoEscape error: %v", err)
+		}
+	}
+}
+
+func BenchmarkMarshalLocal(b *testing.B) {
+	b.ReportAllocs()
+	for i := range b.N {
+		v := noEscapeSample{"name", i, 0.5, true}
+		if _, err := Marshal(&v); err != nil {
+			b.Fatalf("Marshal error: %v", err)
+		}
+	}
+}
+
 func BenchmarkNumberIsValid(b *testing.B) {
 	s := "-61657.61667E+61673"
 	for i := 0; i < b.N; i++ {
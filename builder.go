@@ -0,0 +1,209 @@
+package json
+
+import "fmt"
+
+// An ObjectBuilder builds a JSON object member by member, encoding each
+// value as it is added rather than collecting the members in a map.
+// Its methods return the builder so that calls can be chained:
+//
+//	b, err := json.NewObjectBuilder().
+//		Put("id", 1).
+//		Put("tags", []string{"a", "b"}).
+//		RawField("extra", json.RawMessage(`{"x": true}`)).
+//		Bytes()
+//
+// The first error encoding a value is kept and returned by
+// [ObjectBuilder.Bytes] or [ObjectBuilder.EncodeTo]; members added after it
+// are ignored. An ObjectBuilder encodes as the object built so far, so it
+// may be a value in another builder or passed to [Marshal].
+type ObjectBuilder struct {
+	b builder
+}
+
+// NewObjectBuilder returns a builder for an empty object.
+func NewObjectBuilder() *ObjectBuilder {
+	ob := new(ObjectBuilder)
+	ob.b.e.WriteByte('{')
+	return ob
+}
+
+// Put adds a member with the given name and the JSON encoding of v,
+// as [Marshal] encodes it. Names are not checked for duplicates.
+func (ob *ObjectBuilder) Put(name string, v any) *ObjectBuilder {
+	if ob.b.key(name) {
+		ob.b.value(v)
+	}
+	return ob
+}
+
+// RawField adds a member with the given name and the JSON text raw, which
+// is compacted as [Marshal] writes a [RawMessage]. Invalid JSON in raw is
+// an error.
+func (ob *ObjectBuilder) RawField(name string, raw []byte) *ObjectBuilder {
+	if ob.b.key(name) {
+		ob.b.raw(raw)
+	}
+	return ob
+}
+
+// Len returns the number of members added.
+func (ob *ObjectBuilder) Len() int {
+	return ob.b.n
+}
+
+// Bytes returns the encoding of the object, or the first error encoding
+// one of its members. The builder may still be added to afterwards.
+func (ob *ObjectBuilder) Bytes() ([]byte, error) {
+	return ob.b.bytes('}')
+}
+
+// EncodeTo writes the object to enc, as [Encoder.Encode] writes a
+// [RawMessage].
+func (ob *ObjectBuilder) EncodeTo(enc *Encoder) error {
+	return ob.b.encodeTo(enc, '}')
+}
+
+// MarshalJSON implements [Marshaler] by returning [ObjectBuilder.Bytes].
+func (ob *ObjectBuilder) MarshalJSON() ([]byte, error) {
+	return ob.Bytes()
+}
+
+// An ArrayBuilder builds a JSON array element by element, as
+// [ObjectBuilder] builds an object.
+type ArrayBuilder struct {
+	b builder
+}
+
+// NewArrayBuilder returns a builder for an empty array.
+func NewArrayBuilder() *ArrayBuilder {
+	ab := new(ArrayBuilder)
+	ab.b.e.WriteByte('[')
+	return ab
+}
+
+// Append adds an element with the JSON encoding of v, as [Marshal]
+// encodes it.
+func (ab *ArrayBuilder) Append(v any) *ArrayBuilder {
+	if ab.b.next() {
+		ab.b.value(v)
+	}
+	return ab
+}
+
+// AppendRaw adds an element with the JSON text raw, as
+// [ObjectBuilder.RawField] adds a member.
+func (ab *ArrayBuilder) AppendRaw(raw []byte) *ArrayBuilder {
+	if ab.b.next() {
+		ab.b.raw(raw)
+	}
+	return ab
+}
+
+// Len returns the number of elements added.
+func (ab *ArrayBuilder) Len() int {
+	return ab.b.n
+}
+
+// Bytes returns the encoding of the array, or the first error encoding
+// one of its elements. The builder may still be added to afterwards.
+func (ab *ArrayBuilder) Bytes() ([]byte, error) {
+	return ab.b.bytes(']')
+}
+
+// EncodeTo writes the array to enc, as [Encoder.Encode] writes a
+// [RawMessage].
+func (ab *ArrayBuilder) EncodeTo(enc *Encoder) error {
+	return ab.b.encodeTo(enc, ']')
+}
+
+// MarshalJSON implements [Marshaler] by returning [ArrayBuilder.Bytes].
+func (ab *ArrayBuilder) MarshalJSON() ([]byte, error) {
+	return ab.Bytes()
+}
+
+// A builder holds the open object or array of an ObjectBuilder or
+// ArrayBuilder, without its closing delimiter.
+type builder struct {
+	e   encodeState
+	n   int // number of members or elements
+	err error
+}
+
+// next starts the next member or element, reporting whether to add it.
+func (b *builder) next() bool {
+	if b.err != nil {
+		return false
+	}
+	if b.n > 0 {
+		b.e.WriteByte(',')
+	}
+	b.n++
+	return true
+}
+
+// key starts the next member with the given name.
+func (b *builder) key(name string) bool {
+	if !b.next() {
+		return false
+	}
+	b.e.Write(appendString(b.e.AvailableBuffer(), name, true))
+	b.e.WriteByte(':')
+	return true
+}
+
+func (b *builder) value(v any) {
+	switch v := v.(type) {
+	case *ObjectBuilder:
+		b.nested(&v.b, '}')
+		return
+	case *ArrayBuilder:
+		b.nested(&v.b, ']')
+		return
+	}
+	e := newEncodeState()
+	defer putEncodeState(e, maxPooledBufferSize)
+	if err := e.marshal(v, encOpts{escapeHTML: true}); err != nil {
+		b.err = err
+		return
+	}
+	b.e.Write(e.Bytes())
+}
+
+func (b *builder) raw(raw []byte) {
+	out, err := appendCompact(b.e.AvailableBuffer(), raw, true)
+	if err != nil {
+		b.err = fmt.Errorf("json: invalid raw value in builder: %w", err)
+		return
+	}
+	b.e.Write(out)
+}
+
+// nested writes the contents of the builder sub, closed by end.
+func (b *builder) nested(sub *builder, end byte) {
+	if sub == b {
+		b.err = fmt.Errorf("json: builder added to itself")
+		return
+	}
+	if sub.err != nil {
+		b.err = sub.err
+		return
+	}
+	b.e.Write(sub.e.Bytes())
+	b.e.WriteByte(end)
+}
+
+func (b *builder) bytes(end byte) ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	buf := make([]byte, 0, b.e.Len()+1)
+	return append(append(buf, b.e.Bytes()...), end), nil
+}
+
+func (b *builder) encodeTo(enc *Encoder, end byte) error {
+	buf, err := b.bytes(end)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(RawMessage(buf))
+}
@@ -0,0 +1,139 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestObjectBuilder(t *testing.T) {
+	tests := []struct {
+		CaseName
+		build func() ([]byte, error)
+		want  string
+	}{{
+		CaseName: Name("empty object"),
+		build:    NewObjectBuilder().Bytes,
+		want:     `{}`,
+	}, {
+		CaseName: Name("empty array"),
+		build:    NewArrayBuilder().Bytes,
+		want:     `[]`,
+	}, {
+		CaseName: Name("object"),
+		build: NewObjectBuilder().
+			Put("id", 1).
+			Put("a<b", "x&y").
+			Put("tags", []string{"a", "b"}).
+			Put("none", nil).
+			RawField("extra", []byte(` { "x" : [ true ] } `)).
+			Bytes,
+		want: `{"id":1,"a\u003cb":"x\u0026y","tags":["a","b"],"none":null,"extra":{"x":[true]}}`,
+	}, {
+		CaseName: Name("array"),
+		build: NewArrayBuilder().
+			Append(1.5).
+			AppendRaw([]byte(`"raw"`)).
+			Append(struct{ A int }{2}).
+			Bytes,
+		want: `[1.5,"raw",{"A":2}]`,
+	}, {
+		CaseName: Name("nested"),
+		build: NewObjectBuilder().
+			Put("o", NewObjectBuilder().Put("a", 1)).
+			Put("l", NewArrayBuilder().Append(NewArrayBuilder()).Append(2)).
+			Put("m", map[string]any{"b": NewObjectBuilder().Put("c", true)}).
+			Bytes,
+		want: `{"o":{"a":1},"l":[[],2],"m":{"b":{"c":true}}}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := tt.build()
+			if err != nil {
+				t.Fatalf("%s: Bytes error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Bytes:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestObjectBuilderReuse(t *testing.T) {
+	ob := NewObjectBuilder().Put("a", 1)
+	first, _ := ob.Bytes()
+	ob.Put("b", 2)
+	second, _ := ob.Bytes()
+	if string(first) != `{"a":1}` || string(second) != `{"a":1,"b":2}` {
+		t.Errorf("Bytes: got %s then %s, want {\"a\":1} then {\"a\":1,\"b\":2}", first, second)
+	}
+	if ob.Len() != 2 {
+		t.Errorf("Len: got %d, want 2", ob.Len())
+	}
+}
+
+func TestBuilderError(t *testing.T) {
+	ob := NewObjectBuilder().Put("f", func() {}).Put("a", 1)
+	var ute *UnsupportedTypeError
+	if _, err := ob.Bytes(); !errors.As(err, &ute) {
+		t.Errorf("Bytes error: got %v, want *UnsupportedTypeError", err)
+	}
+	var se *SyntaxError
+	if _, err := NewArrayBuilder().AppendRaw([]byte(`{`)).Bytes(); !errors.As(err, &se) {
+		t.Errorf("Bytes error: got %v, want *SyntaxError", err)
+	}
+	// An error in a nested builder is reported by the outer one.
+	inner := NewArrayBuilder().AppendRaw([]byte(`x`))
+	if _, err := NewObjectBuilder().Put("x", inner).Bytes(); !errors.As(err, &se) {
+		t.Errorf("Bytes error: got %v, want *SyntaxError", err)
+	}
+	if _, err := Marshal(inner); !errors.As(err, &se) {
+		t.Errorf("Marshal error: got %v, want *SyntaxError", err)
+	}
+	ab := NewArrayBuilder()
+	if _, err := ab.Append(ab).Bytes(); err == nil {
+		t.Error("Bytes error: got nil, want error for a builder added to itself")
+	}
+}
+
+type builderList struct {
+	Next *builderList `json:",omitempty"`
+}
+
+func TestBuilderDeepValue(t *testing.T) {
+	// Past 1000 levels of pointers, encoding checks for cycles.
+	var list *builderList
+	for range 1100 {
+		list = &builderList{list}
+	}
+	want, err := Marshal(list)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	got, err := NewArrayBuilder().Append(list).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes error: %v", err)
+	}
+	if string(got) != "["+string(want)+"]" {
+		t.Errorf("Bytes of deep value differs from Marshal")
+	}
+	if _, err := NewObjectBuilder().Put("l", list).Bytes(); err != nil {
+		t.Errorf("Bytes error: %v", err)
+	}
+}
+
+func TestBuilderEncodeTo(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := NewObjectBuilder().Put("a", []int{1}).EncodeTo(enc); err != nil {
+		t.Fatalf("EncodeTo error: %v", err)
+	}
+	if err := NewArrayBuilder().EncodeTo(enc); err != nil {
+		t.Fatalf("EncodeTo error: %v", err)
+	}
+	want := "{\n  \"a\": [\n    1\n  ]\n}\n[]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("EncodeTo:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+}
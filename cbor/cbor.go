@@ -0,0 +1,415 @@
+// Package cbor converts between CBOR (RFC 8949) and the JSON token stream of
+// package json, for use with [json.Transcode].
+//
+// Only the part of CBOR that corresponds to JSON is supported. When reading,
+// byte strings become base64-encoded strings, as []byte values are encoded
+// by package json, tags are ignored, undefined becomes null, and integer map
+// keys become their decimal strings. When writing, numbers without a
+// fraction become integers, and arrays and maps are written with
+// indefinite lengths, as their lengths are not known in advance.
+package cbor
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode/utf8"
+
+	json "github.com/crunk1/gojson"
+)
+
+// Major types.
+const (
+	majorUint = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+const (
+	infoIndefinite = 31
+	breakCode      = 0xff
+)
+
+// A Reader reads CBOR data items from an input stream as JSON tokens.
+type Reader struct {
+	r     *bufio.Reader
+	stack []level
+}
+
+// A level is an array or map being read.
+type level struct {
+	isMap bool
+	n     int // number of items, keys and values counted separately; -1 if indefinite
+	items int // number of items read
+}
+
+// NewReader returns a Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Token returns the next token, of a type returned by [json.Decoder.Token]
+// with UseNumber set: integers are returned as a [json.Number] and floating
+// point numbers as a float64. A sequence of data items may be read.
+// At the end of the input, Token returns nil, io.EOF.
+func (r *Reader) Token() (json.Token, error) {
+	if n := len(r.stack); n > 0 && r.stack[n-1].n >= 0 && r.stack[n-1].items == r.stack[n-1].n {
+		return r.end(), nil
+	}
+	b, err := r.r.ReadByte()
+	if err != nil {
+		if err == io.EOF && len(r.stack) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if b == breakCode {
+		if n := len(r.stack); n == 0 || r.stack[n-1].n >= 0 || r.stack[n-1].isMap && r.stack[n-1].items%2 != 0 {
+			return nil, errors.New("cbor: unexpected break code")
+		}
+		return r.end(), nil
+	}
+	n := len(r.stack)
+	isKey := n > 0 && r.stack[n-1].isMap && r.stack[n-1].items%2 == 0
+	t, err := r.item(b)
+	if err != nil {
+		return nil, r.unexpectedEOF(err)
+	}
+	switch t.(type) {
+	case json.Delim:
+		if isKey {
+			return nil, errors.New("cbor: unsupported map key of array or map type")
+		}
+		return t, nil // counted when it ends
+	case string:
+	case json.Number:
+		if isKey {
+			t = string(t.(json.Number))
+		}
+	default:
+		if isKey {
+			return nil, fmt.Errorf("cbor: unsupported map key %v", t)
+		}
+	}
+	if n > 0 {
+		r.stack[n-1].items++
+	}
+	return t, nil
+}
+
+// end returns the closing delimiter of the innermost array or map.
+func (r *Reader) end() json.Token {
+	l := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if len(r.stack) > 0 {
+		r.stack[len(r.stack)-1].items++
+	}
+	if l.isMap {
+		return json.Delim('}')
+	}
+	return json.Delim(']')
+}
+
+// item reads the data item whose initial byte is b, skipping any tags.
+func (r *Reader) item(b byte) (json.Token, error) {
+	for b>>5 == majorTag {
+		if _, err := r.argument(b & 31); err != nil {
+			return nil, err
+		}
+		var err error
+		if b, err = r.r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	major, info := b>>5, b&31
+	if major == majorSimple {
+		return r.simple(info)
+	}
+	if info == infoIndefinite {
+		switch major {
+		case majorBytes, majorText:
+			return r.chunks(major)
+		case majorArray, majorMap:
+			r.stack = append(r.stack, level{isMap: major == majorMap, n: -1})
+			return r.begin(major), nil
+		}
+		return nil, fmt.Errorf("cbor: invalid indefinite length for major type %d", major)
+	}
+	arg, err := r.argument(info)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case majorNegInt:
+		if arg < math.MaxInt64 {
+			return json.Number(strconv.FormatInt(-1-int64(arg), 10)), nil
+		}
+		n := new(big.Int).SetUint64(arg)
+		return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+	case majorBytes:
+		data, err := r.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case majorText:
+		data, err := r.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(data) {
+			return nil, errors.New("cbor: invalid UTF-8 in text string")
+		}
+		return string(data), nil
+	default: // majorArray, majorMap
+		if arg > math.MaxInt32 {
+			return nil, fmt.Errorf("cbor: array or map length %d too large", arg)
+		}
+		n := int(arg)
+		if major == majorMap {
+			n *= 2
+		}
+		r.stack = append(r.stack, level{isMap: major == majorMap, n: n})
+		return r.begin(major), nil
+	}
+}
+
+func (r *Reader) begin(major byte) json.Token {
+	if major == majorMap {
+		return json.Delim('{')
+	}
+	return json.Delim('[')
+}
+
+// argument reads the argument of a data item with additional information info.
+func (r *Reader) argument(info byte) (uint64, error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		size = 1 << (info - 24)
+	default:
+		return 0, fmt.Errorf("cbor: invalid additional information %d", info)
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r.r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// simple reads a simple value or floating point number.
+func (r *Reader) simple(info byte) (json.Token, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null, undefined
+		return nil, nil
+	case 25, 26, 27:
+		arg, err := r.argument(info)
+		if err != nil {
+			return nil, err
+		}
+		switch info {
+		case 25:
+			return halfToFloat(uint16(arg)), nil
+		case 26:
+			return float64(math.Float32frombits(uint32(arg))), nil
+		}
+		return math.Float64frombits(arg), nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+// bytes reads the n bytes of a definite-length string.
+func (r *Reader) bytes(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("cbor: string length %d too large", n)
+	}
+	// Read the string in pieces, rather than trusting n to allocate it.
+	data, err := io.ReadAll(io.LimitReader(r.r, int64(n)))
+	if err == nil && uint64(len(data)) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
+
+// chunks reads the chunks of an indefinite-length string of the given major type.
+func (r *Reader) chunks(major byte) (json.Token, error) {
+	var data []byte
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == breakCode {
+			break
+		}
+		if b>>5 != major || b&31 == infoIndefinite {
+			return nil, errors.New("cbor: invalid chunk in indefinite-length string")
+		}
+		arg, err := r.argument(b & 31)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := r.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk...)
+	}
+	if major == majorBytes {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	if !utf8.Valid(data) {
+		return nil, errors.New("cbor: invalid UTF-8 in text string")
+	}
+	return string(data), nil
+}
+
+// unexpectedEOF reports the end of the input within a data item
+// as io.ErrUnexpectedEOF.
+func (r *Reader) unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// halfToFloat converts an IEEE 754 half precision number to a float64.
+func halfToFloat(h uint16) float64 {
+	exp, mant := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		f = -f
+	}
+	return f
+}
+
+// A Writer writes JSON tokens to an output stream as CBOR data items.
+type Writer struct {
+	w     io.Writer
+	stack []json.Delim
+	buf   []byte
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteToken writes the next token, which must be of a type returned by
+// [json.Decoder.Token]. Writing is not buffered beyond a single token.
+func (w *Writer) WriteToken(t json.Token) error {
+	b := w.buf[:0]
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			b = append(b, majorArray<<5|infoIndefinite)
+			w.stack = append(w.stack, ']')
+		case '{':
+			b = append(b, majorMap<<5|infoIndefinite)
+			w.stack = append(w.stack, '}')
+		case ']', '}':
+			if len(w.stack) == 0 || w.stack[len(w.stack)-1] != t {
+				return fmt.Errorf("cbor: unexpected %v token", t)
+			}
+			w.stack = w.stack[:len(w.stack)-1]
+			b = append(b, breakCode)
+		default:
+			return fmt.Errorf("cbor: invalid delimiter %v", t)
+		}
+	case nil:
+		b = append(b, majorSimple<<5|22)
+	case bool:
+		if t {
+			b = append(b, majorSimple<<5|21)
+		} else {
+			b = append(b, majorSimple<<5|20)
+		}
+	case string:
+		b = appendHead(b, majorText, uint64(len(t)))
+		b = append(b, t...)
+	case float64:
+		b = appendFloat(b, t)
+	case json.Number:
+		if n, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+			b = appendInt(b, n)
+		} else if n, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			b = appendHead(b, majorUint, n)
+		} else if f, err := t.Float64(); err == nil {
+			b = appendFloat(b, f)
+		} else {
+			return fmt.Errorf("cbor: cannot write number %s", t)
+		}
+	default:
+		return fmt.Errorf("cbor: unsupported token type %T", t)
+	}
+	w.buf = b
+	_, err := w.w.Write(b)
+	return err
+}
+
+// appendHead appends the head of a data item of the given major type and argument.
+func appendHead(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+}
+
+func appendInt(b []byte, n int64) []byte {
+	if n < 0 {
+		return appendHead(b, majorNegInt, uint64(-1-n))
+	}
+	return appendHead(b, majorUint, uint64(n))
+}
+
+// appendFloat appends f as an integer if it has no fraction and is within the
+// range of int64, and otherwise as a single or double precision number,
+// whichever holds it exactly.
+func appendFloat(b []byte, f float64) []byte {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 && !(f == 0 && math.Signbit(f)) {
+		return appendInt(b, int64(f))
+	}
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		return binary.BigEndian.AppendUint32(append(b, majorSimple<<5|26), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(b, majorSimple<<5|27), math.Float64bits(f))
+}
@@ -0,0 +1,141 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	json "github.com/crunk1/gojson"
+)
+
+// toJSON transcodes the CBOR data to JSON text.
+func toJSON(data []byte) (string, error) {
+	var buf strings.Builder
+	err := json.Transcode(json.NewEncoder(&buf), NewReader(bytes.NewReader(data)))
+	return buf.String(), err
+}
+
+func TestReader(t *testing.T) {
+	// Examples from RFC 8949, Appendix A.
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"00", "0"},
+		{"17", "23"},
+		{"1818", "24"},
+		{"1903e8", "1000"},
+		{"1b000000e8d4a51000", "1000000000000"},
+		{"1bffffffffffffffff", "18446744073709551615"},
+		{"3bffffffffffffffff", "-18446744073709551616"},
+		{"20", "-1"},
+		{"3903e7", "-1000"},
+		{"f90000", "0"},
+		{"f93c00", "1"},
+		{"f93e00", "1.5"},
+		{"f97bff", "65504"},
+		{"fa47c35000", "100000"},
+		{"fb3ff199999999999a", "1.1"},
+		{"fb7e37e43c8800759c", "1e+300"},
+		{"f90001", "5.960464477539063e-8"},
+		{"f4", "false"},
+		{"f5", "true"},
+		{"f6", "null"},
+		{"f7", "null"},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`},
+		{"4401020304", `"AQIDBA=="`},
+		{"60", `""`},
+		{"6449455446", `"IETF"`},
+		{"62225c", `"\"\\"`},
+		{"63e6b0b4", `"水"`},
+		{"80", "[]"},
+		{"83010203", "[1,2,3]"},
+		{"8301820203820405", "[1,[2,3],[4,5]]"},
+		{"a0", "{}"},
+		{"a201020304", `{"1":2,"3":4}`},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`},
+		{"5f42010243030405ff", `"AQIDBAU="`},
+		{"7f657374726561646d696e67ff", `"streaming"`},
+		{"9fff", "[]"},
+		{"9f018202039f0405ffff", "[1,[2,3],[4,5]]"},
+		{"bf61610161629f0203ffff", `{"a":1,"b":[2,3]}`},
+		{"0102", "1\n2"},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+		got, err := toJSON(data)
+		if err != nil {
+			t.Errorf("%s: Token error: %v", tt.in, err)
+			continue
+		}
+		if want := tt.want + "\n"; got != want {
+			t.Errorf("%s: Token:\n\tgot:  %q\n\twant: %q", tt.in, got, want)
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	tests := []string{
+		"18",       // truncated argument
+		"830102",   // truncated array
+		"9f01",     // unterminated array
+		"62c328",   // invalid UTF-8
+		"a1800102", // array key
+		"ff",       // unexpected break
+		"1c",       // reserved additional information
+	}
+	for _, in := range tests {
+		data, _ := hex.DecodeString(in)
+		if _, err := toJSON(data); err == nil {
+			t.Errorf("%s: Token error: got nil, want error", in)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`0`, "00"},
+		{`1000000000000`, "1b000000e8d4a51000"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1000`, "3903e7"},
+		{`1.5`, "fa3fc00000"},
+		{`1.1`, "fb3ff199999999999a"},
+		{`1e300`, "fb7e37e43c8800759c"},
+		{`"IETF"`, "6449455446"},
+		{`[1, [2, 3], {"a": null}]`, "9f019f0203ffbf6161f6ffff"},
+		{`true false`, "f5f4"},
+	}
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.in))
+		dec.UseNumber()
+		var buf bytes.Buffer
+		if err := json.Transcode(NewWriter(&buf), dec); err != nil {
+			t.Errorf("%s: WriteToken error: %v", tt.in, err)
+			continue
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+			t.Errorf("%s: WriteToken:\n\tgot:  %s\n\twant: %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := `{"a":[1,-2,3.25,12345678901234567890],"b":{"c":"é","d":[]},"e":null}` + "\n"
+	dec := json.NewDecoder(strings.NewReader(in))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := json.Transcode(NewWriter(&buf), dec); err != nil {
+		t.Fatalf("Transcode error: %v", err)
+	}
+	got, err := toJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Transcode error: %v", err)
+	}
+	if got != in {
+		t.Errorf("Transcode:\n\tgot:  %s\n\twant: %s", got, in)
+	}
+}
@@ -0,0 +1,94 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var codecRegistry struct {
+	sync.RWMutex
+	byName map[string]*fieldFormat
+}
+
+// RegisterCodec records a pair of functions under name to encode and decode
+// the struct fields of type T, or pointers to it, whose tags select them
+// with the codec option, so that a field can have its own representation
+// without a type of its own implementing [Marshaler] and [Unmarshaler]:
+//
+//	Tags []string `json:"tags,codec:csvlist"`
+//
+// marshal returns the JSON encoding of a value, which is checked and
+// compacted as the output of a MarshalJSON method is. unmarshal decodes a
+// JSON value, which is not null, into the value its second argument points
+// to. A nil pointer encodes as null, and null leaves the field unchanged or
+// sets a pointer to nil. An error decoding a value is reported as a
+// [SemanticError] wrapping the error returned by unmarshal.
+//
+// RegisterCodec is meant to be called from init functions, before values
+// of the types using the codec are encoded or decoded. It panics if name
+// is already registered, if name is empty or holds a comma, if T is a
+// pointer type, or if either function is nil.
+func RegisterCodec[T any](name string, marshal func(T) ([]byte, error), unmarshal func([]byte, *T) error) {
+	if name == "" || strings.Contains(name, ",") {
+		panic(fmt.Sprintf("json: invalid codec name %q", name))
+	}
+	if marshal == nil || unmarshal == nil {
+		panic("json: RegisterCodec of nil function")
+	}
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Pointer {
+		panic(fmt.Sprintf("json: RegisterCodec of pointer type %s", t))
+	}
+	f := &fieldFormat{
+		name: name,
+		encode: func(e *encodeState, v reflect.Value, opts encOpts) {
+			b, err := marshal(v.Interface().(T))
+			if err == nil {
+				e.Grow(len(b))
+				var out []byte
+				out, err = appendCompact(e.AvailableBuffer(), b, opts.escapeHTML)
+				e.Write(out)
+			}
+			if err != nil {
+				e.error(&MarshalerError{t, err, "codec " + name})
+			}
+		},
+		decode: func(data []byte, v reflect.Value, _ *ContextValues) error {
+			if err := unmarshal(data, v.Addr().Interface().(*T)); err != nil {
+				return newSemanticError(0, t, err, "json: cannot unmarshal %s into Go value of type %s with codec %s: %v", data, t, name, err)
+			}
+			return nil
+		},
+		typ: t,
+	}
+	codecRegistry.Lock()
+	defer codecRegistry.Unlock()
+	if _, ok := codecRegistry.byName[name]; ok {
+		panic(fmt.Sprintf("json: registering duplicate codecs for %q", name))
+	}
+	if codecRegistry.byName == nil {
+		codecRegistry.byName = make(map[string]*fieldFormat)
+	}
+	codecRegistry.byName[name] = f
+}
+
+// lookupCodec returns the codec registered under name for fields of type t,
+// following pointers.
+func lookupCodec(t reflect.Type, name string) (*fieldFormat, error) {
+	codecRegistry.RLock()
+	f, ok := codecRegistry.byName[name]
+	codecRegistry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q", name)
+	}
+	base := t
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	if base != f.typ {
+		return nil, fmt.Errorf("codec %q applies to type %q", name, f.typ.String())
+	}
+	return f, nil
+}
@@ -0,0 +1,131 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func init() {
+	RegisterCodec("csvlist",
+		func(v []string) ([]byte, error) {
+			return Marshal(strings.Join(v, ","))
+		},
+		func(data []byte, v *[]string) error {
+			var s string
+			if err := Unmarshal(data, &s); err != nil {
+				return err
+			}
+			*v = strings.Split(s, ",")
+			return nil
+		})
+	RegisterCodec("epochstring",
+		func(v int64) ([]byte, error) {
+			if v < 0 {
+				return nil, errors.New("negative epoch")
+			}
+			return []byte(strconv.Quote(strconv.FormatInt(v, 10))), nil
+		},
+		func(data []byte, v *int64) error {
+			s, err := strconv.Unquote(string(data))
+			if err != nil {
+				return err
+			}
+			*v, err = strconv.ParseInt(s, 10, 64)
+			return err
+		})
+}
+
+type codecFields struct {
+	Tags  []string `json:"tags,codec:csvlist"`
+	When  *int64   `json:"when,codec:epochstring"`
+	Other []string `json:"other"`
+}
+
+func TestCodec(t *testing.T) {
+	when := int64(1700000000)
+	v := codecFields{Tags: []string{"a", "b"}, When: &when, Other: []string{"c"}}
+	b, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"tags":"a,b","when":"1700000000","other":["c"]}`
+	if string(b) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", b, want)
+	}
+	var got codecFields
+	if err := Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if fmt.Sprint(got.Tags, *got.When, got.Other) != fmt.Sprint(v.Tags, *v.When, v.Other) {
+		t.Errorf("Unmarshal: got %v %v %v", got.Tags, *got.When, got.Other)
+	}
+
+	// Nil pointers and null are handled as for other fields.
+	b, err = Marshal(codecFields{})
+	if want := `{"tags":"","when":null,"other":null}`; err != nil || string(b) != want {
+		t.Errorf("Marshal = %s, %v, want %s", b, err, want)
+	}
+	got = codecFields{When: &when}
+	if err := Unmarshal([]byte(`{"when": null}`), &got); err != nil || got.When != nil {
+		t.Errorf("Unmarshal = %+v, %v, want a nil When", got, err)
+	}
+}
+
+func TestCodecErrors(t *testing.T) {
+	neg := int64(-1)
+	var me *MarshalerError
+	if _, err := Marshal(codecFields{When: &neg}); !errors.As(err, &me) || !strings.Contains(err.Error(), `codec epochstring`) {
+		t.Errorf("Marshal error: got %v, want a MarshalerError from the codec", err)
+	}
+
+	var v codecFields
+	err := Unmarshal([]byte(`{"tags": "a", "when": "soon"}`), &v)
+	var se *SemanticError
+	if !errors.As(err, &se) || se.Path != "/when" || se.Offset != 22 || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Unmarshal error: got %#v, want a SemanticError at /when wrapping strconv.ErrSyntax", err)
+	}
+
+	type (
+		Unknown struct {
+			A int `json:"a,codec:nope"`
+		}
+		WrongType struct {
+			A int `json:"a,codec:csvlist"`
+		}
+		Both struct {
+			A []string `json:"a,codec:csvlist,format:date"`
+		}
+	)
+	for _, v := range []any{Unknown{}, WrongType{}, Both{}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) error: got nil, want error", v)
+		}
+	}
+}
+
+func TestRegisterCodecPanics(t *testing.T) {
+	tests := []struct {
+		CaseName
+		register func()
+	}{
+		{Name("duplicate"), func() { RegisterCodec("csvlist", Marshal, func([]byte, *any) error { return nil }) }},
+		{Name("comma"), func() { RegisterCodec("a,b", Marshal, func([]byte, *any) error { return nil }) }},
+		{Name("pointer"), func() {
+			RegisterCodec("ptr", func(*int) ([]byte, error) { return nil, nil }, func([]byte, **int) error { return nil })
+		}},
+		{Name("nil"), func() { RegisterCodec[int]("nil", nil, nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterCodec did not panic", tt.Where)
+				}
+			}()
+			tt.register()
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package json
+
+import (
+	"reflect"
+	"time"
+)
+
+// ContextValues holds values that the built-in encodings of standard
+// library types consult in place of the environment of the program, so
+// that their output is the same wherever it is produced, such as in tests
+// run on machines in different time zones. It is set with
+// [MarshalOptions.ContextValues] and [UnmarshalOptions.ContextValues].
+type ContextValues struct {
+	// Location, if non-nil, is the time zone in which time.Time values are
+	// encoded, whether by their MarshalJSON method or with the layout of a
+	// format option, in place of the one each value holds. Times decoded
+	// with a layout that has no time zone are taken to be in Location
+	// rather than UTC. The unix formats do not depend on the time zone.
+	Location *time.Location
+
+	// Now, if non-nil, is called in place of time.Now for the current
+	// time, which the ttl format of time.Time counts from.
+	Now func() time.Time
+}
+
+// in returns t in the Location of c, if any.
+func (c *ContextValues) in(t time.Time) time.Time {
+	if c == nil || c.Location == nil {
+		return t
+	}
+	return t.In(c.Location)
+}
+
+// now returns the current time, as told by the clock of c, if any.
+func (c *ContextValues) now() time.Time {
+	if c == nil || c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// timeEncoder encodes a time.Time or *time.Time with its MarshalJSON
+// method, after moving the time to the Location of opts.ctx.
+func timeEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	if opts.ctx != nil && opts.ctx.Location != nil && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		v = reflect.ValueOf(opts.ctx.in(reflect.Indirect(v).Interface().(time.Time)))
+	}
+	marshalerEncoder(e, v, opts)
+}
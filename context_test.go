@@ -0,0 +1,90 @@
+package json
+
+import (
+	"testing"
+	"time"
+)
+
+type contextTimes struct {
+	Default time.Time  `json:"default"`
+	Ptr     *time.Time `json:"ptr"`
+	Layout  time.Time  `json:"layout,format:datetime"`
+	Unix    time.Time  `json:"unix,format:unix"`
+	TTL     time.Time  `json:"ttl,format:ttl"`
+}
+
+func TestContextValuesMarshal(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	at := time.Date(2024, 5, 1, 13, 30, 0, 500, time.UTC)
+	in := contextTimes{at, &at, at, at, at}
+
+	tests := []struct {
+		CaseName
+		ctx  *ContextValues
+		want string
+	}{{
+		CaseName: Name("location"),
+		ctx:      &ContextValues{Location: tokyo, Now: func() time.Time { return now }},
+		want:     `{"default":"2024-05-01T22:30:00.0000005+09:00","ptr":"2024-05-01T22:30:00.0000005+09:00","layout":"2024-05-01 22:30:00","unix":1714570200,"ttl":5400}`,
+	}, {
+		CaseName: Name("clock only"),
+		ctx:      &ContextValues{Now: func() time.Time { return now.Add(-time.Hour) }},
+		want:     `{"default":"2024-05-01T13:30:00.0000005Z","ptr":"2024-05-01T13:30:00.0000005Z","layout":"2024-05-01 13:30:00","unix":1714570200,"ttl":9000}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := MarshalOptions{ContextValues: tt.ctx}.Marshal(in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	// Without ContextValues, times keep their own time zone.
+	got, err := Marshal(struct{ T time.Time }{at.In(tokyo)})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if want := `{"T":"2024-05-01T22:30:00.0000005+09:00"}`; string(got) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestContextValuesUnmarshal(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	in := `{"layout":"2024-05-01 22:30:00","ttl":-60}`
+
+	var got contextTimes
+	opts := UnmarshalOptions{ContextValues: &ContextValues{Location: tokyo, Now: func() time.Time { return now }}}
+	if err := opts.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := time.Date(2024, 5, 1, 13, 30, 0, 0, time.UTC); !got.Layout.Equal(want) || got.Layout.Location() != tokyo {
+		t.Errorf("Unmarshal: Layout = %v, want %v in JST", got.Layout, want)
+	}
+	if want := now.Add(-time.Minute); !got.TTL.Equal(want) {
+		t.Errorf("Unmarshal: TTL = %v, want %v", got.TTL, want)
+	}
+
+	got = contextTimes{}
+	if err := Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := time.Date(2024, 5, 1, 22, 30, 0, 0, time.UTC); !got.Layout.Equal(want) {
+		t.Errorf("Unmarshal: Layout = %v, want %v", got.Layout, want)
+	}
+	if d := time.Until(got.TTL); d > -59*time.Second || d < -61*time.Second {
+		t.Errorf("Unmarshal: TTL is %v from now, want -1m", d)
+	}
+
+	for _, in := range []string{`{"ttl":"60"}`, `{"ttl":1.5}`, `{"ttl":9223372036854775807}`} {
+		if err := Unmarshal([]byte(in), &got); err == nil {
+			t.Errorf("Unmarshal(%s): got nil error, want error", in)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A CyclePolicy selects how a value that contains itself, through a
+// pointer, map, or slice, is encoded.
+type CyclePolicy int
+
+const (
+	// CycleError reports the cycle as an [UnsupportedValueError] giving the
+	// JSON Pointers (RFC 6901) of the value and of its repetition.
+	// This is the behavior of [Marshal].
+	CycleError CyclePolicy = iota
+
+	// CycleNull encodes the repetition of the value as null.
+	CycleNull
+
+	// CycleRef encodes the repetition of the value as a JSON Reference,
+	// {"$ref":"#/path/to/value"}, whose fragment is the JSON Pointer of
+	// the value it repeats.
+	CycleRef
+)
+
+// cycleDepth returns the number of nested pointers, maps, and slices to
+// encode before looking for cycles.
+func (opts encOpts) cycleDepth() uint {
+	switch {
+	case opts.detectCyclesAfter > 0:
+		return uint(opts.detectCyclesAfter)
+	case opts.cycles != CycleError:
+		return 0
+	}
+	return startDetectingCyclesAfter
+}
+
+// visit records that the pointer, map, or slice v, identified by key, is
+// being encoded, and reports whether it is not in a cycle: that is, not
+// already being encoded at an enclosing level. For a cycle, visit writes a
+// placeholder for v or fails, as selected by opts.
+func (e *encodeState) visit(key any, v reflect.Value, opts encOpts) bool {
+	start, ok := e.ptrSeen[key]
+	if !ok {
+		e.ptrSeen[key] = e.Len()
+		return true
+	}
+	switch opts.cycles {
+	case CycleNull:
+		e.WriteString("null")
+	case CycleRef:
+		e.WriteString(`{"$ref":`)
+		e.Write(appendString(e.AvailableBuffer(), "#"+openPath(e.Bytes()[:start]), opts.escapeHTML))
+		e.WriteByte('}')
+	default:
+		msg := "encountered a cycle via " + v.Type().String()
+		if !e.flushed {
+			msg += fmt.Sprintf(": %q repeats %q", openPath(e.Bytes()), openPath(e.Bytes()[:start]))
+		}
+		e.error(&UnsupportedValueError{v, msg})
+	}
+	return false
+}
+
+// openPath returns the JSON Pointer of the value that starts at the end of
+// the compact JSON text b, which is the start of a value being encoded.
+func openPath(b []byte) string {
+	type level struct {
+		object bool
+		key    string
+		index  int
+	}
+	var stack []level
+	isKey := false // whether a string is an object key
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			start := i
+			for i++; i < len(b) && b[i] != '"'; i++ {
+				if b[i] == '\\' {
+					i++
+				}
+			}
+			if isKey && i < len(b) {
+				stack[len(stack)-1].key, _ = unquote(b[start : i+1])
+				isKey = false
+			}
+		case '{':
+			stack = append(stack, level{object: true})
+			isKey = true
+		case '[':
+			stack = append(stack, level{})
+		case '}', ']':
+			stack = stack[:len(stack)-1]
+		case ',':
+			if l := &stack[len(stack)-1]; l.object {
+				isKey = true
+			} else {
+				l.index++
+			}
+		}
+	}
+	var path strings.Builder
+	for _, l := range stack {
+		path.WriteByte('/')
+		if l.object {
+			path.WriteString(escapePointerToken(l.key))
+		} else {
+			path.WriteString(strconv.Itoa(l.index))
+		}
+	}
+	return path.String()
+}
@@ -0,0 +1,103 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type cycleNode struct {
+	Name     string                `json:"name"`
+	Next     *cycleNode            `json:"next,omitempty"`
+	Children []*cycleNode          `json:"children,omitempty"`
+	Attrs    map[string]*cycleNode `json:"attrs,omitempty"`
+}
+
+func TestMarshalCycles(t *testing.T) {
+	a := &cycleNode{Name: "a"}
+	b := &cycleNode{Name: "b", Next: a}
+	a.Children = []*cycleNode{{Name: "c"}, b}
+	shared := &cycleNode{Name: "s"}
+	d := &cycleNode{Name: "d", Attrs: map[string]*cycleNode{"x/y": shared, "z": shared}}
+
+	tests := []struct {
+		CaseName
+		in    any
+		opts  MarshalOptions
+		want  string
+		error string
+	}{{
+		CaseName: Name("error"),
+		in:       a,
+		opts:     MarshalOptions{DetectCyclesAfter: 1},
+		error:    `json: unsupported value: encountered a cycle via []*json.cycleNode: "/children/1/next/children" repeats "/children"`,
+	}, {
+		CaseName: Name("error after depth"),
+		in:       a,
+		opts:     MarshalOptions{DetectCyclesAfter: 4},
+		error:    `json: unsupported value: encountered a cycle via []*json.cycleNode: "/children/1/next/children/1/next/children" repeats "/children/1/next/children"`,
+	}, {
+		CaseName: Name("null"),
+		in:       a,
+		opts:     MarshalOptions{Cycles: CycleNull},
+		want:     `{"name":"a","children":[{"name":"c"},{"name":"b","next":null}]}`,
+	}, {
+		CaseName: Name("ref"),
+		in:       map[string]any{"root": a},
+		opts:     MarshalOptions{Cycles: CycleRef},
+		want:     `{"root":{"name":"a","children":[{"name":"c"},{"name":"b","next":{"$ref":"#/root"}}]}}`,
+	}, {
+		CaseName: Name("shared values are not cycles"),
+		in:       d,
+		opts:     MarshalOptions{Cycles: CycleRef},
+		want:     `{"name":"d","attrs":{"x/y":{"name":"s"},"z":{"name":"s"}}}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := tt.opts.Marshal(tt.in)
+			if tt.error != "" {
+				var uve *UnsupportedValueError
+				if !errors.As(err, &uve) || err.Error() != tt.error {
+					t.Errorf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalCycleMap(t *testing.T) {
+	m := map[string]any{"a~b": []any{1}}
+	m["a~b"].([]any)[0] = m
+	got, err := MarshalOptions{Cycles: CycleRef}.Marshal([]any{m})
+	if want := `[{"a~b":[{"$ref":"#/0"}]}]`; err != nil || string(got) != want {
+		t.Errorf("Marshal = %s, %v, want %s", got, err, want)
+	}
+	// Without DetectCyclesAfter, the cycle is found deep within itself.
+	_, err = Marshal(m)
+	if err == nil || !strings.Contains(err.Error(), `/a~0b/0" repeats "/a~0b/0`) {
+		t.Errorf("Marshal error: got %v, want the path of the cycle", err)
+	}
+}
+
+func TestOpenPath(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{``, ``},
+		{`{"a":`, `/a`},
+		{`[1,"x,\"]",[2],`, `/3`},
+		{`{"a":{"b":1},"c\"/~":[{},{"d":`, `/c"~1~0/1/d`},
+	}
+	for _, tt := range tests {
+		if got := openPath([]byte(tt.in)); got != tt.want {
+			t.Errorf("openPath(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
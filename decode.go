@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"maps"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -58,6 +59,15 @@ import (
 //   - map[string]interface{}, for JSON objects
 //   - nil for JSON null
 //
+// [UnmarshalOptions] can select other types for numbers, arrays, and objects.
+//
+// To unmarshal a JSON object into a struct with an inline field, as
+// described for [Marshal], Unmarshal decodes the whole object into a new
+// value of the type named by its "$type" member, stores it in the field,
+// and decodes the other fields of the struct from the same object; if the
+// object has no "$type" member, the field is left unchanged. Only members
+// that match neither the struct nor the type of the value are unknown.
+//
 // To unmarshal a JSON array into a slice, Unmarshal resets the slice length
 // to zero and then appends each element to the slice.
 // As a special case, to unmarshal an empty JSON array into a slice,
@@ -86,28 +96,38 @@ import (
 // an [UnmarshalTypeError] describing the earliest such error. In any
 // case, it's not guaranteed that all the remaining fields following
 // the problematic one will be unmarshaled into the target object.
+// Other problems with the contents of the input, such as a value not
+// allowed by an enum tag option, are handled the same way but reported as
+// a [SemanticError], which locates the value and wraps a cause such as
+// [ErrInvalidEnum].
 //
 // The JSON null value unmarshals into an interface, map, pointer, or slice
 // by setting that Go value to nil. Because null is often used in JSON to mean
 // “not present,” unmarshaling a JSON null into any other Go type has no effect
 // on the value and produces no error.
 //
+// A struct field with the "emptyasnull" tag option unmarshals an empty JSON
+// string as if it were null, for input that sends "" where null is meant:
+//
+//	Expires *time.Time `json:"expires,nullable,emptyasnull"`
+//
+// See [UnmarshalOptions.EmptyAsNull] to do so for all pointers.
+//
 // When unmarshaling quoted strings, invalid UTF-8 or
 // invalid UTF-16 surrogate pairs are not treated as an error.
 // Instead, they are replaced by the Unicode replacement
 // character U+FFFD.
 func Unmarshal(data []byte, v any) error {
-	// Check for well-formedness.
-	// Avoids filling out half a data structure
-	// before discovering a JSON syntax error.
-	var d decodeState
-	err := checkValid(data, &d.scan)
-	if err != nil {
-		return err
-	}
+	return UnmarshalOptions{}.Unmarshal(data, v)
+}
 
-	d.init(data)
-	return d.unmarshal(v)
+// UnmarshalValue is like [Unmarshal] but stores the result in the value
+// pointed to by v, or in v itself if it is settable, such as a field of a
+// struct reached through a pointer, without converting it to an interface
+// value first. UnmarshalValue returns an [InvalidUnmarshalError] if v is
+// neither settable nor a non-nil pointer.
+func UnmarshalValue(data []byte, v reflect.Value) error {
+	return UnmarshalOptions{}.UnmarshalValue(data, v)
 }
 
 // Unmarshaler is the interface implemented by types
@@ -171,12 +191,23 @@ func (e *InvalidUnmarshalError) Error() string {
 }
 
 func (d *decodeState) unmarshal(v any) error {
-	rv := reflect.ValueOf(v)
+	return d.unmarshalValue(reflect.ValueOf(v))
+}
+
+func (d *decodeState) unmarshalValue(rv reflect.Value) error {
+	if rv.CanSet() {
+		rv = rv.Addr()
+	}
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
-		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+		var t reflect.Type
+		if rv.IsValid() {
+			t = rv.Type()
+		}
+		return &InvalidUnmarshalError{t}
 	}
 
 	d.scan.reset()
+	d.mask = d.fieldMask
 	d.scanWhile(scanSkipSpace)
 	// We decode rv not rv.Elem because the Unmarshaler interface
 	// test must be applied at the top level of the value.
@@ -218,7 +249,22 @@ type decodeState struct {
 	errorContext          *errorContext
 	savedError            error
 	useNumber             bool
+	useInt64              bool
+	useOrderedObjects     bool
+	useRawArrays          bool
 	disallowUnknownFields bool
+	fieldMask             maskTree // from UnmarshalOptions.Mask
+	mask                  maskTree // applies to the value being decoded
+	duplicateKeys         DuplicateKeyPolicy
+	duplicateKeyFilter    *DuplicateKeyFilter
+	typedInterfaces       bool
+	warnings              *[]Warning // from UnmarshalOptions.Warnings
+	caseSensitive         bool
+	disallowNull          bool
+	missingFields         *[]MissingField // from UnmarshalOptions.MissingFields
+	overflow              OverflowPolicy
+	emptyAsNull           bool
+	ctx                   *ContextValues // from UnmarshalOptions.ContextValues
 }
 
 // readIndex returns the position of the last byte read.
@@ -226,6 +272,15 @@ func (d *decodeState) readIndex() int {
 	return d.off - 1
 }
 
+// literalOffset returns the offset of the literal item just read, or of the
+// end of the string holding it if it is fromQuoted, as for a ,string field.
+func (d *decodeState) literalOffset(item []byte, fromQuoted bool) int {
+	if fromQuoted {
+		return d.readIndex() - 1
+	}
+	return d.readIndex() - len(item)
+}
+
 // phasePanicMsg is used as a panic message when we end up with something that
 // shouldn't happen. It can indicate a bug in the JSON decoder, or that
 // something is editing the data slice while the decoder executes.
@@ -247,6 +302,9 @@ func (d *decodeState) init(data []byte) *decodeState {
 // for reporting at the end of the unmarshal.
 func (d *decodeState) saveError(err error) {
 	if d.savedError == nil {
+		if serr, ok := err.(*SemanticError); ok {
+			serr.Path = pointerAt(d.data, int(serr.Offset))
+		}
 		d.savedError = d.addErrorContext(err)
 	}
 }
@@ -357,6 +415,14 @@ Switch:
 // reads the following byte ahead. If v is invalid, the value is discarded.
 // The first byte of the value has been read already.
 func (d *decodeState) value(v reflect.Value) error {
+	if v.IsValid() && d.emptyAsNull && d.emptyString() && emptyAsNullType(v.Type()) {
+		return d.storeEmptyAsNull(v)
+	}
+	if v.IsValid() {
+		if w, ok := d.indirectWrapper(v); ok {
+			return d.wrapped(w)
+		}
+	}
 	switch d.opcode {
 	default:
 		panic(phasePanicMsg)
@@ -503,6 +569,11 @@ func indirect(v reflect.Value, decodingNull bool) (Unmarshaler, encoding.TextUnm
 func (d *decodeState) array(v reflect.Value) error {
 	// Check for unmarshaler.
 	u, ut, pv := indirect(v, false)
+	if rs, ok := u.(rawScalar); ok {
+		d.saveError(rs.typeError("array", d.off))
+		d.skip()
+		return nil
+	}
 	if u != nil {
 		start := d.readIndex()
 		d.skip()
@@ -598,8 +669,17 @@ var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
 // object consumes an object from d.data[d.off-1:], decoding into v.
 // The first byte ('{') of the object has been read already.
 func (d *decodeState) object(v reflect.Value) error {
+	if d.typedInterfaces && d.typedInterface(v) {
+		return nil
+	}
+
 	// Check for unmarshaler.
 	u, ut, pv := indirect(v, false)
+	if rs, ok := u.(rawScalar); ok {
+		d.saveError(rs.typeError("object", d.off))
+		d.skip()
+		return nil
+	}
 	if u != nil {
 		start := d.readIndex()
 		d.skip()
@@ -622,6 +702,7 @@ func (d *decodeState) object(v reflect.Value) error {
 
 	var fields structFields
 	var nonoptionalNullableFields map[*field]struct{}
+	var unpopulatedFields map[*field]struct{} // for d.missingFields
 
 	// Check type of target:
 	//   struct or
@@ -652,6 +733,19 @@ func (d *decodeState) object(v reflect.Value) error {
 			return nil
 		}
 		nonoptionalNullableFields = maps.Clone(fields.nonoptionalNullables)
+		for f := range nonoptionalNullableFields {
+			if _, ok := d.mask.selects(f.name); !ok {
+				delete(nonoptionalNullableFields, f)
+			}
+		}
+		if d.missingFields != nil {
+			unpopulatedFields = make(map[*field]struct{}, len(fields.list))
+			for i := range fields.list {
+				if _, ok := d.mask.selects(fields.list[i].name); ok {
+					unpopulatedFields[&fields.list[i]] = struct{}{}
+				}
+			}
+		}
 		// ok
 	default:
 		d.saveError(&UnmarshalTypeError{Value: "object", Type: t, Offset: int64(d.off)})
@@ -659,8 +753,17 @@ func (d *decodeState) object(v reflect.Value) error {
 		return nil
 	}
 
+	objStart := d.readIndex()
 	var mapElem reflect.Value
+	var inlineField *field // the inline field of a struct, if any
+	var inlineType reflect.Type
+	var inlineUnknown []inlineKey
+	if f := fields.byExactName[typeMember]; f != nil && f.inline {
+		inlineField = f
+	}
 	var origErrorContext errorContext
+	var seen keySet // members decoded so far, unless duplicates are last-wins
+	mask := d.mask
 	if d.errorContext != nil {
 		origErrorContext = *d.errorContext
 	}
@@ -689,25 +792,53 @@ func (d *decodeState) object(v reflect.Value) error {
 		var subv reflect.Value
 		destring := false // whether the value is wrapped in a string to be decoded first
 		optional := false
+		nullable := false
+		emptyAsNull := false
+		var enum []string // allowed values of the field, if constrained
+		var format *fieldFormat
+		inline := false
+		selected := true // whether the field mask selects this member
 
 		if v.Kind() == reflect.Map {
-			elemType := t.Elem()
-			if !mapElem.IsValid() {
-				mapElem = reflect.New(elemType).Elem()
-			} else {
-				mapElem.SetZero()
+			d.mask, selected = mask.selects(string(key))
+			if selected && d.isDuplicate(&seen, string(key), string(key), start) {
+				selected = false
+			}
+			if selected {
+				elemType := t.Elem()
+				if !mapElem.IsValid() {
+					mapElem = reflect.New(elemType).Elem()
+				} else {
+					mapElem.SetZero()
+				}
+				subv = mapElem
 			}
-			subv = mapElem
 		} else {
 			f := fields.byExactName[string(key)]
-			if f == nil {
+			folded := false
+			if f == nil && !d.caseSensitive {
 				f = fields.byFoldedName[string(foldName(key))]
+				folded = f != nil
+			}
+			duplicate := false
+			if f != nil {
+				if d.mask, selected = mask.selects(f.name); !selected {
+					f = nil
+				} else if duplicate = d.isDuplicate(&seen, f.name, string(key), start); duplicate {
+					f = nil
+				}
 			}
 			delete(nonoptionalNullableFields, f)
+			delete(unpopulatedFields, f)
 			if f != nil {
 				subv = v
 				destring = f.quoted
 				optional = f.optional
+				nullable = f.nullable
+				emptyAsNull = f.emptyAsNull
+				inline = f.inline
+				enum = f.enum
+				format = f.fieldFormat
 				for _, i := range f.index {
 					if subv.Kind() == reflect.Pointer {
 						if subv.IsNil() {
@@ -735,8 +866,18 @@ func (d *decodeState) object(v reflect.Value) error {
 				}
 				d.errorContext.FieldStack = append(d.errorContext.FieldStack, f.name)
 				d.errorContext.Struct = t
-			} else if d.disallowUnknownFields {
-				d.saveError(fmt.Errorf("json: unknown field %q", key))
+				if folded {
+					d.warn(start, ErrCaseInsensitiveMatch)
+				}
+			} else if selected && !duplicate {
+				if inlineField != nil {
+					// Reported once the type of the inline value is known.
+					inlineUnknown = append(inlineUnknown, inlineKey{string(key), start})
+				} else if d.disallowUnknownFields {
+					d.saveError(newSemanticError(start, t, ErrUnknownField, "json: unknown field %q", key))
+				} else {
+					d.warn(start, ErrUnknownField)
+				}
 			}
 		}
 		if optional {
@@ -754,32 +895,62 @@ func (d *decodeState) object(v reflect.Value) error {
 			panic(phasePanicMsg)
 		}
 		d.scanWhile(scanSkipSpace)
+		valueStart := d.readIndex()
+		if enum != nil && d.opcode == scanBeginLiteral && d.data[d.readIndex()] == 'n' {
+			enum = nil // null is not constrained by the enum
+		}
 
-		if destring {
+		isNull := d.opcode == scanBeginLiteral && d.data[d.readIndex()] == 'n'
+		if inline && subv.IsValid() && !isNull {
+			inlineType = d.decodeInline(subv, objStart, mask)
+		} else if subv.IsValid() && d.emptyString() && (emptyAsNull || d.emptyAsNull && emptyAsNullType(subv.Type())) {
+			if err := d.storeEmptyAsNull(subv); err != nil {
+				return err
+			}
+		} else if format != nil && subv.IsValid() && !isNull {
+			d.decodeFormat(format, d.rawValue(), subv)
+		} else if destring {
 			switch qv := d.valueQuoted().(type) {
 			case nil:
 				if err := d.literalStore(nullLiteral, subv, false); err != nil {
 					return err
 				}
 			case string:
+				if nullable && qv == "null" {
+					d.saveError(newSemanticError(valueStart, subv.Type(), ErrStringOption, "json: invalid use of ,string struct tag, trying to unmarshal %q into nullable %v; use a bare null instead", `"null"`, subv.Type()))
+					break
+				}
 				if err := d.literalStore([]byte(qv), subv, true); err != nil {
 					return err
 				}
 			default:
-				d.saveError(fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal unquoted value into %v", subv.Type()))
+				d.saveError(newSemanticError(valueStart, subv.Type(), ErrStringOption, "json: invalid use of ,string struct tag, trying to unmarshal unquoted value into %v", subv.Type()))
 			}
 		} else {
 			if err := d.value(subv); err != nil {
 				return err
 			}
 		}
+		if enum != nil && subv.IsValid() {
+			if s, ok := enumValue(subv); ok && !slices.Contains(enum, s) {
+				d.saveError(newSemanticError(valueStart, subv.Type(), ErrInvalidEnum, "json: invalid value %q for Go struct field %s.%s, must be one of %s",
+					s, d.errorContext.Struct.Name(), strings.Join(d.errorContext.FieldStack, "."), strings.Join(enum, "|")))
+				subv.SetZero()
+			}
+		}
 
 		// Write value back to map;
 		// if using struct, subv points into struct already.
-		if v.Kind() == reflect.Map {
+		if v.Kind() == reflect.Map && selected {
 			kt := t.Key()
 			var kv reflect.Value
-			if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+			if ec := lookupEnum(kt); ec != nil {
+				var ok bool
+				if kv, ok = ec.parse(string(key)); !ok {
+					d.saveError(ec.invalid(start+1, item))
+					kv = reflect.Value{}
+				}
+			} else if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
 				kv = reflect.New(kt)
 				if err := d.literalStore(item, kv, true); err != nil {
 					return err
@@ -836,20 +1007,56 @@ func (d *decodeState) object(v reflect.Value) error {
 		}
 	}
 
+	d.mask = mask
+	if inlineField != nil {
+		d.reportUnknownInline(inlineUnknown, t, inlineType)
+	}
+
 	if len(nonoptionalNullableFields) > 0 {
 		fieldNames := make([]string, 0, len(nonoptionalNullableFields))
 		for f := range nonoptionalNullableFields {
 			fieldNames = append(fieldNames, f.name)
 		}
 		sort.Strings(fieldNames)
-		d.saveError(fmt.Errorf("json: non-optional, nullable fields [%s] not found in object", strings.Join(fieldNames, ", ")))
+		d.saveError(newSemanticError(objStart, t, ErrMissingField, "json: non-optional, nullable fields [%s] not found in object", strings.Join(fieldNames, ", ")))
+	}
+	if len(unpopulatedFields) > 0 {
+		for i := range fields.list {
+			if _, ok := unpopulatedFields[&fields.list[i]]; ok {
+				*d.missingFields = append(*d.missingFields, MissingField{Offset: int64(objStart), Type: t, Name: fields.list[i].name})
+			}
+		}
 	}
 	return nil
 }
 
-// convertNumber converts the number literal s to a float64 or a Number
-// depending on the setting of d.useNumber.
+// isDuplicate reports whether the object member named name, with the given
+// key, should be skipped because the object already had a member
+// with that name, as decided by the duplicate key policy. If the policy
+// is to reject duplicates, the error is saved. seen records the names of
+// the members decoded so far; if it is nil, the caller has already found
+// the name to be a duplicate. off is the offset of the key in the input.
+func (d *decodeState) isDuplicate(seen *keySet, name, key string, off int) bool {
+	if d.duplicateKeys == DuplicateKeysLastWins {
+		return false
+	}
+	if seen != nil && !seen.add(name, d.duplicateKeyFilter) {
+		return false
+	}
+	if d.duplicateKeys == DuplicateKeysError {
+		d.saveError(newSemanticError(off, nil, ErrDuplicateKey, "json: duplicate key %q in object", key))
+	}
+	return true
+}
+
+// convertNumber converts the number literal s to an int64, a float64,
+// or a Number depending on the settings of d.useInt64 and d.useNumber.
 func (d *decodeState) convertNumber(s string) (any, error) {
+	if d.useInt64 && !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, nil
+		}
+	}
 	if d.useNumber {
 		return Number(s), nil
 	}
@@ -875,7 +1082,21 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		return nil
 	}
 	isNull := item[0] == 'n' // null
+	if !isNull && enumsRegistered.Load() {
+		t := v.Type()
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if ec := lookupEnum(t); ec != nil {
+			d.enumStore(ec, item, v, fromQuoted)
+			return nil
+		}
+	}
 	u, ut, pv := indirect(v, isNull)
+	if rs, ok := u.(rawScalar); ok && !isNull && !rs.accepts(item) {
+		d.saveError(rs.typeError(valueKind(item), d.literalOffset(item, fromQuoted)))
+		return nil
+	}
 	if u != nil {
 		return u.UnmarshalJSON(item)
 	}
@@ -918,7 +1139,12 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		switch v.Kind() {
 		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
 			v.SetZero()
-			// otherwise, ignore null for primitives/string
+		default:
+			// Otherwise, ignore null for primitives/string,
+			// unless it is disallowed.
+			if d.disallowNull && !fromQuoted {
+				d.saveError(&UnmarshalTypeError{Value: "null", Type: v.Type(), Offset: int64(d.readIndex())})
+			}
 		}
 	case 't', 'f': // true, false
 		value := item[0] == 't'
@@ -1015,6 +1241,9 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			n, err := strconv.ParseInt(string(item), 10, 64)
 			if err != nil || v.OverflowInt(n) {
+				if d.storeOverflow(item, v, d.literalOffset(item, fromQuoted)) {
+					break
+				}
 				d.saveError(&UnmarshalTypeError{Value: "number " + string(item), Type: v.Type(), Offset: int64(d.readIndex())})
 				break
 			}
@@ -1023,6 +1252,9 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 			n, err := strconv.ParseUint(string(item), 10, 64)
 			if err != nil || v.OverflowUint(n) {
+				if d.storeOverflow(item, v, d.literalOffset(item, fromQuoted)) {
+					break
+				}
 				d.saveError(&UnmarshalTypeError{Value: "number " + string(item), Type: v.Type(), Offset: int64(d.readIndex())})
 				break
 			}
@@ -1031,6 +1263,9 @@ func (d *decodeState) literalStore(item []byte, v reflect.Value, fromQuoted bool
 		case reflect.Float32, reflect.Float64:
 			n, err := strconv.ParseFloat(string(item), v.Type().Bits())
 			if err != nil || v.OverflowFloat(n) {
+				if d.storeOverflow(item, v, d.literalOffset(item, fromQuoted)) {
+					break
+				}
 				d.saveError(&UnmarshalTypeError{Value: "number " + string(item), Type: v.Type(), Offset: int64(d.readIndex())})
 				break
 			}
@@ -1053,6 +1288,13 @@ func (d *decodeState) valueInterface() (val any) {
 		val = d.arrayInterface()
 		d.scanNext()
 	case scanBeginObject:
+		if d.typedInterfaces {
+			if x, ok := d.typedInterfaceValue(); ok {
+				val = x
+				d.scanNext()
+				break
+			}
+		}
 		val = d.objectInterface()
 		d.scanNext()
 	case scanBeginLiteral:
@@ -1061,8 +1303,12 @@ func (d *decodeState) valueInterface() (val any) {
 	return
 }
 
-// arrayInterface is like array but returns []interface{}.
-func (d *decodeState) arrayInterface() []any {
+// arrayInterface is like array but returns []interface{},
+// or []RawMessage if d.useRawArrays is set.
+func (d *decodeState) arrayInterface() any {
+	if d.useRawArrays {
+		return d.arrayRaw()
+	}
 	var v = make([]any, 0)
 	for {
 		// Look ahead for ] - can only happen on first iteration.
@@ -1087,9 +1333,45 @@ func (d *decodeState) arrayInterface() []any {
 	return v
 }
 
-// objectInterface is like object but returns map[string]interface{}.
-func (d *decodeState) objectInterface() map[string]any {
-	m := make(map[string]any)
+// arrayRaw is like arrayInterface but returns the encodings of the elements.
+func (d *decodeState) arrayRaw() []RawMessage {
+	var v = make([]RawMessage, 0)
+	for {
+		// Look ahead for ] - can only happen on first iteration.
+		d.scanWhile(scanSkipSpace)
+		if d.opcode == scanEndArray {
+			break
+		}
+
+		v = append(v, slices.Clone(d.rawValue()))
+
+		// Next token must be , or ].
+		if d.opcode == scanSkipSpace {
+			d.scanWhile(scanSkipSpace)
+		}
+		if d.opcode == scanEndArray {
+			break
+		}
+		if d.opcode != scanArrayValue {
+			panic(phasePanicMsg)
+		}
+	}
+	return v
+}
+
+// objectInterface is like object but returns map[string]interface{},
+// or an OrderedObject if d.useOrderedObjects is set.
+func (d *decodeState) objectInterface() any {
+	var m map[string]any
+	var obj OrderedObject
+	var index map[string]int // of the members of obj
+	if d.useOrderedObjects {
+		obj = OrderedObject{}
+		index = make(map[string]int)
+	} else {
+		m = make(map[string]any)
+	}
+	mask := d.mask
 	for {
 		// Read opening " of string key or closing }.
 		d.scanWhile(scanSkipSpace)
@@ -1120,7 +1402,30 @@ func (d *decodeState) objectInterface() map[string]any {
 		d.scanWhile(scanSkipSpace)
 
 		// Read value.
-		m[key] = d.valueInterface()
+		sub, ok := mask.selects(key)
+		_, dup := m[key]
+		if d.useOrderedObjects {
+			_, dup = index[key]
+		}
+		if ok && dup {
+			ok = !d.isDuplicate(nil, key, key, start)
+		}
+		switch {
+		case ok && d.useOrderedObjects:
+			d.mask = sub
+			val := d.valueInterface()
+			if i, dup := index[key]; dup {
+				obj[i].Value = val
+			} else {
+				index[key] = len(obj)
+				obj = append(obj, ObjectMember{Key: key, Value: val})
+			}
+		case ok:
+			d.mask = sub
+			m[key] = d.valueInterface()
+		default:
+			d.value(reflect.Value{}) // skips the value; cannot fail
+		}
 
 		// Next token must be , or }.
 		if d.opcode == scanSkipSpace {
@@ -1133,6 +1438,10 @@ func (d *decodeState) objectInterface() map[string]any {
 			panic(phasePanicMsg)
 		}
 	}
+	d.mask = mask
+	if d.useOrderedObjects {
+		return obj
+	}
 	return m
 }
 
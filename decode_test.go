@@ -441,6 +441,22 @@ type OptionalsNullablesBadOmitempty3 struct {
 	X *int `json:"x,optional,nullable,omitempty"`
 }
 
+type EnumStatus string
+
+type Enums struct {
+	S     EnumStatus  `json:"s,enum=active|paused"`
+	SO    *EnumStatus `json:"so,optional,enum=active|paused"`
+	Inner EnumsInner  `json:"inner"`
+}
+
+type EnumsInner struct {
+	P *string `json:"p,enum=a|b"`
+}
+
+type EnumsBadType struct {
+	X int `json:"x,enum=1|2"`
+}
+
 var unmarshalTests = []struct {
 	CaseName
 	in                    string
@@ -541,6 +557,13 @@ var unmarshalTests = []struct {
 	{CaseName: Name(""), in: `{}`, ptr: new(OptionalsNullablesBadOmitempty2), err: errors.New(`json: field "x" cannot have both omitempty and optional tags`)},
 	{CaseName: Name(""), in: `{}`, ptr: new(OptionalsNullablesBadOmitempty3), err: errors.New(`json: field "x" cannot have both omitempty and optional tags`)},
 
+	// enums
+	{CaseName: Name(""), in: `{"s":"active","so":"paused","inner":{"p":"b"}}`, ptr: new(Enums), out: Enums{S: "active", SO: toPtr(EnumStatus("paused")), Inner: EnumsInner{P: toPtr("b")}}},
+	{CaseName: Name(""), in: `{"s":"active","inner":{"p":null}}`, ptr: new(Enums), out: Enums{S: "active"}},
+	{CaseName: Name(""), in: `{"s":"deleted"}`, ptr: new(Enums), err: errors.New(`json: invalid value "deleted" for Go struct field Enums.s, must be one of active|paused`)},
+	{CaseName: Name(""), in: `{"inner":{"p":"c"}}`, ptr: new(Enums), err: errors.New(`json: invalid value "c" for Go struct field EnumsInner.inner.p, must be one of a|b`)},
+	{CaseName: Name(""), in: `{}`, ptr: new(EnumsBadType), err: errors.New(`json: enum field "x" requires a string type, type = "int"`)},
+
 	// Z has a "-" tag.
 	{CaseName: Name(""), in: `{"Y": 1, "Z": 2}`, ptr: new(T), out: T{Y: 1}},
 	{CaseName: Name(""), in: `{"Y": 1, "Z": 2}`, ptr: new(T), err: fmt.Errorf("json: unknown field \"Z\""), disallowUnknownFields: true},
@@ -2342,27 +2365,108 @@ func TestInvalidUnmarshalText(t *testing.T) {
 	}
 }
 
-// Test that string option is ignored for invalid types.
-// Issue 9812.
+// Test that string option is reported as an error for invalid types,
+// rather than silently ignored (issue 9812).
 func TestInvalidStringOption(t *testing.T) {
 	num := 0
-	item := struct {
-		T time.Time         `json:",string"`
-		M map[string]string `json:",string"`
-		S []string          `json:",string"`
-		A [1]string         `json:",string"`
-		I any               `json:",string"`
-		P *int              `json:",string"`
-	}{M: make(map[string]string), S: make([]string, 0), I: num, P: &num}
-
-	data, err := Marshal(item)
-	if err != nil {
-		t.Fatalf("Marshal error: %v", err)
+	tests := []struct {
+		CaseName
+		in      any
+		wantErr string
+	}{
+		{Name("time"), &struct {
+			T time.Time `json:",string"`
+		}{}, `json: string option is ignored by field "T" of type "time.Time"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("map"), &struct {
+			M map[string]string `json:",string"`
+		}{M: make(map[string]string)}, `json: string option is ignored by field "M" of type "map[string]string"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("slice"), &struct {
+			S []string `json:",string"`
+		}{S: make([]string, 0)}, `json: string option is ignored by field "S" of type "[]string"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("array"), &struct {
+			A [1]string `json:",string"`
+		}{}, `json: string option is ignored by field "A" of type "[1]string"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("interface"), &struct {
+			I any `json:",string"`
+		}{I: num}, `json: string option is ignored by field "I" of type "interface {}"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("double pointer"), &struct {
+			P **int `json:",string"`
+		}{}, `json: string option is ignored by field "P" of type "**int"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("text marshaler"), &struct {
+			X textMarshalerString `json:",string"`
+		}{}, `json: string option is ignored by field "X" of type "json.textMarshalerString"; it only applies to strings, floating point, integer, and boolean fields`},
+		{Name("pointer"), &struct {
+			P *int `json:",string"`
+		}{P: &num}, ""},
 	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			data, err := Marshal(tt.in)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+			if data == nil {
+				data = []byte("{}")
+			}
+			err = Unmarshal(data, tt.in)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("%s: Unmarshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+		})
+	}
+}
 
-	err = Unmarshal(data, &item)
-	if err != nil {
-		t.Fatalf("Unmarshal error: %v", err)
+type textMarshalerString string
+
+func (s textMarshalerString) MarshalText() ([]byte, error) { return []byte(s), nil }
+
+func TestStringOptionNullable(t *testing.T) {
+	type T struct {
+		B   *bool  `json:"b,string,nullable"`
+		I   *int   `json:"i,string,optional"`
+		ON  **int  `json:"on,string,optional,nullable"`
+		ONB **bool `json:"onb,string,optional,nullable"`
+	}
+	var nilInt *int
+	tru := true
+	two := 2
+	twoPtr := &two
+	tests := []struct {
+		CaseName
+		in   T
+		want string
+	}{
+		{Name("unset"), T{}, `{"b":null}`},
+		{Name("null"), T{ON: &nilInt}, `{"b":null,"on":null}`},
+		{Name("values"), T{B: &tru, I: &two, ON: &twoPtr}, `{"b":"true","i":"2","on":"2"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+			var out T
+			if err := Unmarshal(got, &out); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if !reflect.DeepEqual(out, tt.in) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %#v\n\twant: %#v", tt.Where, out, tt.in)
+			}
+		})
+	}
+
+	var out T
+	wantErr := `json: invalid use of ,string struct tag, trying to unmarshal "\"null\"" into nullable *bool; use a bare null instead`
+	if err := Unmarshal([]byte(`{"b":"null"}`), &out); err == nil || err.Error() != wantErr {
+		t.Errorf("Unmarshal error:\n\tgot:  %v\n\twant: %s", err, wantErr)
+	}
+	var onb T
+	if err := Unmarshal([]byte(`{"b":null,"onb":"false"}`), &onb); err != nil || onb.ONB == nil || *onb.ONB == nil || **onb.ONB {
+		t.Errorf("Unmarshal(onb) = %#v, %v, want **false", onb.ONB, err)
 	}
 }
 
@@ -2723,3 +2827,160 @@ func TestUnmarshalMaxDepth(t *testing.T) {
 }
 
 func toPtr[T any](t T) *T { return &t }
+
+func TestDuplicateKeyPolicy(t *testing.T) {
+	type S struct {
+		Name string `json:"name"`
+		N    int
+	}
+	const in = `{"name": "a", "N": 1, "Name": "b", "n": 2}`
+	var wantAny any = map[string]any{"k": "1"}
+	tests := []struct {
+		CaseName
+		policy  DuplicateKeyPolicy
+		ptr     any
+		want    any
+		wantErr string
+	}{
+		{Name("struct last wins"), DuplicateKeysLastWins, new(S), &S{Name: "b", N: 2}, ""},
+		{Name("struct first wins"), DuplicateKeysFirstWins, new(S), &S{Name: "a", N: 1}, ""},
+		{Name("struct error"), DuplicateKeysError, new(S), &S{Name: "a", N: 1}, `json: duplicate key "Name" in object`},
+		{Name("map first wins"), DuplicateKeysFirstWins, new(map[string]string), &map[string]string{"k": "1"}, ""},
+		{Name("interface first wins"), DuplicateKeysFirstWins, new(any), &wantAny, ""},
+		{Name("interface error"), DuplicateKeysError, new(any), &wantAny, `json: duplicate key "k" in object`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			in := in
+			if _, ok := tt.ptr.(*S); !ok {
+				in = `{"k": "1", "k": "2"}`
+			}
+			err := UnmarshalOptions{DuplicateKeys: tt.policy}.Unmarshal([]byte(in), tt.ptr)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("%s: Unmarshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.ptr, tt.want) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %#v\n\twant: %#v", tt.Where, tt.ptr, tt.want)
+			}
+		})
+	}
+
+	dec := NewDecoder(strings.NewReader(`{"k": 1, "k": 2}`))
+	dec.SetDuplicateKeyPolicy(DuplicateKeysError)
+	var m map[string]int
+	if err := dec.Decode(&m); err == nil {
+		t.Error("Decode error = nil, want duplicate key error")
+	}
+}
+
+func TestUnmarshalInterfaceTypes(t *testing.T) {
+	const in = `{"b": [1, -2, 3.5, 1e2, 9223372036854775808], "a": {"y": [], "x": null}, "b2": [{"c": 1}, "s", true]}`
+	tests := []struct {
+		CaseName
+		opts UnmarshalOptions
+		want any
+	}{{
+		CaseName: Name("UseInt64"),
+		opts:     UnmarshalOptions{UseInt64: true},
+		want: map[string]any{
+			"b":  []any{int64(1), int64(-2), 3.5, 100.0, 9223372036854775808.0},
+			"a":  map[string]any{"y": []any{}, "x": nil},
+			"b2": []any{map[string]any{"c": int64(1)}, "s", true},
+		},
+	}, {
+		CaseName: Name("UseInt64AndNumber"),
+		opts:     UnmarshalOptions{UseInt64: true, UseNumber: true},
+		want: map[string]any{
+			"b":  []any{int64(1), int64(-2), Number("3.5"), Number("1e2"), Number("9223372036854775808")},
+			"a":  map[string]any{"y": []any{}, "x": nil},
+			"b2": []any{map[string]any{"c": int64(1)}, "s", true},
+		},
+	}, {
+		CaseName: Name("UseOrderedObjects"),
+		opts:     UnmarshalOptions{UseOrderedObjects: true},
+		want: OrderedObject{
+			{"b", []any{1.0, -2.0, 3.5, 100.0, 9223372036854775808.0}},
+			{"a", OrderedObject{{"y", []any{}}, {"x", nil}}},
+			{"b2", []any{OrderedObject{{"c", 1.0}}, "s", true}},
+		},
+	}, {
+		CaseName: Name("UseRawArrays"),
+		opts:     UnmarshalOptions{UseRawArrays: true},
+		want: map[string]any{
+			"b":  []RawMessage{RawMessage("1"), RawMessage("-2"), RawMessage("3.5"), RawMessage("1e2"), RawMessage("9223372036854775808")},
+			"a":  map[string]any{"y": []RawMessage{}, "x": nil},
+			"b2": []RawMessage{RawMessage(`{"c": 1}`), RawMessage(`"s"`), RawMessage("true")},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var got any
+			if err := tt.opts.Unmarshal([]byte(in), &got); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %#v\n\twant: %#v", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalOrderedObjectDuplicates(t *testing.T) {
+	const in = `{"a": 1, "b": 2, "a": 3}`
+	tests := []struct {
+		policy DuplicateKeyPolicy
+		want   OrderedObject
+	}{
+		{DuplicateKeysLastWins, OrderedObject{{"a", 3.0}, {"b", 2.0}}},
+		{DuplicateKeysFirstWins, OrderedObject{{"a", 1.0}, {"b", 2.0}}},
+	}
+	for _, tt := range tests {
+		var got any
+		opts := UnmarshalOptions{UseOrderedObjects: true, DuplicateKeys: tt.policy}
+		if err := opts.Unmarshal([]byte(in), &got); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("policy %d: Unmarshal:\n\tgot:  %v\n\twant: %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalValue(t *testing.T) {
+	type T struct {
+		A int
+		P *[]string
+	}
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if err := UnmarshalValue([]byte(`5`), rv.Field(0)); err != nil {
+		t.Fatalf("UnmarshalValue error: %v", err)
+	}
+	if err := UnmarshalValue([]byte(`["x"]`), rv.Field(1)); err != nil {
+		t.Fatalf("UnmarshalValue error: %v", err)
+	}
+	if err := (UnmarshalOptions{DisallowUnknownFields: true}).UnmarshalValue([]byte(`{"A": 6}`), reflect.ValueOf(&v)); err != nil {
+		t.Fatalf("UnmarshalValue error: %v", err)
+	}
+	if v.A != 6 || v.P == nil || !reflect.DeepEqual(*v.P, []string{"x"}) {
+		t.Errorf("UnmarshalValue: got %+v", v)
+	}
+
+	tests := []struct {
+		CaseName
+		in   reflect.Value
+		want string
+	}{
+		{Name("zero"), reflect.Value{}, "json: Unmarshal(nil)"},
+		{Name("unsettable"), reflect.ValueOf(v).Field(0), "json: Unmarshal(non-pointer int)"},
+		{Name("nil pointer"), reflect.ValueOf((*T)(nil)), "json: Unmarshal(nil *json.T)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := UnmarshalValue([]byte(`1`), tt.in)
+			if _, ok := err.(*InvalidUnmarshalError); !ok || err.Error() != tt.want {
+				t.Errorf("%s: UnmarshalValue error: got %v, want %s", tt.Where, err, tt.want)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"encoding"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"math"
 	"reflect"
 	"slices"
@@ -34,6 +35,8 @@ import (
 // to produce JSON. If no [Marshaler.MarshalJSON] method is present but the
 // value implements [encoding.TextMarshaler] instead, Marshal calls
 // [encoding.TextMarshaler.MarshalText] and encodes the result as a JSON string.
+// The AppendText method of encoding.TextAppender, added in Go 1.24, is
+// preferred to MarshalText, as it can avoid allocating.
 // The nil pointer exception is not strictly necessary
 // but mimics a similar, necessary exception in the behavior of
 // [Unmarshaler.UnmarshalJSON].
@@ -71,8 +74,18 @@ import (
 //
 // The "omitempty" option specifies that the field should be omitted
 // from the encoding if the field has an empty value, defined as
-// false, 0, a nil pointer, a nil interface value, and any empty array,
-// slice, map, or string.
+// false, 0, a nil pointer, a nil interface value, function, or channel,
+// and any empty array, slice, map, or string.
+//
+// The "omitdeepempty" option is like "omitempty", but also omits a struct
+// field whose own encodable fields are all empty by the same definition,
+// applied recursively to nested struct fields. A struct type implementing
+// [Marshaler] or [encoding.TextMarshaler], such as [time.Time], is empty
+// only if it is its zero value.
+//
+// The "omitnil" option specifies that the field should be omitted only if
+// it is a nil pointer, interface value, map, slice, function, or channel.
+// Unlike "omitempty", zero values such as false, 0, and "" are still encoded.
 //
 // As a special case, if the field tag is "-", the field is always omitted.
 // Note that a field with name "-" can still be generated using the tag "-,".
@@ -100,11 +113,47 @@ import (
 //
 // The "string" option signals that a field is stored as JSON inside a
 // JSON-encoded string. It applies only to fields of string, floating point,
-// integer, or boolean types. This extra level of encoding is sometimes used
-// when communicating with JavaScript programs:
+// integer, or boolean types, or pointers to them. This extra level of encoding
+// is sometimes used when communicating with JavaScript programs:
 //
 //	Int64String int64 `json:",string"`
 //
+// Using the "string" option on a field of any other type, or of a type
+// implementing [Marshaler] or [encoding.TextMarshaler], is an error.
+// On a nullable field, null is encoded as a bare JSON null rather than
+// as a string.
+//
+// The "format" option selects a built-in encoding for fields of some
+// standard library types, or pointers to them, in place of their usual one:
+//
+//	Created time.Time `json:",format:unix"`
+//
+// For time.Time, the formats rfc3339, rfc3339nano, rfc1123, rfc1123z,
+// date, time, datetime, and kitchen name layouts, unix, unixmilli,
+// unixmicro, and unixnano encode the time as an integer, ttl encodes it as
+// the whole number of seconds from the current time until it, as for the
+// lifetime of a token, and any other format with a layout element is used
+// as a layout; [MarshalOptions.ContextValues] can set the time zone and
+// the current time for reproducible output. For url.URL, url
+// encodes the URL as a string. For netip.Addr, netip.Prefix, and
+// netip.AddrPort, ip, ipv4, and ipv6 encode the value as a string, the
+// latter two requiring an address of that family. For big.Int, number and
+// string encode the integer as a JSON number or string. Unmarshal accepts
+// values in the same form. An unknown format is an error.
+//
+// The "codec" option selects functions registered with [RegisterCodec] to
+// encode and decode the field instead:
+//
+//	Tags []string `json:"tags,codec:csvlist"`
+//
+// The "view" option restricts a field to the views it lists, separated by
+// '|', so that one struct can be encoded for several audiences. The field
+// is encoded only by a [MarshalOptions] selecting one of those views with
+// [MarshalOptions.View], while fields without the option are encoded in
+// every view. Unmarshal ignores the option.
+//
+//	Email string `json:"email,view=admin|internal"`
+//
 // The key name will be used if it's a non-empty string consisting of
 // only Unicode letters, digits, and ASCII punctuation except quotation
 // marks, backslash, and comma.
@@ -117,6 +166,21 @@ import (
 // An anonymous struct field of interface type is treated the same as having
 // that type as its name, rather than being anonymous.
 //
+// The "inline" option, on a field of interface type with no name in its
+// tag, encodes the members of the object its dynamic value encodes as
+// members of the enclosing object, after a "$type" member giving the name
+// under which the value's type is registered with [RegisterType]:
+//
+//	type Event struct {
+//		ID    string `json:"id"`
+//		Shape `json:",inline"` // {"id":"e1","$type":"circle","r":2}
+//	}
+//
+// A nil field is omitted. Marshal returns an error if the type of the value
+// is not registered, if the value does not encode as an object, or if one of
+// its members has the name of a field of the enclosing struct. A struct may
+// have only one inline field, and no other field named "$type".
+//
 // The Go visibility rules for struct fields are amended for JSON when
 // deciding which field to marshal or unmarshal. If there are
 // multiple fields at the same level, and that level is the least
@@ -136,11 +200,12 @@ import (
 // a JSON tag of "-".
 //
 // Map values encode as JSON objects. The map's key type must either be a
-// string, an integer type, or implement [encoding.TextMarshaler]. The map keys
+// string, an integer type, or implement [encoding.TextMarshaler] or
+// encoding.TextAppender. The map keys
 // are sorted and used as JSON object keys by applying the following rules,
 // subject to the UTF-8 coercion described for string values above:
 //   - keys of any string type are used directly
-//   - [encoding.TextMarshalers] are marshaled
+//   - [encoding.TextMarshalers] and TextAppenders are marshaled
 //   - integer keys are converted to strings
 //
 // Pointer values encode as the value pointed to.
@@ -149,7 +214,17 @@ import (
 // Interface values encode as the value contained in the interface.
 // A nil interface value encodes as the null JSON value.
 //
-// Channel, complex, and function values cannot be encoded in JSON.
+// Values of a function type with the underlying type of an [iter.Seq],
+// func(yield func(V) bool), encode as a JSON array of the values yielded.
+// Receive-only channels (<-chan V) encode as a JSON array of the values
+// received until the channel is closed; encoding blocks until then.
+// A nil function or channel encodes as the null JSON value.
+// When encoding with an [Encoder] that has no indentation set, the output
+// is written as the elements are produced; if an element then cannot be
+// encoded, the elements before it are left in the stream, and the Encoder
+// returns the error from every later call.
+//
+// Other channel, complex, and function values cannot be encoded in JSON.
 // Attempting to encode such a value causes Marshal to return
 // an [UnsupportedTypeError].
 //
@@ -157,13 +232,46 @@ import (
 // handle them. Passing cyclic structures to Marshal will result in
 // an error.
 func Marshal(v any) ([]byte, error) {
+	return MarshalOptions{}.Marshal(v)
+}
+
+// MarshalValue is like [Marshal] but encodes the value held by v, without
+// converting it to an interface value first. If v is addressable, the
+// MarshalJSON and MarshalText methods of *T are used for a value of type T,
+// as for the fields of a struct passed to Marshal by pointer. The zero Value
+// encodes as null.
+func MarshalValue(v reflect.Value) ([]byte, error) {
+	return MarshalOptions{}.MarshalValue(v)
+}
+
+// Marshal is like the package-level [Marshal] but encodes v as configured by o.
+func (o MarshalOptions) Marshal(v any) ([]byte, error) {
+	return o.MarshalValue(reflect.ValueOf(v))
+}
+
+// MarshalValue is like the package-level [MarshalValue] but encodes v as
+// configured by o.
+func (o MarshalOptions) MarshalValue(v reflect.Value) ([]byte, error) {
 	e := newEncodeState()
-	defer encodeStatePool.Put(e)
+	defer putEncodeState(e, maxPooledBufferSize)
+	if e.Cap() == 0 {
+		// A new buffer would otherwise be grown, and copied, repeatedly.
+		// Pooled buffers have grown to the size needed by earlier values.
+		e.Grow(estimateValueSize(v))
+	}
 
-	err := e.marshal(v, encOpts{escapeHTML: true})
+	err := e.marshalValue(v, o.encOpts())
 	if err != nil {
 		return nil, err
 	}
+	if o.Interchange {
+		if err := checkInterchange(e.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if o.EscapeRune != nil {
+		return appendEscapeRunes(nil, e.Bytes(), o.EscapeRune), nil
+	}
 	buf := append([]byte(nil), e.Bytes()...)
 
 	return buf, nil
@@ -172,17 +280,36 @@ func Marshal(v any) ([]byte, error) {
 // MarshalIndent is like [Marshal] but applies [Indent] to format the output.
 // Each JSON element in the output will begin on a new line beginning with prefix
 // followed by one or more copies of indent according to the indentation nesting.
+// The contents of a [RawMessage] are reindented like the rest of the output;
+// see [MarshalOptions.VerbatimRawMessages] to keep their layout.
 func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
-	b, err := Marshal(v)
+	return MarshalOptions{}.MarshalIndent(v, prefix, indent)
+}
+
+// MarshalIndent is like the package-level [MarshalIndent] but encodes as
+// configured by o.
+func (o MarshalOptions) MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	e := newEncodeState()
+	defer putEncodeState(e, maxPooledBufferSize)
+
+	err := e.marshal(v, o.encOpts())
 	if err != nil {
 		return nil, err
 	}
-	b2 := make([]byte, 0, indentGrowthFactor*len(b))
-	b2, err = appendIndent(b2, b, prefix, indent)
+	if o.Interchange {
+		if err := checkInterchange(e.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	b := make([]byte, 0, indentGrowthFactor*e.Len())
+	b, err = appendIndentVerbatim(b, e.Bytes(), prefix, indent, e.verbatim)
 	if err != nil {
 		return nil, err
 	}
-	return b2, nil
+	if o.EscapeRune != nil {
+		b = appendEscapeRunes(nil, b, o.EscapeRune)
+	}
+	return b, nil
 }
 
 // Marshaler is the interface implemented by types that
@@ -259,7 +386,19 @@ type encodeState struct {
 	// startDetectingCyclesAfter, so that we skip the work if we're within a
 	// reasonable amount of nested pointers deep.
 	ptrLevel uint
-	ptrSeen  map[any]struct{}
+	ptrSeen  map[any]int // offset in the output of each value being encoded
+
+	// stream, if set, is where an Encoder writes the output; it allows
+	// a StreamString to flush the output before the value is complete.
+	stream    io.Writer
+	streamErr error // error writing to stream
+	flushed   bool  // whether output has been written to stream
+
+	scratch [64]byte // for text appended by encoding.TextAppenders
+
+	// verbatim holds the start and end offsets of the RawMessages written
+	// with their formatting kept.
+	verbatim []int
 }
 
 const startDetectingCyclesAfter = 1000
@@ -274,9 +413,27 @@ func newEncodeState() *encodeState {
 			panic("ptrEncoder.encode should have emptied ptrSeen via defers")
 		}
 		e.ptrLevel = 0
+		e.stream = nil
+		e.streamErr = nil
+		e.flushed = false
+		e.verbatim = e.verbatim[:0]
 		return e
 	}
-	return &encodeState{ptrSeen: make(map[any]struct{})}
+	return &encodeState{ptrSeen: make(map[any]int)}
+}
+
+// maxPooledBufferSize is the capacity, in bytes, above which the buffer of an
+// encodeState is not kept in encodeStatePool, so that encoding one very large
+// value does not keep its memory in use.
+const maxPooledBufferSize = 64 << 10
+
+// putEncodeState returns e to encodeStatePool, unless its buffer has grown
+// larger than limit bytes.
+func putEncodeState(e *encodeState, limit int) {
+	if e.Cap() > limit {
+		return
+	}
+	encodeStatePool.Put(e)
 }
 
 // jsonError is an error wrapper type for internal use only.
@@ -284,7 +441,11 @@ func newEncodeState() *encodeState {
 // can distinguish intentional panics from this package.
 type jsonError struct{ error }
 
-func (e *encodeState) marshal(v any, opts encOpts) (err error) {
+func (e *encodeState) marshal(v any, opts encOpts) error {
+	return e.marshalValue(reflect.ValueOf(v), opts)
+}
+
+func (e *encodeState) marshalValue(v reflect.Value, opts encOpts) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if je, ok := r.(jsonError); ok {
@@ -294,7 +455,7 @@ func (e *encodeState) marshal(v any, opts encOpts) (err error) {
 			}
 		}
 	}()
-	e.reflectValue(reflect.ValueOf(v), opts)
+	e.reflectValue(v, opts)
 	return nil
 }
 
@@ -311,12 +472,54 @@ func isEmptyValue(v reflect.Value) bool {
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
 		reflect.Float32, reflect.Float64,
-		reflect.Interface, reflect.Pointer:
+		reflect.Interface, reflect.Pointer, reflect.Func, reflect.Chan:
 		return v.IsZero()
 	}
 	return false
 }
 
+// isDeepEmptyValue is like isEmptyValue, but also reports a struct as empty
+// if every field that would be encoded is itself deeply empty.
+func isDeepEmptyValue(v reflect.Value) bool {
+	if v.Kind() != reflect.Struct {
+		return isEmptyValue(v)
+	}
+	if implementsMarshaler(v.Type()) {
+		return v.IsZero()
+	}
+	fields := cachedTypeFields(v.Type())
+	if fields.error != nil {
+		return false
+	}
+FieldLoop:
+	for i := range fields.list {
+		fv := v
+		for _, i := range fields.list[i].index {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue FieldLoop
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(i)
+		}
+		if !isDeepEmptyValue(fv) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNilValue reports whether v is a nil pointer, interface, map, slice,
+// function, or channel.
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
 func (e *encodeState) reflectValue(v reflect.Value, opts encOpts) {
 	valueEncoder(v)(e, v, opts)
 }
@@ -326,11 +529,34 @@ type encOpts struct {
 	quoted bool
 	// escapeHTML causes '<', '>', and '&' to be escaped in JSON strings.
 	escapeHTML bool
+	// omitUnset causes nil struct fields to be omitted as if tagged optional.
+	omitUnset bool
+	// mask restricts which object members are encoded. nil means no restriction.
+	mask maskTree
+	// filter applies the Include and Exclude patterns. nil means no filtering.
+	filter *pathFilter
+	// detectCyclesAfter and cycles configure the detection of cycles.
+	detectCyclesAfter int
+	cycles            CyclePolicy
+	// verifyEnums causes values of enum-tagged fields to be checked.
+	verifyEnums bool
+	// interchange causes strings that are not valid UTF-8 to be rejected
+	// rather than coerced.
+	interchange bool
+	// typedInterfaces causes interface values to be encoded with the
+	// registered name of their dynamic type.
+	typedInterfaces bool
+	// verbatimRaw causes the formatting of RawMessages to be kept.
+	verbatimRaw bool
+	// view selects the fields with a view option that are encoded.
+	view string
+	// ctx holds the values consulted by the encodings of standard types.
+	ctx *ContextValues
 }
 
 type encoderFunc func(e *encodeState, v reflect.Value, opts encOpts)
 
-var encoderCache sync.Map // map[reflect.Type]encoderFunc
+var encoderCache typeCache // map[reflect.Type]encoderFunc
 
 func valueEncoder(v reflect.Value) encoderFunc {
 	if !v.IsValid() {
@@ -353,7 +579,7 @@ func typeEncoder(t reflect.Type) encoderFunc {
 		f  encoderFunc
 	)
 	wg.Add(1)
-	fi, loaded := encoderCache.LoadOrStore(t, encoderFunc(func(e *encodeState, v reflect.Value, opts encOpts) {
+	fi, loaded := encoderCache.LoadOrStorePending(t, encoderFunc(func(e *encodeState, v reflect.Value, opts encOpts) {
 		wg.Wait()
 		f(e, v, opts)
 	}))
@@ -371,11 +597,37 @@ func typeEncoder(t reflect.Type) encoderFunc {
 var (
 	marshalerType     = reflect.TypeFor[Marshaler]()
 	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	textAppenderType  = reflect.TypeFor[textAppender]()
 )
 
+// textAppender is the encoding.TextAppender interface added in Go 1.24.
+// It is declared here so that it is recognized with older versions as well.
+type textAppender interface {
+	AppendText(b []byte) ([]byte, error)
+}
+
+// implementsText reports whether t implements [encoding.TextMarshaler]
+// or encoding.TextAppender.
+func implementsText(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || t.Implements(textAppenderType)
+}
+
 // newTypeEncoder constructs an encoderFunc for a type.
 // The returned encoder only checks CanAddr when allowAddr is true.
 func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
+	if t == readerType || t == streamStringType {
+		return readerEncoder
+	}
+	if t == timeType || t == timePtrType {
+		return timeEncoder
+	}
+	if ec := lookupEnum(t); ec != nil {
+		return ec.encode
+	}
+	if t.Kind() == reflect.Pointer && lookupEnum(t.Elem()) != nil {
+		// Rather than the methods of the enum type, promoted to *T.
+		return newPtrEncoder(t)
+	}
 	// If we have a non-pointer value whose type implements
 	// Marshaler with a value receiver, then we're better off taking
 	// the address of the value - otherwise we end up with an
@@ -386,6 +638,12 @@ func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
 	if t.Implements(marshalerType) {
 		return marshalerEncoder
 	}
+	if t.Kind() != reflect.Pointer && allowAddr && reflect.PointerTo(t).Implements(textAppenderType) {
+		return newCondAddrEncoder(addrTextAppenderEncoder, newTypeEncoder(t, false))
+	}
+	if t.Implements(textAppenderType) {
+		return textAppenderEncoder
+	}
 	if t.Kind() != reflect.Pointer && allowAddr && reflect.PointerTo(t).Implements(textMarshalerType) {
 		return newCondAddrEncoder(addrTextMarshalerEncoder, newTypeEncoder(t, false))
 	}
@@ -409,6 +667,9 @@ func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
 	case reflect.Interface:
 		return interfaceEncoder
 	case reflect.Struct:
+		if null, maybe := wrapperKind(t); null || maybe {
+			return newWrapperEncoder(t)
+		}
 		return newStructEncoder(t)
 	case reflect.Map:
 		return newMapEncoder(t)
@@ -418,6 +679,16 @@ func newTypeEncoder(t reflect.Type, allowAddr bool) encoderFunc {
 		return newArrayEncoder(t)
 	case reflect.Pointer:
 		return newPtrEncoder(t)
+	case reflect.Func:
+		if isSeq(t) {
+			return newSeqEncoder(t)
+		}
+		return unsupportedTypeEncoder
+	case reflect.Chan:
+		if t.ChanDir() == reflect.RecvDir {
+			return newChanEncoder(t)
+		}
+		return unsupportedTypeEncoder
 	default:
 		return unsupportedTypeEncoder
 	}
@@ -439,10 +710,7 @@ func marshalerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 	}
 	b, err := m.MarshalJSON()
 	if err == nil {
-		e.Grow(len(b))
-		out := e.AvailableBuffer()
-		out, err = appendCompact(out, b, opts.escapeHTML)
-		e.Buffer.Write(out)
+		err = e.writeMarshaled(m, b, opts)
 	}
 	if err != nil {
 		e.error(&MarshalerError{v.Type(), err, "MarshalJSON"})
@@ -458,16 +726,52 @@ func addrMarshalerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 	m := va.Interface().(Marshaler)
 	b, err := m.MarshalJSON()
 	if err == nil {
-		e.Grow(len(b))
-		out := e.AvailableBuffer()
-		out, err = appendCompact(out, b, opts.escapeHTML)
-		e.Buffer.Write(out)
+		err = e.writeMarshaled(m, b, opts)
 	}
 	if err != nil {
 		e.error(&MarshalerError{v.Type(), err, "MarshalJSON"})
 	}
 }
 
+// writeMarshaled writes the output b of m.MarshalJSON, compacted
+// unless m is a RawMessage whose formatting is to be kept, or a RawNumber
+// or RawString, which is written as it is.
+func (e *encodeState) writeMarshaled(m Marshaler, b []byte, opts encOpts) error {
+	switch m.(type) {
+	case RawMessage, *RawMessage:
+		if opts.verbatimRaw {
+			return e.writeVerbatim(b)
+		}
+	case RawNumber, *RawNumber, RawString, *RawString:
+		e.Write(b)
+		return nil
+	}
+	e.Grow(len(b))
+	out := e.AvailableBuffer()
+	out, err := appendCompact(out, b, opts.escapeHTML)
+	e.Buffer.Write(out)
+	return err
+}
+
+// writeVerbatim writes the JSON value b without its surrounding white space
+// and records its position for MarshalOptions.MarshalIndent.
+func (e *encodeState) writeVerbatim(b []byte) error {
+	var scan scanner
+	if err := checkValid(b, &scan); err != nil {
+		return err
+	}
+	start, end := 0, len(b)
+	for isSpace(b[start]) {
+		start++
+	}
+	for isSpace(b[end-1]) {
+		end--
+	}
+	e.verbatim = append(e.verbatim, e.Len(), e.Len()+end-start)
+	e.Write(b[start:end])
+	return nil
+}
+
 func textMarshalerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 	if v.Kind() == reflect.Pointer && v.IsNil() {
 		e.WriteString("null")
@@ -482,6 +786,9 @@ func textMarshalerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 	if err != nil {
 		e.error(&MarshalerError{v.Type(), err, "MarshalText"})
 	}
+	if opts.interchange && !utf8.ValidString(string(b)) {
+		e.error(&UnsupportedValueError{v, "invalid UTF-8 in string " + strconv.Quote(string(b))})
+	}
 	e.Write(appendString(e.AvailableBuffer(), b, opts.escapeHTML))
 }
 
@@ -496,6 +803,44 @@ func addrTextMarshalerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 	if err != nil {
 		e.error(&MarshalerError{v.Type(), err, "MarshalText"})
 	}
+	if opts.interchange && !utf8.ValidString(string(b)) {
+		e.error(&UnsupportedValueError{v, "invalid UTF-8 in string " + strconv.Quote(string(b))})
+	}
+	e.Write(appendString(e.AvailableBuffer(), b, opts.escapeHTML))
+}
+
+func textAppenderEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		e.WriteString("null")
+		return
+	}
+	m, ok := v.Interface().(textAppender)
+	if !ok {
+		e.WriteString("null")
+		return
+	}
+	e.appendText(v, m, opts)
+}
+
+func addrTextAppenderEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	va := v.Addr()
+	if va.IsNil() {
+		e.WriteString("null")
+		return
+	}
+	e.appendText(v, va.Interface().(textAppender), opts)
+}
+
+// appendText encodes the text appended by m as a JSON string, using
+// e.scratch to avoid allocating when the text is short.
+func (e *encodeState) appendText(v reflect.Value, m textAppender, opts encOpts) {
+	b, err := m.AppendText(e.scratch[:0])
+	if err != nil {
+		e.error(&MarshalerError{v.Type(), err, "AppendText"})
+	}
+	if opts.interchange && !utf8.Valid(b) {
+		e.error(&UnsupportedValueError{v, "invalid UTF-8 in string " + strconv.Quote(string(b))})
+	}
 	e.Write(appendString(e.AvailableBuffer(), b, opts.escapeHTML))
 }
 
@@ -582,6 +927,9 @@ func stringEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 		e.Write(b)
 		return
 	}
+	if opts.interchange && !utf8.ValidString(v.String()) {
+		e.error(&UnsupportedValueError{v, "invalid UTF-8 in string " + strconv.Quote(v.String())})
+	}
 	if opts.quoted {
 		b := appendString(nil, v.String(), opts.escapeHTML)
 		e.Write(appendString(e.AvailableBuffer(), b, false)) // no need to escape again since it is already escaped
@@ -655,6 +1003,10 @@ func interfaceEncoder(e *encodeState, v reflect.Value, opts encOpts) {
 		e.WriteString("null")
 		return
 	}
+	if opts.typedInterfaces {
+		typedInterfaceEncoder(e, v, opts)
+		return
+	}
 	e.reflectValue(v.Elem(), opts)
 }
 
@@ -664,6 +1016,7 @@ func unsupportedTypeEncoder(e *encodeState, v reflect.Value, _ encOpts) {
 
 type structEncoder struct {
 	fields structFields
+	views  map[string][]field // see fieldViews
 }
 
 type structFields struct {
@@ -679,10 +1032,30 @@ func (se structEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 		e.error(se.fields.error)
 	}
 
+	list := se.fields.list
+	if se.views != nil {
+		var ok bool
+		if list, ok = se.views[opts.view]; !ok {
+			list = se.views[""]
+		}
+	}
 	next := byte('{')
+	mask, filter := opts.mask, opts.filter
 FieldLoop:
-	for i := range se.fields.list {
-		f := &se.fields.list[i]
+	for i := range list {
+		f := &list[i]
+		if f.inline {
+			next = se.encodeInline(e, v, f, next, opts)
+			continue
+		}
+		fieldMask, ok := mask.selects(f.name)
+		if !ok {
+			continue
+		}
+		fieldFilter, ok := filter.selects(f.name)
+		if !ok {
+			continue
+		}
 		fNameColon := f.nameNonEsc
 		if opts.escapeHTML {
 			fNameColon = f.nameEscHTML
@@ -705,9 +1078,21 @@ FieldLoop:
 			fv = fv.Field(i)
 		}
 
+		if f.maybe && !fv.Field(1).Bool() {
+			continue
+		}
 		if f.omitEmpty && isEmptyValue(fv) {
 			continue
 		}
+		if f.omitDeepEmpty && isDeepEmptyValue(fv) {
+			continue
+		}
+		if f.omitNil && isNilValue(fv) {
+			continue
+		}
+		if opts.omitUnset && !f.optional && !f.nullable && isNilValue(fv) {
+			continue
+		}
 		if f.optional {
 			if fv.IsNil() {
 				continue
@@ -724,10 +1109,17 @@ FieldLoop:
 			fv = fv.Elem()
 		}
 
+		if f.enum != nil && opts.verifyEnums {
+			if s, ok := enumValue(fv); ok && !slices.Contains(f.enum, s) {
+				e.error(&SemanticError{Type: fv.Type(), Err: ErrInvalidEnum, msg: fmt.Sprintf("json: invalid value %q for field %q, must be one of %s", s, f.name, strings.Join(f.enum, "|"))})
+			}
+		}
+
 		e.WriteByte(next)
 		next = ','
 		e.WriteString(fNameColon)
 		opts.quoted = f.quoted
+		opts.mask, opts.filter = fieldMask, fieldFilter
 		f.encoder(e, fv, opts)
 	}
 	if next == '{' {
@@ -737,13 +1129,27 @@ FieldLoop:
 	}
 }
 
+// enumValue returns the string held by v, following pointers,
+// and whether there is one.
+func enumValue(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	return v.String(), v.Kind() == reflect.String
+}
+
 func newStructEncoder(t reflect.Type) encoderFunc {
 	se := structEncoder{fields: cachedTypeFields(t)}
+	se.views = fieldViews(se.fields.list)
 	return se.encode
 }
 
 type mapEncoder struct {
-	elemEnc encoderFunc
+	elemEnc   encoderFunc
+	maybeElem bool // the values are Maybes, omitted when absent
 }
 
 func (me mapEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
@@ -751,40 +1157,57 @@ func (me mapEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 		e.WriteString("null")
 		return
 	}
-	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+	if e.ptrLevel++; e.ptrLevel > opts.cycleDepth() {
 		// We're a large number of nested ptrEncoder.encode calls deep;
 		// start checking if we've run into a pointer cycle.
 		ptr := v.UnsafePointer()
-		if _, ok := e.ptrSeen[ptr]; ok {
-			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		if !e.visit(ptr, v, opts) {
+			e.ptrLevel--
+			return
 		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer delete(e.ptrSeen, ptr)
 	}
 	e.WriteByte('{')
 
 	// Extract and sort the keys.
 	var (
-		sv  = make([]reflectWithString, v.Len())
+		sv  = make([]reflectWithString, 0, v.Len())
 		mi  = v.MapRange()
 		err error
 	)
-	for i := 0; mi.Next(); i++ {
-		if sv[i].ks, err = resolveKeyName(mi.Key()); err != nil {
+	for mi.Next() {
+		var kv reflectWithString
+		if kv.ks, err = resolveKeyName(mi.Key()); err != nil {
 			e.error(fmt.Errorf("json: encoding error for type %q: %q", v.Type().String(), err.Error()))
 		}
-		sv[i].v = mi.Value()
+		if opts.interchange && !utf8.ValidString(kv.ks) {
+			e.error(&UnsupportedValueError{mi.Key(), "invalid UTF-8 in map key " + strconv.Quote(kv.ks)})
+		}
+		if _, ok := opts.mask.selects(kv.ks); !ok {
+			continue
+		}
+		if _, ok := opts.filter.selects(kv.ks); !ok {
+			continue
+		}
+		kv.v = mi.Value()
+		if me.maybeElem && isAbsent(kv.v) {
+			continue
+		}
+		sv = append(sv, kv)
 	}
 	slices.SortFunc(sv, func(i, j reflectWithString) int {
 		return strings.Compare(i.ks, j.ks)
 	})
 
+	mask, filter := opts.mask, opts.filter
 	for i, kv := range sv {
 		if i > 0 {
 			e.WriteByte(',')
 		}
 		e.Write(appendString(e.AvailableBuffer(), kv.ks, opts.escapeHTML))
 		e.WriteByte(':')
+		opts.mask, _ = mask.selects(kv.ks)
+		opts.filter, _ = filter.selects(kv.ks)
 		me.elemEnc(e, kv.v, opts)
 	}
 	e.WriteByte('}')
@@ -797,11 +1220,15 @@ func newMapEncoder(t reflect.Type) encoderFunc {
 		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 	default:
-		if !t.Key().Implements(textMarshalerType) {
+		if !implementsText(t.Key()) {
 			return unsupportedTypeEncoder
 		}
 	}
-	me := mapEncoder{typeEncoder(t.Elem())}
+	if err := checkElem(t.Elem()); err != nil {
+		return errorEncoder(err)
+	}
+	_, maybe := wrapperKind(t.Elem())
+	me := mapEncoder{typeEncoder(t.Elem()), maybe}
 	return me.encode
 }
 
@@ -829,7 +1256,7 @@ func (se sliceEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 		e.WriteString("null")
 		return
 	}
-	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+	if e.ptrLevel++; e.ptrLevel > opts.cycleDepth() {
 		// We're a large number of nested ptrEncoder.encode calls deep;
 		// start checking if we've run into a pointer cycle.
 		// Here we use a struct to memorize the pointer to the first element of the slice
@@ -838,10 +1265,10 @@ func (se sliceEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 			ptr interface{} // always an unsafe.Pointer, but avoids a dependency on package unsafe
 			len int
 		}{v.UnsafePointer(), v.Len()}
-		if _, ok := e.ptrSeen[ptr]; ok {
-			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		if !e.visit(ptr, v, opts) {
+			e.ptrLevel--
+			return
 		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer delete(e.ptrSeen, ptr)
 	}
 	se.arrayEnc(e, v, opts)
@@ -852,7 +1279,7 @@ func newSliceEncoder(t reflect.Type) encoderFunc {
 	// Byte slices get special treatment; arrays don't.
 	if t.Elem().Kind() == reflect.Uint8 {
 		p := reflect.PointerTo(t.Elem())
-		if !p.Implements(marshalerType) && !p.Implements(textMarshalerType) {
+		if !p.Implements(marshalerType) && !implementsText(p) {
 			return encodeByteSlice
 		}
 	}
@@ -877,10 +1304,89 @@ func (ae arrayEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 }
 
 func newArrayEncoder(t reflect.Type) encoderFunc {
+	if err := checkElem(t.Elem()); err != nil {
+		return errorEncoder(err)
+	}
 	enc := arrayEncoder{typeEncoder(t.Elem())}
 	return enc.encode
 }
 
+// isSeq reports whether t has the underlying type of an iter.Seq,
+// func(yield func(V) bool).
+func isSeq(t reflect.Type) bool {
+	if t.NumIn() != 1 || t.NumOut() != 0 || t.IsVariadic() {
+		return false
+	}
+	y := t.In(0)
+	return y.Kind() == reflect.Func && y.NumIn() == 1 && y.NumOut() == 1 &&
+		!y.IsVariadic() && y.Out(0).Kind() == reflect.Bool
+}
+
+// seqEncoder encodes an iter.Seq as an array of the values it yields.
+type seqEncoder struct {
+	yieldType reflect.Type
+	elemEnc   encoderFunc
+}
+
+var trueValue = reflect.ValueOf(true)
+
+func (se seqEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
+	if v.IsNil() {
+		e.WriteString("null")
+		return
+	}
+	e.WriteByte('[')
+	first := true
+	yield := reflect.MakeFunc(se.yieldType, func(args []reflect.Value) []reflect.Value {
+		if !first {
+			e.WriteByte(',')
+		}
+		first = false
+		se.elemEnc(e, args[0], opts)
+		e.flushStream()
+		return []reflect.Value{trueValue.Convert(se.yieldType.Out(0))}
+	})
+	v.Call([]reflect.Value{yield})
+	e.WriteByte(']')
+}
+
+func newSeqEncoder(t reflect.Type) encoderFunc {
+	y := t.In(0)
+	enc := seqEncoder{y, typeEncoder(y.In(0))}
+	return enc.encode
+}
+
+// chanEncoder encodes a receive-only channel as an array of the values
+// received from it until it is closed.
+type chanEncoder struct {
+	elemEnc encoderFunc
+}
+
+func (ce chanEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
+	if v.IsNil() {
+		e.WriteString("null")
+		return
+	}
+	e.WriteByte('[')
+	for i := 0; ; i++ {
+		x, ok := v.Recv()
+		if !ok {
+			break
+		}
+		if i > 0 {
+			e.WriteByte(',')
+		}
+		ce.elemEnc(e, x, opts)
+		e.flushStream()
+	}
+	e.WriteByte(']')
+}
+
+func newChanEncoder(t reflect.Type) encoderFunc {
+	enc := chanEncoder{typeEncoder(t.Elem())}
+	return enc.encode
+}
+
 type ptrEncoder struct {
 	elemEnc encoderFunc
 }
@@ -890,14 +1396,14 @@ func (pe ptrEncoder) encode(e *encodeState, v reflect.Value, opts encOpts) {
 		e.WriteString("null")
 		return
 	}
-	if e.ptrLevel++; e.ptrLevel > startDetectingCyclesAfter {
+	if e.ptrLevel++; e.ptrLevel > opts.cycleDepth() {
 		// We're a large number of nested ptrEncoder.encode calls deep;
 		// start checking if we've run into a pointer cycle.
 		ptr := v.Interface()
-		if _, ok := e.ptrSeen[ptr]; ok {
-			e.error(&UnsupportedValueError{v, fmt.Sprintf("encountered a cycle via %s", v.Type())})
+		if !e.visit(ptr, v, opts) {
+			e.ptrLevel--
+			return
 		}
-		e.ptrSeen[ptr] = struct{}{}
 		defer delete(e.ptrSeen, ptr)
 	}
 	pe.elemEnc(e, v.Elem(), opts)
@@ -964,6 +1470,17 @@ func resolveKeyName(k reflect.Value) (string, error) {
 	if k.Kind() == reflect.String {
 		return k.String(), nil
 	}
+	if ec := lookupEnum(k.Type()); ec != nil {
+		return ec.keyName(k)
+	}
+	if ta, ok := k.Interface().(textAppender); ok {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		var buf [64]byte
+		b, err := ta.AppendText(buf[:0])
+		return string(b), err
+	}
 	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
 		if k.Kind() == reflect.Pointer && k.IsNil() {
 			return "", nil
@@ -1060,13 +1577,24 @@ type field struct {
 	nameNonEsc  string // `"` + name + `":`
 	nameEscHTML string // `"` + HTMLEscape(name) + `":`
 
-	tag       bool
-	index     []int
-	typ       reflect.Type
-	omitEmpty bool
-	quoted    bool
-	nullable  bool
-	optional  bool
+	tag           bool
+	index         []int
+	typ           reflect.Type
+	omitEmpty     bool
+	quoted        bool
+	omitDeepEmpty bool
+	omitNil       bool
+	stringOpt     bool // the string option was given, whether or not it applies
+	nullable      bool
+	optional      bool
+	emptyAsNull   bool     // an empty string decodes as null
+	maybe         bool     // the field is a Maybe, omitted when absent
+	enum          []string // allowed string values, if constrained
+	format        string   // name of the format option, if any
+	codec         string   // name of the codec option, if any
+	inline        bool     // an interface whose dynamic value's members are inlined
+	views         []string // the views the field is restricted to, if any
+	fieldFormat   *fieldFormat
 
 	encoder encoderFunc
 }
@@ -1120,16 +1648,28 @@ func typeFields(t reflect.Type) structFields {
 			}
 			visited[f.typ] = true
 
+			naming, defaults, hasDefaults, err := structDefaults(f.typ)
+			if err != nil {
+				return structFields{nil, nil, nil, nil, err}
+			}
+
 			// Scan f.typ for fields to include.
 			for i := 0; i < f.typ.NumField(); i++ {
 				sf := f.typ.Field(i)
+				if sf.Anonymous && sf.Type == optionsType {
+					continue
+				}
 				if sf.Anonymous {
 					t := sf.Type
 					if t.Kind() == reflect.Pointer {
 						t = t.Elem()
 					}
 					if !sf.IsExported() && t.Kind() != reflect.Struct {
-						// Ignore embedded fields of unexported non-struct types.
+						// Ignore embedded fields of unexported non-struct types,
+						// unless they are asking to be inlined.
+						if _, opts := parseTag(sf.Tag.Get("json")); opts.Contains("inline") {
+							return structFields{nil, nil, nil, nil, fmt.Errorf("json: inline field %s is unexported", sf.Name)}
+						}
 						continue
 					}
 					// Do not ignore embedded fields of unexported struct types
@@ -1146,6 +1686,9 @@ func typeFields(t reflect.Type) structFields {
 				if !isValidTag(name) {
 					name = ""
 				}
+				if hasDefaults && !strings.Contains(tag, ",") {
+					opts = defaults
+				}
 				index := make([]int, len(f.index)+1)
 				copy(index, f.index)
 				index[len(f.index)] = i
@@ -1157,15 +1700,26 @@ func typeFields(t reflect.Type) structFields {
 				}
 
 				// Only strings, floats, integers, and booleans can be quoted.
+				// The pointers required by the optional and nullable options
+				// are followed first. Marshalers encode themselves, so the
+				// option cannot apply to them.
 				quoted := false
-				if opts.Contains("string") {
-					switch ft.Kind() {
+				stringOpt := opts.Contains("string")
+				if stringOpt {
+					qt := sf.Type
+					for n := indirectionsRequired(opts); n > 0 && qt.Kind() == reflect.Pointer; n-- {
+						qt = qt.Elem()
+					}
+					if qt.Name() == "" && qt.Kind() == reflect.Pointer {
+						qt = qt.Elem()
+					}
+					switch qt.Kind() {
 					case reflect.Bool,
 						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
 						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
 						reflect.Float32, reflect.Float64,
 						reflect.String:
-						quoted = true
+						quoted = !implementsMarshaler(qt)
 					}
 				}
 
@@ -1173,17 +1727,48 @@ func typeFields(t reflect.Type) structFields {
 				if name != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
 					tagged := name != ""
 					if name == "" {
-						name = sf.Name
+						name = applyNaming(naming, sf.Name)
 					}
 					field := field{
-						name:      name,
-						tag:       tagged,
-						index:     index,
-						typ:       ft,
-						omitEmpty: opts.Contains("omitempty"),
-						quoted:    quoted,
-						nullable:  opts.Contains("nullable"),
-						optional:  opts.Contains("optional"),
+						name:          name,
+						tag:           tagged,
+						index:         index,
+						typ:           ft,
+						omitEmpty:     opts.Contains("omitempty"),
+						quoted:        quoted,
+						stringOpt:     stringOpt,
+						nullable:      opts.Contains("nullable"),
+						optional:      opts.Contains("optional"),
+						omitDeepEmpty: opts.Contains("omitdeepempty"),
+						omitNil:       opts.Contains("omitnil"),
+						emptyAsNull:   opts.Contains("emptyasnull"),
+					}
+					_, field.maybe = wrapperKind(sf.Type)
+					if enum, ok := opts.Lookup("enum"); ok {
+						field.enum = strings.Split(enum, "|")
+					}
+					field.format, _ = opts.Lookup("format")
+					field.codec, _ = opts.Lookup("codec")
+					if views, ok := opts.Lookup("view"); ok {
+						field.views = strings.Split(views, "|")
+						if slices.Contains(field.views, "") {
+							return structFields{nil, nil, nil, nil, fmt.Errorf("json: empty view name in tag of field %s", sf.Name)}
+						}
+					}
+					if opts.Contains("inline") {
+						var err error
+						switch {
+						case sf.Type.Kind() != reflect.Interface:
+							err = fmt.Errorf("json: inline option on field %s of non-interface type %q", sf.Name, sf.Type.String())
+						case tagged || string(opts) != "inline":
+							err = fmt.Errorf("json: inline field %s cannot have a name or other options", sf.Name)
+						}
+						if err != nil {
+							return structFields{nil, nil, nil, nil, err}
+						}
+						// The field holds the $type member naming the type
+						// of the value whose members are inlined.
+						field.name, field.inline = typeMember, true
 					}
 					field.nameBytes = []byte(field.name)
 
@@ -1212,6 +1797,10 @@ func typeFields(t reflect.Type) structFields {
 		}
 	}
 
+	if err := checkInlineFields(t, fields); err != nil {
+		return structFields{nil, nil, nil, nil, err}
+	}
+
 	sort.Slice(fields, func(i, j int) bool {
 		x := fields
 		// sort field by name, breaking ties with depth, then
@@ -1291,7 +1880,26 @@ func typeFields(t reflect.Type) structFields {
 			fieldType = fieldType.Elem()
 		}
 
-		f.encoder = typeEncoder(fieldType)
+		if f.codec != "" {
+			var err error
+			if f.format != "" {
+				err = fmt.Errorf("json: field %q cannot have both format and codec tags", f.name)
+			} else if f.fieldFormat, err = lookupCodec(fieldType, f.codec); err != nil {
+				err = fmt.Errorf("json: %v for field %q of type %q", err, f.name, typeByIndex(t, f.index).String())
+			}
+			if err != nil {
+				return structFields{nil, nil, nil, nil, err}
+			}
+			f.encoder = formatEncoder(f.fieldFormat)
+		} else if f.format != "" {
+			if f.fieldFormat = lookupFormat(fieldType, f.format); f.fieldFormat == nil {
+				err := fmt.Errorf("json: unknown format %q for field %q of type %q", f.format, f.name, typeByIndex(t, f.index).String())
+				return structFields{nil, nil, nil, nil, err}
+			}
+			f.encoder = formatEncoder(f.fieldFormat)
+		} else {
+			f.encoder = typeEncoder(fieldType)
+		}
 	}
 	return structFields{fields, exactNameIndex, foldedNameIndex, nonoptionalNullables, nil}
 }
@@ -1312,7 +1920,7 @@ func dominantField(fields []field) (field, bool) {
 	return fields[0], true
 }
 
-var fieldCache sync.Map // map[reflect.Type]structFields
+var fieldCache typeCache // map[reflect.Type]structFields
 
 // cachedTypeFields is like typeFields but uses a cache to avoid repeated work.
 func cachedTypeFields(t reflect.Type) structFields {
@@ -1323,6 +1931,27 @@ func cachedTypeFields(t reflect.Type) structFields {
 	return f.(structFields)
 }
 
+// indirectionsRequired returns the number of pointers the optional and
+// nullable options of a field require.
+func indirectionsRequired(opts tagOptions) int {
+	n := 0
+	if opts.Contains("optional") {
+		n++
+	}
+	if opts.Contains("nullable") {
+		n++
+	}
+	return n
+}
+
+// implementsMarshaler reports whether t or *t implements
+// [Marshaler] or [encoding.TextMarshaler].
+func implementsMarshaler(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(marshalerType) || pt.Implements(marshalerType) ||
+		implementsText(t) || implementsText(pt)
+}
+
 func mayAppendQuote(b []byte, quoted bool) []byte {
 	if quoted {
 		b = append(b, '"')
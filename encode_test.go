@@ -15,7 +15,9 @@ import (
 	"regexp"
 	"runtime/debug"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 type Optionals struct {
@@ -190,6 +192,259 @@ func TestOptionalNullable(t *testing.T) {
 	}
 }
 
+func TestMarshalOmitDeepEmpty(t *testing.T) {
+	type Address struct {
+		City string   `json:"city"`
+		Tags []string `json:"tags"`
+	}
+	type Embedded struct {
+		E int `json:"e"`
+	}
+	type Profile struct {
+		*Embedded
+		Home    Address   `json:"home"`
+		When    time.Time `json:"when"`
+		Ignored int       `json:"-"`
+	}
+	type T struct {
+		Name    string  `json:"name,omitdeepempty"`
+		Profile Profile `json:"profile,omitdeepempty"`
+		Plain   Address `json:"plain,omitempty"`
+	}
+
+	cases := []struct {
+		CaseName
+		in   T
+		want string
+	}{
+		{Name("zero value"), T{}, `{"plain":{"city":"","tags":null}}`},
+		{Name("ignored and nil embedded fields"), T{Profile: Profile{Ignored: 1}}, `{"plain":{"city":"","tags":null}}`},
+		{Name("embedded field"), T{Profile: Profile{Embedded: &Embedded{}}}, `{"plain":{"city":"","tags":null}}`},
+		{Name("nested field set"), T{Profile: Profile{Home: Address{Tags: []string{"x"}}}},
+			`{"profile":{"home":{"city":"","tags":["x"]},"when":"0001-01-01T00:00:00Z"},"plain":{"city":"","tags":null}}`},
+		{Name("marshaler set"), T{Name: "n", Profile: Profile{When: time.Unix(0, 0).UTC()}},
+			`{"name":"n","profile":{"home":{"city":"","tags":null},"when":"1970-01-01T00:00:00Z"},"plain":{"city":"","tags":null}}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	_, err := Marshal(struct {
+		X *int `json:"x,optional,omitdeepempty"`
+	}{})
+	if want := `json: field "x" cannot have both omitdeepempty and optional tags`; err == nil || err.Error() != want {
+		t.Errorf("Marshal error:\n\tgot:  %v\n\twant: %s", err, want)
+	}
+}
+
+func TestMarshalOmitNil(t *testing.T) {
+	type T struct {
+		B   bool           `json:"b,omitnil"`
+		I   int            `json:"i,omitnil"`
+		S   string         `json:"s,omitnil"`
+		P   *int           `json:"p,omitnil"`
+		Sl  []int          `json:"sl,omitnil"`
+		M   map[string]int `json:"m,omitnil"`
+		Any any            `json:"any,omitnil"`
+	}
+	zero := 0
+	cases := []struct {
+		CaseName
+		in   T
+		want string
+	}{
+		{Name("zero value"), T{}, `{"b":false,"i":0,"s":""}`},
+		{Name("empty but not nil"), T{P: &zero, Sl: []int{}, M: map[string]int{}, Any: 0},
+			`{"b":false,"i":0,"s":"","p":0,"sl":[],"m":{},"any":0}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	_, err := Marshal(struct {
+		X *int `json:"x,nullable,omitnil"`
+	}{})
+	if want := `json: field "x" cannot have both omitnil and nullable tags`; err == nil || err.Error() != want {
+		t.Errorf("Marshal error:\n\tgot:  %v\n\twant: %s", err, want)
+	}
+}
+
+type intSeq func(yield func(int) bool)
+
+func TestMarshalSeqAndChan(t *testing.T) {
+	count := func(n int) func(func(int) bool) {
+		return func(yield func(int) bool) {
+			for i := 0; i < n; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+	}
+	recv := func(vs ...string) <-chan string {
+		c := make(chan string, len(vs))
+		for _, v := range vs {
+			c <- v
+		}
+		close(c)
+		return c
+	}
+	type T struct {
+		Seq   func(func(int) bool) `json:"seq"`
+		Named intSeq               `json:"named,omitempty"`
+		Chan  <-chan string        `json:"chan"`
+	}
+	tests := []struct {
+		CaseName
+		in   any
+		want string
+	}{
+		{Name("seq"), count(3), `[0,1,2]`},
+		{Name("empty seq"), count(0), `[]`},
+		{Name("chan"), recv("a", "b"), `["a","b"]`},
+		{Name("nil"), T{}, `{"seq":null,"chan":null}`},
+		{Name("fields"), T{Seq: count(1), Named: intSeq(count(2)), Chan: recv()}, `{"seq":[0],"named":[0,1],"chan":[]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	for _, v := range []any{make(chan int), make(chan<- int), func(int) bool { return false }} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T) error = nil, want UnsupportedTypeError", v)
+		}
+	}
+}
+
+func TestEncoderSeqAndChanError(t *testing.T) {
+	big := strings.Repeat("x", streamFlushSize)
+	seq := func(yield func(any) bool) {
+		_ = yield(big) && yield(big) && yield(math.NaN())
+	}
+	ch := make(chan any, 3)
+	ch <- big
+	ch <- big
+	ch <- math.Inf(1)
+	close(ch)
+	tests := []struct {
+		CaseName
+		in any
+	}{
+		{Name("seq"), seq},
+		{Name("chan"), (<-chan any)(ch)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf)
+			var uve *UnsupportedValueError
+			if err := enc.Encode(tt.in); !errors.As(err, &uve) {
+				t.Fatalf("%s: Encode error: got %v, want *UnsupportedValueError", tt.Where, err)
+			}
+			n := buf.Len()
+			if n == 0 {
+				t.Fatalf("%s: Encode wrote nothing before the error, want the first elements streamed", tt.Where)
+			}
+			if err := enc.Encode(1); !errors.As(err, &uve) {
+				t.Errorf("%s: Encode after partial output: got %v, want the earlier error", tt.Where, err)
+			}
+			if buf.Len() != n {
+				t.Errorf("%s: Encode after partial output wrote %q", tt.Where, buf.Bytes()[n:])
+			}
+		})
+	}
+}
+
+func TestMarshalOmitUnset(t *testing.T) {
+	type Inner struct {
+		A *int `json:"a"`
+		B int  `json:"b"`
+	}
+	type Patch struct {
+		Name    *string        `json:"name"`
+		Count   int            `json:"count"`
+		Tags    []string       `json:"tags"`
+		Attrs   map[string]any `json:"attrs"`
+		Any     any            `json:"any"`
+		Inner   *Inner         `json:"inner"`
+		Cleared *string        `json:"cleared,nullable"`
+		Opt     *string        `json:"opt,optional"`
+		OptNull **string       `json:"optNull,optional,nullable"`
+	}
+	var nilString *string
+	one := 1
+
+	cases := []struct {
+		CaseName
+		in   any
+		want string
+	}{
+		{Name("zero value"), Patch{}, `{"count":0,"cleared":null}`},
+		{Name("set fields"), Patch{Tags: []string{}, Inner: &Inner{A: &one}, OptNull: &nilString},
+			`{"count":0,"tags":[],"inner":{"a":1,"b":0},"cleared":null,"optNull":null}`},
+		{Name("nested unset"), Patch{Inner: &Inner{}}, `{"count":0,"inner":{"b":0},"cleared":null}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := MarshalOptions{OmitUnset: true}.Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshalVerifyEnums(t *testing.T) {
+	type T struct {
+		S string  `json:"s,enum=a|b"`
+		P *string `json:"p,enum=a|b"`
+	}
+	// Without VerifyEnums, values are not checked.
+	if _, err := Marshal(T{S: "c"}); err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	opts := MarshalOptions{VerifyEnums: true}
+	got, err := opts.Marshal(T{S: "a"})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if want := `{"s":"a","p":null}`; string(got) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+	c := "c"
+	wantErr := `json: invalid value "c" for field "p", must be one of a|b`
+	if _, err := opts.Marshal(T{S: "b", P: &c}); err == nil || err.Error() != wantErr {
+		t.Errorf("Marshal error:\n\tgot:  %v\n\twant: %s", err, wantErr)
+	}
+}
+
 type StringTag struct {
 	BoolStr    bool    `json:",string"`
 	IntStr     int64   `json:",string"`
@@ -293,6 +548,12 @@ type PointerCycle struct {
 
 var pointerCycle = &PointerCycle{}
 
+type PointerCycleBranch struct {
+	A, B *PointerCycleBranch
+}
+
+var pointerCycleBranch = &PointerCycleBranch{}
+
 type PointerCycleIndirect struct {
 	Ptrs []any
 }
@@ -313,6 +574,7 @@ func init() {
 	samePointerNoCycle.Ptr2 = ptr
 
 	pointerCycle.Ptr = pointerCycle
+	pointerCycleBranch.A, pointerCycleBranch.B = pointerCycleBranch, pointerCycleBranch
 	pointerCycleIndirect.Ptrs = []any{pointerCycleIndirect}
 
 	mapCycle["x"] = mapCycle
@@ -345,6 +607,7 @@ func TestUnsupportedValues(t *testing.T) {
 		{Name(""), math.Inf(-1)},
 		{Name(""), math.Inf(1)},
 		{Name(""), pointerCycle},
+		{Name(""), pointerCycleBranch},
 		{Name(""), pointerCycleIndirect},
 		{Name(""), mapCycle},
 		{Name(""), sliceCycle},
@@ -1256,6 +1519,116 @@ func TestMarshalRawMessageValue(t *testing.T) {
 	}
 }
 
+func TestMarshalVerbatimRawMessages(t *testing.T) {
+	frag := RawMessage(" {\n  \"b\": [1, 2],\n  \"c\": \"<x>\"\n}\n")
+	in := struct {
+		A int
+		M RawMessage
+		L []*RawMessage
+	}{1, frag, []*RawMessage{&frag}}
+	opts := MarshalOptions{VerbatimRawMessages: true}
+
+	b, err := opts.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"A":1,"M":{
+  "b": [1, 2],
+  "c": "<x>"
+},"L":[{
+  "b": [1, 2],
+  "c": "<x>"
+}]}`
+	if got := string(b); got != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	b, err = opts.MarshalIndent(in, ">", "\t")
+	if err != nil {
+		t.Fatalf("MarshalIndent error: %v", err)
+	}
+	want = `{
+>	"A": 1,
+>	"M": {
+>	  "b": [1, 2],
+>	  "c": "<x>"
+>	},
+>	"L": [
+>		{
+>		  "b": [1, 2],
+>		  "c": "<x>"
+>		}
+>	]
+>}`
+	if got := string(b); got != want {
+		t.Errorf("MarshalIndent:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	// Without the option, the contents are reindented.
+	b, err = MarshalIndent(in, "", "  ")
+	if err != nil {
+		t.Fatalf("MarshalIndent error: %v", err)
+	}
+	want = `{
+  "A": 1,
+  "M": {
+    "b": [
+      1,
+      2
+    ],
+    "c": "\u003cx\u003e"
+  },
+  "L": [
+    {
+      "b": [
+        1,
+        2
+      ],
+      "c": "\u003cx\u003e"
+    }
+  ]
+}`
+	if got := string(b); got != want {
+		t.Errorf("MarshalIndent:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	for _, raw := range []RawMessage{{}, RawMessage(" "), RawMessage(`{"a":}`)} {
+		if _, err := opts.MarshalIndent(struct{ M RawMessage }{raw}, "", "  "); err == nil {
+			t.Errorf("MarshalIndent(%q) error: got nil, want non-nil", raw)
+		}
+	}
+}
+
+func TestMarshalEscapeRune(t *testing.T) {
+	opts := MarshalOptions{EscapeRune: func(r rune) bool { return r == '/' || r == '\u00a0' }}
+	in := struct {
+		URL string `json:"a/b"`
+		N   int
+	}{"http://x\u00a0y", 1}
+	b, err := opts.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"a\u002fb":"http:\u002f\u002fx\u00a0y","N":1}`
+	if got := string(b); got != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+	var out struct {
+		URL string `json:"a/b"`
+	}
+	if err := Unmarshal(b, &out); err != nil || out.URL != in.URL {
+		t.Errorf("Unmarshal: got %q, %v, want %q", out.URL, err, in.URL)
+	}
+
+	b, err = opts.MarshalIndent([]string{"/"}, "", " ")
+	if err != nil {
+		t.Fatalf("MarshalIndent error: %v", err)
+	}
+	if got, want := string(b), "[\n \"\\u002f\"\n]"; got != want {
+		t.Errorf("MarshalIndent:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
 type marshalPanic struct{}
 
 func (marshalPanic) MarshalJSON() ([]byte, error) { panic(0xdead) }
@@ -1339,3 +1712,124 @@ func TestIssue63379(t *testing.T) {
 		}
 	}
 }
+
+// textAppenderOnly implements only AppendText.
+type textAppenderOnly int
+
+func (t textAppenderOnly) AppendText(b []byte) ([]byte, error) {
+	return strconv.AppendInt(append(b, "a:"...), int64(t), 10), nil
+}
+
+// textAppenderAndMarshaler prefers AppendText to MarshalText.
+type textAppenderAndMarshaler struct{}
+
+func (textAppenderAndMarshaler) AppendText(b []byte) ([]byte, error) {
+	return append(b, "appended"...), nil
+}
+
+func (textAppenderAndMarshaler) MarshalText() ([]byte, error) { return []byte("marshaled"), nil }
+
+// textAppenderPtr implements AppendText with a pointer receiver.
+type textAppenderPtr struct{ s string }
+
+func (t *textAppenderPtr) AppendText(b []byte) ([]byte, error) {
+	if t.s == "" {
+		return nil, errors.New("empty")
+	}
+	return append(b, t.s...), nil
+}
+
+func TestMarshalTextAppender(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      any
+		want    string
+		wantErr string
+	}{
+		{CaseName: Name("Value"), in: textAppenderOnly(3), want: `"a:3"`},
+		{CaseName: Name("Preferred"), in: textAppenderAndMarshaler{}, want: `"appended"`},
+		{CaseName: Name("Addressable"), in: &struct{ T textAppenderPtr }{textAppenderPtr{"<x>"}}, want: `{"T":"\u003cx\u003e"}`},
+		{CaseName: Name("NotAddressable"), in: struct{ T textAppenderPtr }{textAppenderPtr{"x"}}, want: `{"T":{}}`},
+		{CaseName: Name("NilPointer"), in: struct{ T *textAppenderPtr }{}, want: `{"T":null}`},
+		{CaseName: Name("MapKeys"), in: map[textAppenderOnly]int{2: 2, 1: 1}, want: `{"a:1":1,"a:2":2}`},
+		{CaseName: Name("Slice"), in: []textAppenderOnly{1, 2}, want: `["a:1","a:2"]`},
+		{CaseName: Name("Error"), in: &textAppenderPtr{}, wantErr: "json: error calling AppendText for type *json.textAppenderPtr: empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := Marshal(tt.in)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.want)
+			}
+		})
+	}
+}
+
+// textMarshalerOnly is like textAppenderOnly but implements MarshalText.
+type textMarshalerOnly int
+
+func (t textMarshalerOnly) MarshalText() ([]byte, error) {
+	return textAppenderOnly(t).AppendText(nil)
+}
+
+func TestMarshalTextAppenderAllocs(t *testing.T) {
+	appenders := []textAppenderOnly{1, 2, 3, 4}
+	marshalers := []textMarshalerOnly{1, 2, 3, 4}
+	Marshal(appenders) // warm up the encoder cache
+	Marshal(marshalers)
+	withAppend := testing.AllocsPerRun(100, func() { Marshal(appenders) })
+	withMarshal := testing.AllocsPerRun(100, func() { Marshal(marshalers) })
+	if withAppend+float64(len(marshalers)) > withMarshal {
+		t.Errorf("Marshal of TextAppenders allocated %v times, want at most %v", withAppend, withMarshal-float64(len(marshalers)))
+	}
+}
+
+// ptrMarshaler implements Marshaler only with a pointer receiver.
+type ptrMarshaler int
+
+func (*ptrMarshaler) MarshalJSON() ([]byte, error) { return []byte(`"ptr"`), nil }
+
+func TestMarshalValue(t *testing.T) {
+	type T struct {
+		A int
+		P ptrMarshaler
+	}
+	v := T{1, 2}
+	tests := []struct {
+		CaseName
+		in   reflect.Value
+		want string
+	}{
+		{Name("value"), reflect.ValueOf(v), `{"A":1,"P":2}`},
+		{Name("addressable"), reflect.ValueOf(&v).Elem(), `{"A":1,"P":"ptr"}`},
+		{Name("field"), reflect.ValueOf(v).Field(0), `1`},
+		{Name("addressable field"), reflect.ValueOf(&v).Elem().Field(1), `"ptr"`},
+		{Name("zero"), reflect.Value{}, `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := MarshalValue(tt.in)
+			if err != nil {
+				t.Fatalf("%s: MarshalValue error: %v", tt.Where, err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("%s: MarshalValue:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.want)
+			}
+		})
+	}
+
+	field := reflect.ValueOf(v).Field(0)
+	MarshalValue(field) // warm up the encoder cache
+	if allocs := testing.AllocsPerRun(100, func() { MarshalValue(field) }); allocs > 1 {
+		t.Errorf("MarshalValue allocated %v times, want at most 1", allocs)
+	}
+}
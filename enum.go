@@ -0,0 +1,273 @@
+package json
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// An EnumPolicy selects how a type registered with [RegisterEnum] encodes
+// and decodes the values that have no name.
+type EnumPolicy int
+
+const (
+	// EnumStrict makes values without a name errors: encoding one fails
+	// with an [UnsupportedValueError], and decoding a string that is not a
+	// name, or a number, fails with a [SemanticError] wrapping
+	// [ErrInvalidEnum].
+	EnumStrict EnumPolicy = iota
+
+	// EnumNumbers encodes values without a name as JSON numbers, and
+	// decodes JSON numbers as the values they hold, for types whose
+	// values may come from newer versions of a program. Strings must
+	// still be names.
+	EnumNumbers
+)
+
+var (
+	enumRegistry    sync.Map // map[reflect.Type]*enumCodec
+	enumsRegistered atomic.Bool
+)
+
+// An enumCodec converts between the values of an enum type and their names.
+type enumCodec struct {
+	typ     reflect.Type
+	policy  EnumPolicy
+	byValue map[uint64]string // by the bits of the integer value
+	byName  map[string]uint64
+	names   []string // in order of value, for error messages and schemas
+}
+
+// RegisterEnum records names for the values of the integer type T, so
+// that they are encoded as those names, as JSON strings, and decoded from
+// them, wherever a value of type T or *T is encoded or decoded, including
+// as a map key. This takes the place of a String method and UnmarshalJSON
+// method written for each enum type, and of the MarshalJSON, MarshalText,
+// UnmarshalJSON, and UnmarshalText methods of T, if any.
+//
+//	type Color int
+//
+//	const (
+//		Red Color = iota
+//		Green
+//	)
+//
+//	func init() {
+//		json.RegisterEnum(map[Color]string{Red: "red", Green: "green"})
+//	}
+//
+// Names are matched exactly. The policy, [EnumStrict] if omitted, selects
+// how values without a name are handled.
+//
+// RegisterEnum is meant to be called from init functions, before values
+// of T are encoded or decoded. It panics if T is not an integer type, if T
+// is already registered, if two values have the same name, or if more than
+// one policy is given.
+func RegisterEnum[T comparable](names map[T]string, policy ...EnumPolicy) {
+	t := reflect.TypeFor[T]()
+	if !isIntegerKind(t.Kind()) {
+		panic(fmt.Sprintf("json: RegisterEnum of non-integer type %s", t))
+	}
+	if len(policy) > 1 {
+		panic("json: RegisterEnum with more than one policy")
+	}
+	ec := &enumCodec{
+		typ:     t,
+		byValue: make(map[uint64]string, len(names)),
+		byName:  make(map[string]uint64, len(names)),
+	}
+	if len(policy) == 1 {
+		ec.policy = policy[0]
+	}
+	values := make([]uint64, 0, len(names))
+	for v, name := range names {
+		bits := integerBits(reflect.ValueOf(v))
+		if _, dup := ec.byName[name]; dup {
+			panic(fmt.Sprintf("json: RegisterEnum of type %s with duplicate name %q", t, name))
+		}
+		ec.byValue[bits] = name
+		ec.byName[name] = bits
+		values = append(values, bits)
+	}
+	slices.SortFunc(values, func(a, b uint64) int {
+		if isSignedKind(t.Kind()) {
+			return cmp.Compare(int64(a), int64(b))
+		}
+		return cmp.Compare(a, b)
+	})
+	for _, bits := range values {
+		ec.names = append(ec.names, ec.byValue[bits])
+	}
+	if _, dup := enumRegistry.LoadOrStore(t, ec); dup {
+		panic(fmt.Sprintf("json: RegisterEnum of type %s registered twice", t))
+	}
+	enumsRegistered.Store(true)
+}
+
+// lookupEnum returns the enumCodec registered for t, or nil if there is none.
+func lookupEnum(t reflect.Type) *enumCodec {
+	if ec, ok := enumRegistry.Load(t); ok {
+		return ec.(*enumCodec)
+	}
+	return nil
+}
+
+func isIntegerKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isSignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// integerBits returns the bits of the integer held by v.
+func integerBits(v reflect.Value) uint64 {
+	if isSignedKind(v.Kind()) {
+		return uint64(v.Int())
+	}
+	return v.Uint()
+}
+
+// numberString returns the decimal form of the integer held by v.
+func numberString(v reflect.Value) string {
+	if isSignedKind(v.Kind()) {
+		return strconv.FormatInt(v.Int(), 10)
+	}
+	return strconv.FormatUint(v.Uint(), 10)
+}
+
+func (ec *enumCodec) encode(e *encodeState, v reflect.Value, opts encOpts) {
+	if name, ok := ec.byValue[integerBits(v)]; ok {
+		e.Write(appendString(e.AvailableBuffer(), name, opts.escapeHTML))
+		return
+	}
+	if ec.policy != EnumNumbers {
+		e.error(&UnsupportedValueError{v, fmt.Sprintf("value %s of enum type %s has no name", numberString(v), ec.typ)})
+	}
+	if isSignedKind(v.Kind()) {
+		intEncoder(e, v, opts)
+	} else {
+		uintEncoder(e, v, opts)
+	}
+}
+
+// keyName returns the name of the map key v, or its decimal form if it
+// has none and numbers are allowed.
+func (ec *enumCodec) keyName(v reflect.Value) (string, error) {
+	if name, ok := ec.byValue[integerBits(v)]; ok {
+		return name, nil
+	}
+	if ec.policy != EnumNumbers {
+		return "", &UnsupportedValueError{v, fmt.Sprintf("value %s of enum type %s has no name", numberString(v), ec.typ)}
+	}
+	return numberString(v), nil
+}
+
+// parse returns the value of ec.typ named by s, or, if numbers are
+// allowed, written as s in decimal.
+func (ec *enumCodec) parse(s string) (reflect.Value, bool) {
+	bits, ok := ec.byName[s]
+	v := reflect.New(ec.typ).Elem()
+	if !ok {
+		if ec.policy != EnumNumbers {
+			return v, false
+		}
+		if isSignedKind(v.Kind()) {
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v.OverflowInt(n) {
+				return v, false
+			}
+			bits = uint64(n)
+		} else {
+			n, err := strconv.ParseUint(s, 10, 64)
+			if err != nil || v.OverflowUint(n) {
+				return v, false
+			}
+			bits = n
+		}
+	}
+	if isSignedKind(v.Kind()) {
+		v.SetInt(int64(bits))
+	} else {
+		v.SetUint(bits)
+	}
+	return v, true
+}
+
+// enumStore decodes the JSON literal item, which is not null, into v,
+// whose type, after following pointers, is that of ec. With fromQuoted,
+// item is the contents of a string, as for a field with the string option.
+func (d *decodeState) enumStore(ec *enumCodec, item []byte, v reflect.Value, fromQuoted bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	off := d.literalOffset(item, fromQuoted)
+	var s string
+	switch c := item[0]; {
+	case fromQuoted:
+		s = string(item)
+	case c == '"':
+		b, _ := unquoteBytes(item)
+		if _, ok := ec.byName[string(b)]; !ok {
+			d.saveError(ec.invalid(off, item))
+			return
+		}
+		s = string(b)
+	case c == '-' || '0' <= c && c <= '9':
+		if ec.policy != EnumNumbers {
+			d.saveError(ec.invalid(off, item))
+			return
+		}
+		s = string(item)
+	default:
+		d.saveError(&UnmarshalTypeError{Value: valueKind(item), Type: v.Type(), Offset: int64(off)})
+		return
+	}
+	ev, ok := ec.parse(s)
+	if !ok {
+		d.saveError(ec.invalid(off, item))
+		return
+	}
+	v.Set(ev)
+}
+
+// keySize returns the length of the name of v, or of its decimal form.
+func (ec *enumCodec) keySize(v reflect.Value) int {
+	if name, ok := ec.byValue[integerBits(v)]; ok {
+		return len(name)
+	}
+	return len(numberString(v))
+}
+
+func (ec *enumCodec) invalid(off int, item []byte) error {
+	return newSemanticError(off, ec.typ, ErrInvalidEnum, "json: invalid value %s for enum type %s, must be one of %s", item, ec.typ, strings.Join(ec.names, "|"))
+}
+
+// schema returns the schema of the encodings of ec.typ.
+func (ec *enumCodec) schema() *Schema {
+	if ec.policy == EnumNumbers {
+		return &Schema{Type: SchemaTypes{"string", "integer"}}
+	}
+	s := &Schema{Type: SchemaTypes{"string"}}
+	for _, name := range ec.names {
+		s.Enum = append(s.Enum, name)
+	}
+	return s
+}
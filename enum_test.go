@@ -0,0 +1,170 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type enumColor int
+
+const (
+	enumRed enumColor = iota
+	enumGreen
+	enumBlue
+)
+
+// MarshalText is not used: the registered names take its place, for values,
+// pointers, and map keys alike.
+func (c enumColor) MarshalText() ([]byte, error) { return []byte("text"), nil }
+
+type enumLevel uint8
+
+const (
+	enumLow  enumLevel = 1
+	enumHigh enumLevel = 200
+)
+
+func init() {
+	RegisterEnum(map[enumColor]string{enumRed: "red", enumGreen: "green", enumBlue: "<blue>"})
+	RegisterEnum(map[enumLevel]string{enumLow: "low", enumHigh: "high"}, EnumNumbers)
+}
+
+type enumHolder struct {
+	Color  enumColor              `json:"color"`
+	Ptr    *enumColor             `json:"ptr"`
+	Quoted enumLevel              `json:"quoted,string"`
+	Levels map[enumLevel]bool     `json:"levels,omitempty"`
+	Counts map[enumColor]int      `json:"counts,omitempty"`
+	Any    any                    `json:"any,omitempty"`
+	Nested []map[string]enumColor `json:"nested,omitempty"`
+}
+
+func TestEnumRoundTrip(t *testing.T) {
+	green := enumGreen
+	tests := []struct {
+		CaseName
+		in   enumHolder
+		want string
+	}{{
+		CaseName: Name("names"),
+		in:       enumHolder{Color: enumBlue, Ptr: &green, Quoted: enumHigh, Counts: map[enumColor]int{enumRed: 1, enumBlue: 2}},
+		want:     `{"color":"\u003cblue\u003e","ptr":"green","quoted":"high","counts":{"\u003cblue\u003e":2,"red":1}}`,
+	}, {
+		CaseName: Name("numbers"),
+		in:       enumHolder{Quoted: 7, Levels: map[enumLevel]bool{3: true, enumLow: false}},
+		want:     `{"color":"red","ptr":null,"quoted":"7","levels":{"3":true,"low":false}}`,
+	}, {
+		CaseName: Name("nested"),
+		in:       enumHolder{Quoted: enumLow, Nested: []map[string]enumColor{{"a": enumGreen}}},
+		want:     `{"color":"red","ptr":null,"quoted":"low","nested":[{"a":"green"}]}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(b) != tt.want {
+				t.Fatalf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.want)
+			}
+			var got enumHolder
+			if err := Unmarshal(b, &got); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if !reflect.DeepEqual(got, tt.in) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %#v\n\twant: %#v", tt.Where, got, tt.in)
+			}
+		})
+	}
+
+	// Numbers are accepted with EnumNumbers.
+	var level enumLevel
+	if err := Unmarshal([]byte(`42`), &level); err != nil || level != 42 {
+		t.Errorf("Unmarshal(42) = %v, %v, want 42, nil", level, err)
+	}
+	if size, want := EstimateSize(enumHolder{Color: enumGreen}), len(`{"color":"green","ptr":null,"quoted":""}`); size < want {
+		t.Errorf("EstimateSize = %d, want at least %d", size, want)
+	}
+}
+
+func TestEnumErrors(t *testing.T) {
+	for _, in := range []any{enumColor(9), map[enumColor]int{9: 1}} {
+		_, err := Marshal(in)
+		if err == nil || !strings.Contains(err.Error(), "value 9 of enum type json.enumColor has no name") {
+			t.Errorf("Marshal(%v) error: got %v, want no name error", in, err)
+		}
+	}
+
+	tests := []struct {
+		CaseName
+		in   string
+		ptr  any
+		want string
+	}{
+		{Name("unknown name"), `"purple"`, new(enumColor), `json: invalid value "purple" for enum type json.enumColor, must be one of red|green|<blue>`},
+		{Name("number"), `1`, new(enumColor), `json: invalid value 1 for enum type json.enumColor, must be one of red|green|<blue>`},
+		{Name("overflow"), `300`, new(enumLevel), `json: invalid value 300 for enum type json.enumLevel, must be one of low|high`},
+		{Name("numeric string"), `"3"`, new(enumLevel), `json: invalid value "3" for enum type json.enumLevel, must be one of low|high`},
+		{Name("bool"), `true`, new(enumColor), `json: cannot unmarshal bool into Go value of type json.enumColor`},
+		{Name("map key"), `{"purple":1}`, new(map[enumColor]int), `json: invalid value "purple" for enum type json.enumColor, must be one of red|green|<blue>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.in), tt.ptr)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("%s: Unmarshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.want)
+			}
+			if strings.Contains(tt.want, "invalid value") && !errors.Is(err, ErrInvalidEnum) {
+				t.Errorf("%s: Unmarshal error does not wrap ErrInvalidEnum", tt.Where)
+			}
+		})
+	}
+
+	// null leaves the value alone.
+	c := enumBlue
+	if err := Unmarshal([]byte(`null`), &c); err != nil || c != enumBlue {
+		t.Errorf("Unmarshal(null) = %v, %v, want <blue>, nil", c, err)
+	}
+}
+
+func TestEnumSchema(t *testing.T) {
+	s, err := SchemaOf(struct {
+		C enumColor
+		L *enumLevel
+		P *enumColor
+	}{})
+	if err != nil {
+		t.Fatalf("SchemaOf error: %v", err)
+	}
+	got, _ := Marshal(s.Properties)
+	want := `{"C":{"type":"string","enum":["red","green","\u003cblue\u003e"]},"L":{"type":["string","integer","null"]},"P":{"type":["string","null"],"enum":["red","green","\u003cblue\u003e",null]}}`
+	if string(got) != want {
+		t.Errorf("schema:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestRegisterEnumPanics(t *testing.T) {
+	type dup int
+	type str string
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{"non-integer", func() { RegisterEnum(map[str]string{"a": "a"}) }},
+		{"duplicate name", func() { RegisterEnum(map[dup]string{1: "a", 2: "a"}) }},
+		{"twice", func() { RegisterEnum(map[enumColor]string{}) }},
+		{"two policies", func() { RegisterEnum(map[dup]string{}, EnumStrict, EnumNumbers) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterEnum did not panic")
+				}
+			}()
+			tt.register()
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Sentinel errors wrapped by a [SemanticError], for use with [errors.Is].
+var (
+	// ErrUnknownField is reported for an object member that matches no
+	// struct field when unknown fields are disallowed.
+	ErrUnknownField = errors.New("json: unknown field")
+
+	// ErrDuplicateKey is reported for an object member whose key repeats
+	// an earlier one when duplicate keys are an error.
+	ErrDuplicateKey = errors.New("json: duplicate key")
+
+	// ErrMissingField is reported for an object missing the members of
+	// nullable struct fields that are not optional.
+	ErrMissingField = errors.New("json: missing field")
+
+	// ErrInvalidEnum is reported for a value of a struct field with an enum
+	// tag option that is not one of the allowed values.
+	ErrInvalidEnum = errors.New("json: value not in enum")
+
+	// ErrStringOption is reported for a value that does not suit a struct
+	// field with the string tag option, such as an unquoted value.
+	ErrStringOption = errors.New("json: invalid value for string option")
+
+	// ErrUnregisteredType is reported for a type name or a Go type that
+	// was not registered with [RegisterType].
+	ErrUnregisteredType = errors.New("json: type not registered")
+)
+
+// A SemanticError describes well-formed JSON that cannot be decoded into a
+// Go value as requested, or a Go value that cannot be encoded, for reasons
+// other than a mismatch of types, which is reported as an
+// [UnmarshalTypeError]. Err holds the cause, which may be one of the
+// sentinel errors of this package, such as [ErrUnknownField].
+type SemanticError struct {
+	Path   string       // JSON Pointer (RFC 6901) of the value in the input; empty when encoding
+	Type   reflect.Type // Go type of the value or of its struct, if known
+	Offset int64        // offset in the input of the value or of its object key
+	Err    error        // cause of the error
+
+	msg string
+}
+
+// newSemanticError returns a SemanticError for the value at offset off in
+// the input, with a message formatted as by fmt.Sprintf.
+func newSemanticError(off int, t reflect.Type, err error, format string, args ...any) *SemanticError {
+	return &SemanticError{Type: t, Offset: int64(off), Err: err, msg: fmt.Sprintf(format, args...)}
+}
+
+func (e *SemanticError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	s := "json: "
+	if e.Type != nil {
+		s += "Go value of type " + e.Type.String() + ": "
+	}
+	if e.Err != nil {
+		s += strings.TrimPrefix(e.Err.Error(), "json: ")
+	}
+	return s
+}
+
+func (e *SemanticError) Unwrap() error {
+	return e.Err
+}
+
+// pointerAt returns the JSON Pointer of the innermost value in the valid
+// JSON text data that includes the offset off, where an object member's key
+// counts as part of its value.
+func pointerAt(data []byte, off int) string {
+	return pointersAt(data, []int{off})[0]
+}
+
+// pointersAt is like pointerAt for each of the offsets offs, which must be
+// in increasing order.
+func pointersAt(data []byte, offs []int) []string {
+	paths := make([]string, len(offs))
+	walkPointers(data, skipSpace(data, 0), nil, offs, paths)
+	return paths
+}
+
+// walkPointers sets paths[k] to the JSON Pointer of the innermost value
+// within the value at data[i], whose path is path, that includes offs[k].
+func walkPointers(data []byte, i int, path []any, offs []int, paths []string) {
+	p := pathPointer(path)
+	for k := range paths {
+		paths[k] = p
+	}
+	if i >= len(data) || data[i] != '{' && data[i] != '[' {
+		return
+	}
+	entries, _, err := containerEntries(data, i)
+	if err != nil {
+		return
+	}
+	k := 0
+	for n, e := range entries {
+		for k < len(offs) && offs[k] < e.start {
+			k++
+		}
+		j := k
+		for j < len(offs) && offs[j] < e.end {
+			j++
+		}
+		if j > k {
+			var elem any = n
+			if data[i] == '{' {
+				elem = e.key
+			}
+			walkPointers(data, e.value, append(path, elem), offs[k:j], paths[k:j])
+		}
+		k = j
+	}
+}
@@ -0,0 +1,160 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type semanticEnum struct {
+	Status string `json:"status,enum=on|off"`
+}
+
+func TestSemanticError(t *testing.T) {
+	type (
+		Inner struct {
+			N int
+		}
+		Outer struct {
+			Items []Inner          `json:"items"`
+			M     map[string]Inner `json:"m"`
+		}
+		Nullable struct {
+			A *int `json:"a,nullable"`
+		}
+		Quoted struct {
+			N int `json:"n,string"`
+		}
+		Formatted struct {
+			T time.Time `json:"t,format:date"`
+		}
+	)
+	tests := []struct {
+		CaseName
+		in       string
+		opts     UnmarshalOptions
+		ptr      any
+		wantErr  error
+		wantPath string
+		wantOff  int64
+		wantType reflect.Type
+	}{{
+		CaseName: Name("unknown field"),
+		in:       `{"items": [{"N": 1}, {"N": 2, "x/y": 3}]}`,
+		opts:     UnmarshalOptions{DisallowUnknownFields: true},
+		ptr:      new(Outer),
+		wantErr:  ErrUnknownField,
+		wantPath: "/items/1/x~1y",
+		wantOff:  30,
+		wantType: reflect.TypeFor[Inner](),
+	}, {
+		CaseName: Name("duplicate key"),
+		in:       `{"m": {"a": {}, "a": {}}}`,
+		opts:     UnmarshalOptions{DuplicateKeys: DuplicateKeysError},
+		ptr:      new(Outer),
+		wantErr:  ErrDuplicateKey,
+		wantPath: "/m/a",
+		wantOff:  16,
+	}, {
+		CaseName: Name("duplicate key in interface"),
+		in:       `[{"a": 1, "a": 2}]`,
+		opts:     UnmarshalOptions{DuplicateKeys: DuplicateKeysError},
+		ptr:      new(any),
+		wantErr:  ErrDuplicateKey,
+		wantPath: "/0/a",
+		wantOff:  10,
+	}, {
+		CaseName: Name("missing nullable field"),
+		in:       `[{}]`,
+		ptr:      new([]Nullable),
+		wantErr:  ErrMissingField,
+		wantPath: "/0",
+		wantOff:  1,
+		wantType: reflect.TypeFor[Nullable](),
+	}, {
+		CaseName: Name("enum"),
+		in:       `{"status": "maybe"}`,
+		ptr:      new(semanticEnum),
+		wantErr:  ErrInvalidEnum,
+		wantPath: "/status",
+		wantOff:  11,
+		wantType: reflect.TypeFor[string](),
+	}, {
+		CaseName: Name("string option"),
+		in:       `{"n": 1}`,
+		ptr:      new(Quoted),
+		wantErr:  ErrStringOption,
+		wantPath: "/n",
+		wantOff:  6,
+		wantType: reflect.TypeFor[int](),
+	}, {
+		CaseName: Name("format"),
+		in:       `{"t": "2001-02-30"}`,
+		ptr:      new(Formatted),
+		wantPath: "/t",
+		wantOff:  6,
+		wantType: reflect.TypeFor[time.Time](),
+	}, {
+		CaseName: Name("unregistered type"),
+		in:       `{"a": {"$type": "nope", "value": 1}}`,
+		opts:     UnmarshalOptions{TypedInterfaces: true},
+		ptr:      new(map[string]any),
+		wantErr:  ErrUnregisteredType,
+		wantPath: "/a",
+		wantOff:  6,
+		wantType: reflect.TypeFor[any](),
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.opts.Unmarshal([]byte(tt.in), tt.ptr)
+			var serr *SemanticError
+			if !errors.As(err, &serr) {
+				t.Fatalf("%s: Unmarshal error: got %v, want a *SemanticError", tt.Where, err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: errors.Is(%v, %v) = false, want true", tt.Where, err, tt.wantErr)
+			}
+			if serr.Path != tt.wantPath || serr.Offset != tt.wantOff || serr.Type != tt.wantType {
+				t.Errorf("%s: SemanticError:\n\tgot:  Path=%q Offset=%d Type=%v\n\twant: Path=%q Offset=%d Type=%v",
+					tt.Where, serr.Path, serr.Offset, serr.Type, tt.wantPath, tt.wantOff, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestSemanticErrorMarshal(t *testing.T) {
+	_, err := MarshalOptions{VerifyEnums: true}.Marshal(semanticEnum{"maybe"})
+	if !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("Marshal error: got %v, want %v", err, ErrInvalidEnum)
+	}
+}
+
+func TestSemanticErrorMessage(t *testing.T) {
+	err := &SemanticError{Type: reflect.TypeFor[int](), Err: ErrUnknownField}
+	if got, want := err.Error(), "json: Go value of type int: unknown field"; got != want {
+		t.Errorf("Error:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestPointerAt(t *testing.T) {
+	data := []byte(` {"a": [1, {"b": 2}], "c~": 3} `)
+	tests := []struct {
+		off  int
+		want string
+	}{
+		{0, ""},
+		{1, ""},
+		{2, "/a"},
+		{8, "/a/0"},
+		{9, "/a"},
+		{17, "/a/1/b"},
+		{23, "/c~0"},
+		{30, ""},
+	}
+	for _, tt := range tests {
+		if got := pointerAt(data, tt.off); got != tt.want {
+			t.Errorf("pointerAt(%d) = %q, want %q", tt.off, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package json
+
+import (
+	"strings"
+)
+
+// A FieldMask is a set of field paths, such as "user.name" or "address.city",
+// that restricts which object members are encoded or decoded.
+// Each path is a dot-separated sequence of JSON object keys (struct field
+// names as they appear in JSON, or map keys). A path selects the member it
+// names and everything below it; members not selected by any path are
+// omitted when encoding and ignored when decoding.
+//
+// Paths pass through arrays unchanged, so "items.id" selects the "id" member
+// of every element of the "items" array. Values encoded or decoded by a
+// [Marshaler], [Unmarshaler], or their text equivalents are not masked.
+//
+// An empty FieldMask selects everything.
+type FieldMask []string
+
+// ParseFieldMask parses a comma-separated list of field paths,
+// such as "user.name,address.city". Surrounding spaces are ignored.
+func ParseFieldMask(s string) FieldMask {
+	var m FieldMask
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			m = append(m, p)
+		}
+	}
+	return m
+}
+
+// String returns the comma-separated form of m.
+func (m FieldMask) String() string {
+	return strings.Join(m, ",")
+}
+
+// A maskTree is the parsed form of a FieldMask, keyed by object key.
+// A nil subtree selects everything below its key,
+// and a nil maskTree as a whole applies no restriction.
+type maskTree map[string]maskTree
+
+func (m FieldMask) tree() maskTree {
+	if len(m) == 0 {
+		return nil
+	}
+	root := maskTree{}
+	for _, p := range m {
+		node := root
+		keys := strings.Split(p, ".")
+		for i, k := range keys {
+			sub, ok := node[k]
+			if ok && sub == nil {
+				break // an enclosing path already selects everything
+			}
+			if i == len(keys)-1 {
+				node[k] = nil
+				break
+			}
+			if sub == nil {
+				sub = maskTree{}
+				node[k] = sub
+			}
+			node = sub
+		}
+	}
+	return root
+}
+
+// selects reports whether the mask selects the member with the given key,
+// and returns the mask to apply to the member's value.
+func (t maskTree) selects(key string) (maskTree, bool) {
+	if t == nil {
+		return nil, true
+	}
+	sub, ok := t[key]
+	return sub, ok
+}
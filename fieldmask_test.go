@@ -0,0 +1,107 @@
+package json
+
+import (
+	"reflect"
+	"testing"
+)
+
+type maskAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street"`
+}
+
+type maskUser struct {
+	Name    string         `json:"name"`
+	Email   string         `json:"email"`
+	Address maskAddress    `json:"address"`
+	Items   []maskAddress  `json:"items"`
+	Attrs   map[string]int `json:"attrs"`
+	Note    *string        `json:"note,nullable"`
+}
+
+func TestParseFieldMask(t *testing.T) {
+	m := ParseFieldMask(" name, address.city ,,")
+	if want := (FieldMask{"name", "address.city"}); !reflect.DeepEqual(m, want) {
+		t.Errorf("ParseFieldMask = %#v, want %#v", m, want)
+	}
+	if got := m.String(); got != "name,address.city" {
+		t.Errorf("String = %q, want %q", got, "name,address.city")
+	}
+}
+
+func TestMarshalMask(t *testing.T) {
+	u := maskUser{
+		Name:    "n",
+		Email:   "e",
+		Address: maskAddress{City: "c", Street: "s"},
+		Items:   []maskAddress{{City: "c1", Street: "s1"}},
+		Attrs:   map[string]int{"a": 1, "b": 2},
+	}
+	tests := []struct {
+		CaseName
+		mask string
+		want string
+	}{
+		{Name("empty"), "", `{"name":"n","email":"e","address":{"city":"c","street":"s"},"items":[{"city":"c1","street":"s1"}],"attrs":{"a":1,"b":2},"note":null}`},
+		{Name("top level"), "name,note", `{"name":"n","note":null}`},
+		{Name("nested"), "address.city", `{"address":{"city":"c"}}`},
+		{Name("enclosing path wins"), "address.city,address", `{"address":{"city":"c","street":"s"}}`},
+		{Name("through arrays"), "items.street", `{"items":[{"street":"s1"}]}`},
+		{Name("map keys"), "attrs.b,missing", `{"attrs":{"b":2}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := MarshalOptions{Mask: ParseFieldMask(tt.mask)}.Marshal(u)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMask(t *testing.T) {
+	in := `{"name":"n","email":"e","address":{"city":"c","street":"s"},"items":[{"city":"c1","street":"s1"}],"attrs":{"a":1,"b":2},"note":null}`
+	opts := UnmarshalOptions{Mask: ParseFieldMask("name,address.city,items.street,attrs.a")}
+
+	var u maskUser
+	if err := opts.Unmarshal([]byte(in), &u); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := maskUser{
+		Name:    "n",
+		Address: maskAddress{City: "c"},
+		Items:   []maskAddress{{Street: "s1"}},
+		Attrs:   map[string]int{"a": 1},
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", u, want)
+	}
+
+	var x any
+	if err := opts.Unmarshal([]byte(in), &x); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	wantX := map[string]any{
+		"name":    "n",
+		"address": map[string]any{"city": "c"},
+		"items":   []any{map[string]any{"street": "s1"}},
+		"attrs":   map[string]any{"a": float64(1)},
+	}
+	if !reflect.DeepEqual(x, wantX) {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", x, wantX)
+	}
+
+	// Masked-out members are not unknown fields, and masked-out
+	// non-optional nullable fields are not required.
+	opts.DisallowUnknownFields = true
+	u = maskUser{}
+	if err := opts.Unmarshal([]byte(`{"name":"n","email":"e"}`), &u); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if err := opts.Unmarshal([]byte(`{"bogus":1}`), &u); err == nil {
+		t.Error("Unmarshal with unknown field error = nil, want error")
+	}
+}
@@ -0,0 +1,139 @@
+package json
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// A pathFilter is the state of the Include and Exclude patterns of
+// MarshalOptions at a value being encoded: the patterns left to match
+// against the keys of its members. A nil *pathFilter encodes everything.
+type pathFilter struct {
+	include    [][]string
+	includeAll bool // whether an include pattern matched an enclosing member
+	exclude    [][]string
+}
+
+// newPathFilter returns the filter for the patterns of MarshalOptions.
+func newPathFilter(include, exclude []string) *pathFilter {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	f := &pathFilter{includeAll: len(include) == 0}
+	for _, p := range include {
+		f.include = append(f.include, splitPattern(p))
+	}
+	for _, p := range exclude {
+		f.exclude = append(f.exclude, splitPattern(p))
+	}
+	return f
+}
+
+// selects reports whether the filter keeps the member with the given key,
+// and returns the filter to apply to the member's value.
+func (f *pathFilter) selects(key string) (*pathFilter, bool) {
+	if f == nil {
+		return nil, true
+	}
+	exclude, excluded := advancePatterns(f.exclude, key)
+	if excluded {
+		return nil, false
+	}
+	sub := &pathFilter{includeAll: f.includeAll, exclude: exclude}
+	if !f.includeAll {
+		include, all := advancePatterns(f.include, key)
+		if !all && len(include) == 0 {
+			return nil, false
+		}
+		if all {
+			sub.includeAll = true
+		} else {
+			sub.include = include
+		}
+	}
+	if sub.includeAll && len(sub.exclude) == 0 {
+		return nil, true
+	}
+	return sub, true
+}
+
+// advancePatterns matches the first segment of each pattern against key.
+// It returns the rest of the patterns that match, to apply to the value
+// of the member, and whether a pattern matches the member as a whole.
+func advancePatterns(patterns [][]string, key string) (rest [][]string, whole bool) {
+	for _, p := range patterns {
+		if p[0] == "**" {
+			if len(p) == 1 {
+				return nil, true
+			}
+			rest = append(rest, p) // ** may match more members below
+			p = p[1:]
+		}
+		if !matchGlob(p[0], key) {
+			continue
+		}
+		if len(p) == 1 {
+			return nil, true
+		}
+		rest = append(rest, p[1:])
+	}
+	return rest, false
+}
+
+// splitPattern splits a pattern into its segments at the dots that are
+// not escaped by a backslash.
+func splitPattern(p string) []string {
+	var segs []string
+	start := 0
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '\\':
+			i++
+		case '.':
+			segs = append(segs, p[start:i])
+			start = i + 1
+		}
+	}
+	return append(segs, p[start:])
+}
+
+// matchGlob reports whether key matches the segment pattern, in which
+// '*' matches any sequence of characters, '?' matches one character, and
+// '\\' makes the character after it match itself.
+func matchGlob(pattern, key string) bool {
+	// Backtrack to the most recent '*' on a mismatch, as each '*' after it
+	// can only match later in key once the earlier one can.
+	star, next := -1, 0
+	p, k := 0, 0
+	for k < len(key) {
+		if p < len(pattern) {
+			switch c := pattern[p]; c {
+			case '*':
+				star, next = p, k
+				p++
+				continue
+			case '?':
+				_, size := utf8.DecodeRuneInString(key[k:])
+				p, k = p+1, k+size
+				continue
+			case '\\':
+				if p+1 < len(pattern) {
+					p++
+				}
+				fallthrough
+			default:
+				if pattern[p] == key[k] {
+					p, k = p+1, k+1
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		_, size := utf8.DecodeRuneInString(key[next:])
+		next += size
+		p, k = star+1, next
+	}
+	return strings.Trim(pattern[p:], "*") == ""
+}
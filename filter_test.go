@@ -0,0 +1,106 @@
+package json
+
+import "testing"
+
+func TestMarshalIncludeExclude(t *testing.T) {
+	type (
+		Secrets struct {
+			Token string `json:"token"`
+			Key   string `json:"key"`
+		}
+		User struct {
+			Name     string            `json:"name"`
+			Password string            `json:"password"`
+			Secrets  Secrets           `json:"secrets"`
+			Labels   map[string]string `json:"labels"`
+		}
+		Response struct {
+			User    User   `json:"user"`
+			Friends []User `json:"friends"`
+			Note    string `json:"a.b"`
+		}
+	)
+	v := Response{
+		User: User{
+			Name:     "n",
+			Password: "p",
+			Secrets:  Secrets{"t", "k"},
+			Labels:   map[string]string{"env": "prod", "team": "x", "temp": "y"},
+		},
+		Friends: []User{{Name: "f", Password: "fp"}},
+		Note:    "dotted",
+	}
+	tests := []struct {
+		CaseName
+		include, exclude []string
+		want             string
+	}{{
+		CaseName: Name("none"),
+		want:     `{"user":{"name":"n","password":"p","secrets":{"token":"t","key":"k"},"labels":{"env":"prod","team":"x","temp":"y"}},"friends":[{"name":"f","password":"fp","secrets":{"token":"","key":""},"labels":null}],"a.b":"dotted"}`,
+	}, {
+		CaseName: Name("include nested"),
+		include:  []string{"user.name", "user.secrets.key"},
+		want:     `{"user":{"name":"n","secrets":{"key":"k"}}}`,
+	}, {
+		CaseName: Name("exclude glob"),
+		exclude:  []string{"user.secrets.*", "friends", "a.b"}, // "a.b" is a nested path
+		want:     `{"user":{"name":"n","password":"p","secrets":{},"labels":{"env":"prod","team":"x","temp":"y"}},"a.b":"dotted"}`,
+	}, {
+		CaseName: Name("exclude at any depth"),
+		include:  []string{"user", "friends.name", "friends.password"},
+		exclude:  []string{"**.password", "user.labels.te?m"},
+		want:     `{"user":{"name":"n","secrets":{"token":"t","key":"k"},"labels":{"env":"prod","temp":"y"}},"friends":[{"name":"f"}]}`,
+	}, {
+		CaseName: Name("include glob"),
+		include:  []string{"*.labels.t*", `a\.b`},
+		want:     `{"user":{"labels":{"team":"x","temp":"y"}},"friends":[{"labels":null}],"a.b":"dotted"}`,
+	}, {
+		CaseName: Name("exclude wins"),
+		include:  []string{"user.secrets"},
+		exclude:  []string{"user.secrets"},
+		want:     `{"user":{}}`,
+	}, {
+		CaseName: Name("exclude everything"),
+		exclude:  []string{"**"},
+		want:     `{}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := MarshalOptions{Include: tt.include, Exclude: tt.exclude}.Marshal(v)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, key string
+		want         bool
+	}{
+		{"", "", true},
+		{"a", "a", true},
+		{"a", "ab", false},
+		{"*", "", true},
+		{"*", "a/b.c", true},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{"?", "é", true},
+		{"??", "é", false},
+		{`\*`, "*", true},
+		{`\*`, "a", false},
+		{`a\`, `a\`, true},
+		{"**", "anything", true},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package json
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// A fieldFormat encodes and decodes the values of a struct field tagged
+// with a format option. Its functions operate on values of the field's type
+// after following pointers; null and nil pointers are handled by the caller.
+type fieldFormat struct {
+	name   string
+	encode encoderFunc
+	// decode decodes the JSON value data, which is not null, into v,
+	// consulting ctx, which may be nil.
+	decode func(data []byte, v reflect.Value, ctx *ContextValues) error
+	schema Schema
+	typ    reflect.Type // type of the values of a codec
+}
+
+var (
+	urlType      = reflect.TypeFor[url.URL]()
+	addrType     = reflect.TypeFor[netip.Addr]()
+	prefixType   = reflect.TypeFor[netip.Prefix]()
+	addrPortType = reflect.TypeFor[netip.AddrPort]()
+	bigIntType   = reflect.TypeFor[big.Int]()
+)
+
+// timeLayouts are the named layouts of the time.Time formats.
+// Others cannot be written in a struct tag, as they contain commas.
+var timeLayouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"date":        time.DateOnly,
+	"time":        time.TimeOnly,
+	"datetime":    time.DateTime,
+	"kitchen":     time.Kitchen,
+}
+
+// lookupFormat returns the format with the given name for fields of type t,
+// or nil if there is none.
+func lookupFormat(t reflect.Type, name string) *fieldFormat {
+	base := t
+	for base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	var f *fieldFormat
+	switch base {
+	case timeType:
+		f = timeFormat(name)
+	case urlType:
+		if name == "url" {
+			f = urlFormat()
+		}
+	case addrType, prefixType, addrPortType:
+		f = netipFormat(base, name)
+	case bigIntType:
+		f = bigIntFormat(name)
+	}
+	if f != nil {
+		f.name = name
+	}
+	return f
+}
+
+// formatEncoder returns an encoder of fields with format f,
+// following pointers.
+func formatEncoder(f *fieldFormat) encoderFunc {
+	return func(e *encodeState, v reflect.Value, opts encOpts) {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				e.WriteString("null")
+				return
+			}
+			v = v.Elem()
+		}
+		f.encode(e, v, opts)
+	}
+}
+
+// decodeFormat decodes the JSON value data into v, which has format f,
+// allocating pointers as needed. data is not null.
+func (d *decodeState) decodeFormat(f *fieldFormat, data []byte, v reflect.Value) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if err := f.decode(data, v, d.ctx); err != nil {
+		switch err := err.(type) {
+		case *UnmarshalTypeError:
+			err.Offset = int64(d.readIndex() - len(data))
+		case *SemanticError:
+			err.Offset = int64(d.readIndex() - len(data))
+		}
+		d.saveError(err)
+	}
+}
+
+// rawValue consumes the JSON value whose first byte has been read and
+// returns its encoding, reading the following byte ahead.
+func (d *decodeState) rawValue() []byte {
+	start := d.readIndex()
+	if d.opcode == scanBeginLiteral {
+		d.rescanLiteral()
+	} else {
+		d.skip()
+		d.scanNext()
+	}
+	return d.data[start:d.readIndex()]
+}
+
+// formatString returns the contents of the JSON string data, or an
+// UnmarshalTypeError if data is some other kind of value.
+func formatString(data []byte, t reflect.Type) (string, error) {
+	s, ok := unquote(data)
+	if !ok {
+		return "", &UnmarshalTypeError{Value: valueKind(data), Type: t}
+	}
+	return s, nil
+}
+
+// valueKind describes the kind of the JSON value data as in an UnmarshalTypeError.
+func valueKind(data []byte) string {
+	switch data[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
+
+func invalidFormatValue(data []byte, t reflect.Type, format string, err error) error {
+	return newSemanticError(0, t, err, "json: cannot unmarshal %s into Go value of type %s with format %s: %v", data, t, format, err)
+}
+
+func timeFormat(name string) *fieldFormat {
+	var scale time.Duration
+	switch name {
+	case "unix":
+		scale = time.Second
+	case "unixmilli":
+		scale = time.Millisecond
+	case "unixmicro":
+		scale = time.Microsecond
+	case "unixnano":
+		scale = time.Nanosecond
+	}
+	if name == "ttl" {
+		return ttlFormat()
+	}
+	if scale != 0 {
+		return &fieldFormat{
+			encode: func(e *encodeState, v reflect.Value, _ encOpts) {
+				t := v.Interface().(time.Time)
+				n := t.Unix()*int64(time.Second/scale) + int64(t.Nanosecond())/int64(scale)
+				e.Write(strconv.AppendInt(e.AvailableBuffer(), n, 10))
+			},
+			decode: func(data []byte, v reflect.Value, _ *ContextValues) error {
+				if c := data[0]; c != '-' && (c < '0' || c > '9') {
+					return &UnmarshalTypeError{Value: valueKind(data), Type: v.Type()}
+				}
+				n, err := strconv.ParseInt(string(data), 10, 64)
+				if err != nil {
+					return invalidFormatValue(data, v.Type(), name, err)
+				}
+				per := int64(time.Second / scale)
+				sec, frac := n/per, n%per
+				v.Set(reflect.ValueOf(time.Unix(sec, frac*int64(scale))))
+				return nil
+			},
+			schema: Schema{Type: SchemaTypes{"integer"}},
+		}
+	}
+
+	layout, ok := timeLayouts[name]
+	if !ok {
+		// Accept a layout if it has at least one element.
+		if time.Date(2001, 11, 23, 22, 33, 44, 0, time.UTC).Format(name) == name {
+			return nil
+		}
+		layout = name
+	}
+	s := Schema{Type: SchemaTypes{"string"}}
+	switch name {
+	case "rfc3339", "rfc3339nano":
+		s.Format = "date-time"
+	case "date":
+		s.Format = "date"
+	}
+	return &fieldFormat{
+		encode: func(e *encodeState, v reflect.Value, opts encOpts) {
+			b := e.AvailableBuffer()
+			b = append(b, '"')
+			b = opts.ctx.in(v.Interface().(time.Time)).AppendFormat(b, layout)
+			e.Write(append(b, '"'))
+		},
+		decode: func(data []byte, v reflect.Value, ctx *ContextValues) error {
+			s, err := formatString(data, v.Type())
+			if err != nil {
+				return err
+			}
+			var t time.Time
+			if ctx != nil && ctx.Location != nil {
+				t, err = time.ParseInLocation(layout, s, ctx.Location)
+			} else {
+				t, err = time.Parse(layout, s)
+			}
+			if err != nil {
+				return invalidFormatValue(data, v.Type(), name, err)
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		},
+		schema: s,
+	}
+}
+
+// ttlFormat returns the format of times as the number of seconds from the
+// current time, rounded down.
+func ttlFormat() *fieldFormat {
+	return &fieldFormat{
+		encode: func(e *encodeState, v reflect.Value, opts encOpts) {
+			d := v.Interface().(time.Time).Sub(opts.ctx.now())
+			e.Write(strconv.AppendInt(e.AvailableBuffer(), int64(d/time.Second), 10))
+		},
+		decode: func(data []byte, v reflect.Value, ctx *ContextValues) error {
+			if c := data[0]; c != '-' && (c < '0' || c > '9') {
+				return &UnmarshalTypeError{Value: valueKind(data), Type: v.Type()}
+			}
+			n, err := strconv.ParseInt(string(data), 10, 64)
+			if err == nil && (n > math.MaxInt64/int64(time.Second) || n < math.MinInt64/int64(time.Second)) {
+				err = strconv.ErrRange
+			}
+			if err != nil {
+				return invalidFormatValue(data, v.Type(), "ttl", err)
+			}
+			v.Set(reflect.ValueOf(ctx.now().Add(time.Duration(n) * time.Second)))
+			return nil
+		},
+		schema: Schema{Type: SchemaTypes{"integer"}},
+	}
+}
+
+func urlFormat() *fieldFormat {
+	return &fieldFormat{
+		encode: func(e *encodeState, v reflect.Value, opts encOpts) {
+			u := v.Interface().(url.URL)
+			e.Write(appendString(e.AvailableBuffer(), u.String(), opts.escapeHTML))
+		},
+		decode: func(data []byte, v reflect.Value, _ *ContextValues) error {
+			s, err := formatString(data, v.Type())
+			if err != nil {
+				return err
+			}
+			u, err := url.Parse(s)
+			if err != nil {
+				return invalidFormatValue(data, v.Type(), "url", err)
+			}
+			v.Set(reflect.ValueOf(*u))
+			return nil
+		},
+		schema: Schema{Type: SchemaTypes{"string"}, Format: "uri"},
+	}
+}
+
+// netipFormat returns the format of netip.Addr, netip.Prefix, or
+// netip.AddrPort values, encoded as by their MarshalText methods.
+// The ipv4 and ipv6 formats restrict the address family.
+func netipFormat(t reflect.Type, name string) *fieldFormat {
+	if name != "ip" && name != "ipv4" && name != "ipv6" {
+		return nil
+	}
+	addr := func(v reflect.Value) netip.Addr {
+		switch v := v.Interface().(type) {
+		case netip.Prefix:
+			return v.Addr()
+		case netip.AddrPort:
+			return v.Addr()
+		}
+		return v.Interface().(netip.Addr)
+	}
+	check := func(v reflect.Value) error {
+		switch a := addr(v); {
+		case !a.IsValid(), name == "ip":
+		case name == "ipv4" && !a.Is4():
+			return fmt.Errorf("%s is not an IPv4 address", a)
+		case name == "ipv6" && !a.Is6():
+			return fmt.Errorf("%s is not an IPv6 address", a)
+		}
+		return nil
+	}
+	s := Schema{Type: SchemaTypes{"string"}}
+	if t == addrType && name != "ip" {
+		s.Format = name
+	}
+	return &fieldFormat{
+		encode: func(e *encodeState, v reflect.Value, opts encOpts) {
+			if err := check(v); err != nil {
+				e.error(&UnsupportedValueError{v, err.Error()})
+			}
+			b, _ := v.Interface().(encoding.TextMarshaler).MarshalText()
+			e.Write(appendString(e.AvailableBuffer(), b, opts.escapeHTML))
+		},
+		decode: func(data []byte, v reflect.Value, _ *ContextValues) error {
+			s, err := formatString(data, v.Type())
+			if err != nil {
+				return err
+			}
+			if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+				return invalidFormatValue(data, v.Type(), name, err)
+			}
+			if err := check(v); err != nil {
+				return invalidFormatValue(data, v.Type(), name, err)
+			}
+			return nil
+		},
+		schema: s,
+	}
+}
+
+func bigIntFormat(name string) *fieldFormat {
+	if name != "number" && name != "string" {
+		return nil
+	}
+	quoted := name == "string"
+	s := Schema{Type: SchemaTypes{"integer"}}
+	if quoted {
+		s = Schema{Type: SchemaTypes{"string"}}
+	}
+	return &fieldFormat{
+		encode: func(e *encodeState, v reflect.Value, _ encOpts) {
+			if !v.CanAddr() {
+				p := reflect.New(bigIntType)
+				p.Elem().Set(v)
+				v = p.Elem()
+			}
+			i := v.Addr().Interface().(*big.Int)
+			b := mayAppendQuote(e.AvailableBuffer(), quoted)
+			b = i.Append(b, 10)
+			e.Write(mayAppendQuote(b, quoted))
+		},
+		decode: func(data []byte, v reflect.Value, _ *ContextValues) error {
+			lit := string(data)
+			if quoted {
+				var err error
+				if lit, err = formatString(data, v.Type()); err != nil {
+					return err
+				}
+			} else if c := data[0]; c != '-' && (c < '0' || c > '9') {
+				return &UnmarshalTypeError{Value: valueKind(data), Type: v.Type()}
+			}
+			if _, ok := v.Addr().Interface().(*big.Int).SetString(lit, 10); !ok {
+				return invalidFormatValue(data, v.Type(), name, errors.New("not an integer"))
+			}
+			return nil
+		},
+		schema: s,
+	}
+}
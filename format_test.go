@@ -0,0 +1,156 @@
+package json
+
+import (
+	"math/big"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type formatTypes struct {
+	RFC3339   time.Time      `json:"rfc3339,format:rfc3339"`
+	Date      time.Time      `json:"date,format:date"`
+	Layout    time.Time      `json:"layout,format:02/01/2006T15h04"`
+	Unix      time.Time      `json:"unix,format:unix"`
+	UnixMilli *time.Time     `json:"unixMilli,format:unixmilli"`
+	URL       url.URL        `json:"url,format:url"`
+	URLPtr    **url.URL      `json:"urlPtr,optional,format:url"`
+	Addr      netip.Addr     `json:"addr,format:ipv4"`
+	Prefix    netip.Prefix   `json:"prefix,format:ipv6"`
+	AddrPort  netip.AddrPort `json:"addrPort,format:ip"`
+	Number    big.Int        `json:"number,format:number"`
+	String    *big.Int       `json:"string,format:string"`
+	Nullable  *time.Time     `json:"nullable,nullable,format:unixnano"`
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 3, 9, 17, 30, 5, 123456789, time.UTC)
+	u, _ := url.Parse("https://example.com/a?b=c&d")
+	in := formatTypes{
+		RFC3339:   ts.Truncate(time.Second),
+		Date:      time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+		Layout:    time.Date(2024, 3, 9, 17, 30, 0, 0, time.UTC),
+		Unix:      ts.Truncate(time.Second).Local(),
+		UnixMilli: ptrTo(ts.Truncate(time.Millisecond).Local()),
+		URL:       *u,
+		URLPtr:    &u,
+		Addr:      netip.MustParseAddr("192.0.2.1"),
+		Prefix:    netip.MustParsePrefix("2001:db8::/32"),
+		AddrPort:  netip.MustParseAddrPort("[::1]:80"),
+		String:    big.NewInt(-42),
+	}
+	in.Number.SetString("123456789012345678901234567890", 10)
+
+	b, err := Marshal(&in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	const want = `{"rfc3339":"2024-03-09T17:30:05Z","date":"2024-03-09","layout":"09/03/2024T17h30",` +
+		`"unix":1710005405,"unixMilli":1710005405123,"url":"https://example.com/a?b=c\u0026d",` +
+		`"urlPtr":"https://example.com/a?b=c\u0026d","addr":"192.0.2.1","prefix":"2001:db8::/32",` +
+		`"addrPort":"[::1]:80","number":123456789012345678901234567890,"string":"-42","nullable":null}`
+	if string(b) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", b, want)
+	}
+
+	var out formatTypes
+	if err := Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", out, in)
+	}
+
+	// A value, not a pointer, is not addressable.
+	if b, err := Marshal(struct {
+		N big.Int `json:",format:string"`
+	}{*big.NewInt(7)}); err != nil || string(b) != `{"N":"7"}` {
+		t.Errorf("Marshal = %s, %v, want %s", b, err, `{"N":"7"}`)
+	}
+}
+
+func ptrTo[T any](v T) *T { return &v }
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      string
+		ptr     any
+		wantErr string
+	}{{
+		CaseName: Name("UnknownFormat"),
+		in:       `{}`,
+		ptr: new(struct {
+			T time.Time `json:",format:none"`
+		}),
+		wantErr: `json: unknown format "none" for field "T" of type "time.Time"`,
+	}, {
+		CaseName: Name("WrongType"),
+		in:       `{}`,
+		ptr: new(struct {
+			S string `json:",format:url"`
+		}),
+		wantErr: `json: unknown format "url" for field "S" of type "string"`,
+	}, {
+		CaseName: Name("UnixString"),
+		in:       `{"T":"1"}`,
+		ptr: new(struct {
+			T time.Time `json:",format:unix"`
+		}),
+		wantErr: `json: cannot unmarshal string into Go struct field .T of type time.Time`,
+	}, {
+		CaseName: Name("BadLayout"),
+		in:       `{"T":"2024-13-01"}`,
+		ptr: new(struct {
+			T time.Time `json:",format:date"`
+		}),
+		wantErr: `json: cannot unmarshal "2024-13-01" into Go value of type time.Time with format date: parsing time "2024-13-01": month out of range`,
+	}, {
+		CaseName: Name("WrongFamily"),
+		in:       `{"A":"::1"}`,
+		ptr: new(struct {
+			A netip.Addr `json:",format:ipv4"`
+		}),
+		wantErr: `json: cannot unmarshal "::1" into Go value of type netip.Addr with format ipv4: ::1 is not an IPv4 address`,
+	}, {
+		CaseName: Name("BigIntFraction"),
+		in:       `{"N":1.5}`,
+		ptr: new(struct {
+			N big.Int `json:",format:number"`
+		}),
+		wantErr: `json: cannot unmarshal 1.5 into Go value of type big.Int with format number: not an integer`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.in), tt.ptr)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("%s: Unmarshal error:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := Marshal(struct {
+		A netip.Addr `json:",format:ipv6"`
+	}{netip.MustParseAddr("192.0.2.1")})
+	if want := "json: unsupported value: 192.0.2.1 is not an IPv6 address"; errString(err) != want {
+		t.Errorf("Marshal error:\n\tgot:  %v\n\twant: %s", err, want)
+	}
+}
+
+func TestFormatSchema(t *testing.T) {
+	s, err := SchemaOf(struct {
+		T time.Time  `json:"t,format:unix"`
+		U *url.URL   `json:"u,format:url"`
+		A netip.Addr `json:"a,format:ipv6"`
+	}{})
+	if err != nil {
+		t.Fatalf("SchemaOf error: %v", err)
+	}
+	got, _ := Marshal(s.Properties)
+	const want = `{"a":{"type":"string","format":"ipv6"},"t":{"type":"integer"},"u":{"type":["string","null"],"format":"uri"}}`
+	if string(got) != want {
+		t.Errorf("SchemaOf properties:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
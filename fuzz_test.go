@@ -81,3 +81,15 @@ func FuzzDecoderToken(f *testing.F) {
 		}
 	})
 }
+
+func FuzzValidate(f *testing.F) {
+	for _, in := range validateTests {
+		f.Add([]byte(in))
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		want := checkValid(b, newScanner())
+		if got := Validate(b); !equalSyntaxError(got, want) {
+			t.Errorf("Validate(%q):\n\tgot:  %v\n\twant: %v", b, got, want)
+		}
+	})
+}
@@ -4,7 +4,11 @@
 
 package json
 
-import "bytes"
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
 // HTMLEscape appends to dst the JSON-encoded src with <, >, &, U+2028 and U+2029
 // characters inside string literals changed to \u003c, \u003e, \u0026, \u2028, \u2029
@@ -36,6 +40,56 @@ func appendHTMLEscape(dst, src []byte) []byte {
 	return append(dst, src[start:]...)
 }
 
+// appendEscapeRunes appends to dst the JSON-encoded src with the characters
+// inside string literals for which escape reports true changed to \u escapes,
+// using a surrogate pair for characters outside the Basic Multilingual Plane.
+// Characters that are already escaped are left unchanged.
+func appendEscapeRunes(dst, src []byte, escape func(rune) bool) []byte {
+	inString := false
+	start := 0
+	for i := 0; i < len(src); {
+		c := src[i]
+		if !inString {
+			inString = c == '"'
+			i++
+			continue
+		}
+		switch c {
+		case '"':
+			inString = false
+			i++
+			continue
+		case '\\':
+			if i+1 < len(src) && src[i+1] == 'u' {
+				i += len(`\u0000`)
+			} else {
+				i += len(`\n`)
+			}
+			continue
+		}
+		r, size := rune(c), 1
+		if c >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(src[i:])
+		}
+		if (r != utf8.RuneError || size > 1) && escape(r) {
+			dst = append(dst, src[start:i]...)
+			if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+				dst = appendRuneEscape(dst, r1)
+				dst = appendRuneEscape(dst, r2)
+			} else {
+				dst = appendRuneEscape(dst, r)
+			}
+			start = i + size
+		}
+		i += size
+	}
+	return append(dst, src[start:]...)
+}
+
+func appendRuneEscape(dst []byte, r rune) []byte {
+	return append(dst, '\\', 'u', hex[r>>12&0xF], hex[r>>8&0xF], hex[r>>4&0xF], hex[r&0xF])
+}
+
 // Compact appends to dst the JSON-encoded src with
 // insignificant space characters elided.
 func Compact(dst *bytes.Buffer, src []byte) error {
@@ -124,12 +178,41 @@ func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
 }
 
 func appendIndent(dst, src []byte, prefix, indent string) ([]byte, error) {
+	return appendIndentVerbatim(dst, src, prefix, indent, nil)
+}
+
+// appendIndentVerbatim is like appendIndent, but keeps the formatting of the
+// values at the spans of src given by pairs of start and end offsets, in
+// increasing order. The lines of such a value after the first are prefixed
+// with the indentation at the value's position.
+func appendIndentVerbatim(dst, src []byte, prefix, indent string, spans []int) ([]byte, error) {
 	origLen := len(dst)
 	scan := newScanner()
 	defer freeScanner(scan)
 	needIndent := false
 	depth := 0
-	for _, c := range src {
+	for i := 0; i < len(src); i++ {
+		if len(spans) > 0 && i == spans[0] {
+			end := spans[1]
+			spans = spans[2:]
+			if needIndent {
+				needIndent = false
+				depth++
+				dst = appendNewline(dst, prefix, indent, depth)
+			}
+			for _, c := range src[i:end] {
+				scan.bytes++
+				scan.step(scan, c) // valid, as checked when it was encoded
+				if c == '\n' {
+					dst = appendNewline(dst, prefix, indent, depth)
+				} else {
+					dst = append(dst, c)
+				}
+			}
+			i = end - 1
+			continue
+		}
+		c := src[i]
 		scan.bytes++
 		v := scan.step(scan, c)
 		if v == scanSkipSpace {
@@ -0,0 +1,181 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// typeMember is the key of the object member naming a registered type:
+// that of the value in an object encoded with MarshalOptions.TypedInterfaces,
+// and that of the dynamic value of an inline field.
+const typeMember = "$type"
+
+// checkInlineFields checks that the fields of the struct type t include
+// at most one inline field, and no other field with its $type member.
+func checkInlineFields(t reflect.Type, fields []field) error {
+	inline, typed := 0, 0
+	for i := range fields {
+		if fields[i].inline {
+			inline++
+		} else if fields[i].name == typeMember {
+			typed++
+		}
+	}
+	switch {
+	case inline > 1:
+		return fmt.Errorf("json: struct %s has more than one inline field", t)
+	case inline == 1 && typed > 0:
+		return fmt.Errorf("json: field %q of struct %s conflicts with its inline field", typeMember, t)
+	}
+	return nil
+}
+
+// encodeInline writes the $type member for the inline field f of the
+// struct v, and the members of the field's dynamic value, after next.
+// It returns the byte to write before the next member.
+func (se structEncoder) encodeInline(e *encodeState, v reflect.Value, f *field, next byte, opts encOpts) byte {
+	fv := v
+	for _, i := range f.index {
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				return next
+			}
+			fv = fv.Elem()
+		}
+		fv = fv.Field(i)
+	}
+	if fv.IsNil() {
+		return next
+	}
+	elem := fv.Elem()
+	name, ok := registeredName(elem.Type())
+	if !ok {
+		e.error(&SemanticError{Type: elem.Type(), Err: ErrUnregisteredType, msg: fmt.Sprintf("json: type %s stored in inline field of %s is not registered", elem.Type(), v.Type())})
+	}
+	e.WriteByte(next)
+	e.WriteString(`"` + typeMember + `":`)
+	e.Write(appendString(e.AvailableBuffer(), name, opts.escapeHTML))
+
+	// Encode the value as usual, then remove the braces around its members.
+	start, flushed := e.Len(), e.flushed
+	e.reflectValue(elem, opts)
+	if e.flushed != flushed {
+		e.error(&UnsupportedValueError{elem, "inline value of type " + elem.Type().String() + " was streamed"})
+	}
+	b := e.Bytes()[start:]
+	switch {
+	case string(b) == "null" || string(b) == "{}":
+		e.Truncate(start)
+		return ','
+	case b[0] != '{':
+		e.error(&UnsupportedValueError{elem, "inline value of type " + elem.Type().String() + " does not encode as an object"})
+	}
+	entries, _, err := containerEntries(b, 0)
+	if err != nil {
+		e.error(err)
+	}
+	for _, m := range entries {
+		if se.fields.byExactName[m.key] != nil {
+			e.error(&UnsupportedValueError{elem, fmt.Sprintf("member %q of inline %s conflicts with a field of %s", m.key, elem.Type(), v.Type())})
+		}
+	}
+	b[0] = ','
+	e.Truncate(e.Len() - 1)
+	return ','
+}
+
+// decodeInline decodes the value of the $type member, starting at
+// d.data[d.readIndex()], of the object starting at d.data[objStart], and
+// decodes the whole object into a new value of the type it names, stored
+// in the inline field v. It returns the type, or nil if there is none.
+func (d *decodeState) decodeInline(v reflect.Value, objStart int, mask maskTree) reflect.Type {
+	valueStart := d.readIndex()
+	raw := d.rawValue()
+	var name string
+	if err := Unmarshal(raw, &name); err != nil {
+		d.saveError(newSemanticError(valueStart, v.Type(), err, "json: invalid $type %s", raw))
+		return nil
+	}
+	t, ok := registeredType(name)
+	if !ok {
+		d.saveError(newSemanticError(valueStart, v.Type(), ErrUnregisteredType, "json: type name %q is not registered", name))
+		return nil
+	}
+	if !t.AssignableTo(v.Type()) {
+		d.saveError(newSemanticError(valueStart, v.Type(), errors.New("registered type not assignable"), "json: registered type %s for %q cannot be stored in %s", t, name, v.Type()))
+		return nil
+	}
+	if hasInlineField(t) {
+		d.saveError(newSemanticError(valueStart, v.Type(), errors.New("nested inline field"), "json: registered type %s for %q has an inline field of its own", t, name))
+		return nil
+	}
+
+	// Members of the object that are not fields of t are fields of the
+	// enclosing struct, or are reported as unknown once t is known.
+	end, err := valueEnd(d.data, objStart)
+	if err != nil {
+		d.saveError(err)
+		return nil
+	}
+	disallow, outerMask := d.disallowUnknownFields, d.mask
+	d.disallowUnknownFields, d.mask = false, mask
+	var warned int
+	if d.warnings != nil {
+		warned = len(*d.warnings)
+	}
+	p := d.decodeElsewhere(d.data[objStart:end], objStart, t)
+	d.disallowUnknownFields, d.mask = disallow, outerMask
+	if d.warnings != nil {
+		kept := slices.DeleteFunc((*d.warnings)[warned:], func(w Warning) bool { return w.Err == ErrUnknownField })
+		*d.warnings = (*d.warnings)[:warned+len(kept)]
+	}
+	v.Set(p.Elem())
+	return t
+}
+
+// hasInlineField reports whether t is a struct, or a pointer to one, with
+// an inline field.
+func hasInlineField(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	f := cachedTypeFields(t).byExactName[typeMember]
+	return f != nil && f.inline
+}
+
+// An inlineKey is a key of an object decoded into a struct with an inline
+// field that matches no field of the struct.
+type inlineKey struct {
+	key string
+	off int
+}
+
+// reportUnknownInline reports the keys of the object decoded into the
+// struct type t that match no field of t, nor of inlineType, the type of
+// the value of its inline field, if any.
+func (d *decodeState) reportUnknownInline(keys []inlineKey, t, inlineType reflect.Type) {
+	var inner structFields
+	if inlineType != nil {
+		for inlineType.Kind() == reflect.Pointer {
+			inlineType = inlineType.Elem()
+		}
+		if inlineType.Kind() == reflect.Struct {
+			inner = cachedTypeFields(inlineType)
+		}
+	}
+	for _, k := range keys {
+		if inner.byExactName[k.key] != nil || !d.caseSensitive && inner.byFoldedName[string(foldName([]byte(k.key)))] != nil {
+			continue
+		}
+		if d.disallowUnknownFields {
+			d.saveError(newSemanticError(k.off, t, ErrUnknownField, "json: unknown field %q", k.key))
+		} else {
+			d.warn(k.off, ErrUnknownField)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type InlineShape interface{ Area() float64 }
+
+type inlineSquare struct {
+	Side float64 `json:"side"`
+}
+
+func (s inlineSquare) Area() float64 { return s.Side * s.Side }
+
+type inlineCircle struct {
+	R float64 `json:"r"`
+}
+
+func (c *inlineCircle) Area() float64 { return 3 * c.R * c.R }
+
+type inlineClash struct {
+	ID string `json:"id"`
+}
+
+func (inlineClash) Area() float64 { return 0 }
+
+type inlineNumber float64
+
+func (n inlineNumber) Area() float64 { return float64(n) }
+
+func init() {
+	RegisterType("json.inlineSquare", inlineSquare{})
+	RegisterType("json.inlineCircle", &inlineCircle{})
+	RegisterType("json.inlineClash", inlineClash{})
+	RegisterType("json.inlineNumber", inlineNumber(0))
+}
+
+type inlineEvent struct {
+	ID          string `json:"id"`
+	InlineShape `json:",inline"`
+	Note        string `json:"note,omitempty"`
+}
+
+type inlineNamed struct {
+	Shape InlineShape `json:",inline"`
+	N     int         `json:"n"`
+}
+
+func TestInlineRoundTrip(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in   any
+		want string
+	}{{
+		CaseName: Name("value"),
+		in:       inlineEvent{ID: "a", InlineShape: inlineSquare{2}, Note: "x"},
+		want:     `{"id":"a","$type":"json.inlineSquare","side":2,"note":"x"}`,
+	}, {
+		CaseName: Name("pointer"),
+		in:       inlineEvent{ID: "b", InlineShape: &inlineCircle{1.5}},
+		want:     `{"id":"b","$type":"json.inlineCircle","r":1.5}`,
+	}, {
+		CaseName: Name("nil"),
+		in:       inlineEvent{ID: "c"},
+		want:     `{"id":"c"}`,
+	}, {
+		CaseName: Name("first field"),
+		in:       inlineNamed{Shape: inlineSquare{1}, N: 3},
+		want:     `{"$type":"json.inlineSquare","side":1,"n":3}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(b) != tt.want {
+				t.Fatalf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.want)
+			}
+			got := reflect.New(reflect.TypeOf(tt.in))
+			if err := (UnmarshalOptions{DisallowUnknownFields: true}).Unmarshal(b, got.Interface()); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if !reflect.DeepEqual(got.Elem().Interface(), tt.in) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %#v\n\twant: %#v", tt.Where, got.Elem().Interface(), tt.in)
+			}
+		})
+	}
+}
+
+func TestInlineUnmarshal(t *testing.T) {
+	// The $type member may come after the members of the value.
+	var ev inlineEvent
+	if err := Unmarshal([]byte(`{"side": 3, "id": "x", "$type": "json.inlineSquare"}`), &ev); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := (inlineEvent{ID: "x", InlineShape: inlineSquare{3}}); ev != want {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", ev, want)
+	}
+
+	// Without a $type member, the field is left alone and the other
+	// members are unknown.
+	ev = inlineEvent{InlineShape: inlineSquare{1}}
+	var warnings []Warning
+	if err := (UnmarshalOptions{Warnings: &warnings}).Unmarshal([]byte(`{"id": "y", "side": 2}`), &ev); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := (inlineEvent{ID: "y", InlineShape: inlineSquare{1}}); ev != want {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", ev, want)
+	}
+	if len(warnings) != 1 || warnings[0].Path != "/side" || warnings[0].Err != ErrUnknownField {
+		t.Errorf("Warnings: got %v, want one unknown field at /side", warnings)
+	}
+
+	// Members in neither type are unknown.
+	err := (UnmarshalOptions{DisallowUnknownFields: true}).Unmarshal([]byte(`{"$type": "json.inlineSquare", "side": 2, "r": 1}`), &ev)
+	var se *SemanticError
+	if !errors.As(err, &se) || se.Err != ErrUnknownField || se.Offset != 42 {
+		t.Errorf("Unmarshal error: got %#v, want unknown field at offset 42", err)
+	}
+}
+
+type inlineTwice struct {
+	A InlineShape `json:",inline"`
+	B InlineShape `json:",inline"`
+}
+
+type inlineTyped struct {
+	A InlineShape `json:",inline"`
+	T string      `json:"$type"`
+}
+
+type inlineNotInterface struct {
+	A inlineSquare `json:",inline"`
+}
+
+type inlineWithOptions struct {
+	A InlineShape `json:",inline,omitempty"`
+}
+
+type inlineHidden struct {
+	inlineShape `json:",inline"`
+}
+
+type inlineShape interface{ Area() float64 }
+
+type inlineOuter struct {
+	S InlineShape `json:",inline"`
+}
+
+func (inlineOuter) Area() float64 { return 0 }
+
+func TestInlineErrors(t *testing.T) {
+	RegisterType("json.inlineOuter", inlineOuter{})
+	tests := []struct {
+		CaseName
+		in  any
+		err string
+	}{
+		{Name("two inline fields"), inlineTwice{}, "more than one inline field"},
+		{Name("$type field"), inlineTyped{}, `field "$type" of struct json.inlineTyped conflicts`},
+		{Name("unexported"), inlineHidden{}, "inline field inlineShape is unexported"},
+		{Name("not interface"), inlineNotInterface{}, "non-interface type"},
+		{Name("options"), inlineWithOptions{}, "cannot have a name or other options"},
+		{Name("unregistered"), inlineEvent{InlineShape: &inlineSquare{}}, "not registered"},
+		{Name("not object"), inlineEvent{InlineShape: inlineNumber(1)}, "does not encode as an object"},
+		{Name("conflict"), inlineEvent{InlineShape: inlineClash{}}, `member "id" of inline json.inlineClash conflicts`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := Marshal(tt.in)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.err)
+			}
+		})
+	}
+
+	var ev inlineEvent
+	for _, in := range []string{
+		`{"$type": 1}`,
+		`{"$type": "json.nope"}`,
+		`{"$type": "json.inlineOuter"}`,
+		`{"$type": "json.inlineNumber"}`,
+	} {
+		if err := Unmarshal([]byte(in), &ev); err == nil {
+			t.Errorf("Unmarshal(%s): got nil error, want error", in)
+		}
+	}
+}
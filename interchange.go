@@ -0,0 +1,121 @@
+package json
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// checkInterchange reports whether the valid JSON text data conforms to the
+// I-JSON profile (RFC 7493): strings, including escaped ones, must hold valid
+// Unicode without unpaired surrogates, numbers must be within the range of
+// IEEE 754 double precision, and objects must not have duplicate keys.
+func checkInterchange(data []byte) error {
+	var keys []map[string]struct{} // for each enclosing object or array; nil for arrays
+	for i := 0; i < len(data); {
+		switch c := data[i]; {
+		case c == '{':
+			keys = append(keys, map[string]struct{}{})
+			i++
+		case c == '[':
+			keys = append(keys, nil)
+			i++
+		case c == '}' || c == ']':
+			keys = keys[:len(keys)-1]
+			i++
+		case c == '"':
+			end, err := checkInterchangeString(data, i)
+			if err != nil {
+				return err
+			}
+			if j := skipSpace(data, end); j < len(data) && data[j] == ':' {
+				key, _ := unquote(data[i:end])
+				seen := keys[len(keys)-1]
+				if _, ok := seen[key]; ok {
+					return &SyntaxError{"duplicate object key " + strconv.Quote(key), int64(i + 1)}
+				}
+				seen[key] = struct{}{}
+			}
+			i = end
+		case c == '-' || '0' <= c && c <= '9':
+			end := i + 1
+			for end < len(data) && isNumberByte(data[end]) {
+				end++
+			}
+			if f, _ := strconv.ParseFloat(string(data[i:end]), 64); math.IsInf(f, 0) {
+				return &SyntaxError{"number " + string(data[i:end]) + " overflows IEEE 754 double precision", int64(i + 1)}
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return nil
+}
+
+// checkInterchangeString checks the string literal starting at data[i]
+// and returns the offset just past it.
+func checkInterchangeString(data []byte, i int) (int, error) {
+	for i++; data[i] != '"'; {
+		switch c := data[i]; {
+		case c == '\\' && data[i+1] == 'u':
+			r := getu4(data[i:])
+			if utf16.IsSurrogate(r) {
+				if r2 := getu4(data[i+6:]); r >= 0xdc00 || utf16.DecodeRune(r, r2) == utf8.RuneError {
+					return 0, &SyntaxError{"unpaired surrogate escape " + string(data[i:i+6]) + " in string", int64(i + 1)}
+				}
+				i += 6
+			}
+			i += 6
+		case c == '\\':
+			i += 2
+		case c < utf8.RuneSelf:
+			i++
+		default:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				return 0, &SyntaxError{"invalid UTF-8 in string", int64(i + 1)}
+			}
+			i += size
+		}
+	}
+	return i + 1, nil
+}
+
+// repairSurrogates returns the valid JSON text data with each \u escape for an
+// unpaired UTF-16 surrogate changed to \ufffd, and the number of escapes
+// changed. If there are none, data itself is returned; otherwise, a copy.
+func repairSurrogates(data []byte) ([]byte, int) {
+	n := 0
+	inString := false
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			inString = !inString
+		case !inString || c != '\\':
+		case data[i+1] != 'u':
+			i++
+		default:
+			r := getu4(data[i:])
+			if utf16.IsSurrogate(r) {
+				if r < 0xdc00 && utf16.DecodeRune(r, getu4(data[i+6:])) != utf8.RuneError {
+					i += 6 // leave the pair, skipping its first half here
+				} else {
+					if n == 0 {
+						data = slices.Clone(data)
+					}
+					copy(data[i:], `\ufffd`)
+					n++
+				}
+			}
+			i += 5
+		}
+	}
+	return data, n
+}
+
+func isNumberByte(c byte) bool {
+	return '0' <= c && c <= '9' || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-'
+}
@@ -0,0 +1,107 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnmarshalInterchange(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      string
+		wantErr string
+	}{
+		{Name("valid"), `{"a": ["é😀", "é", 1e308, -0.5], "b": {"a": 1}, "ab": true}`, ""},
+		{Name("lone high surrogate"), `["\ud800"]`, `unpaired surrogate escape \ud800 in string`},
+		{Name("high surrogate then text"), `"\ud800x"`, `unpaired surrogate escape \ud800 in string`},
+		{Name("reversed surrogates"), `"\ude00\ud83d"`, `unpaired surrogate escape \ude00 in string`},
+		{Name("invalid UTF-8"), "\"a\xffb\"", `invalid UTF-8 in string`},
+		{Name("encoded surrogate"), "\"\xed\xa0\x80\"", `invalid UTF-8 in string`},
+		{Name("number overflow"), `[1e309]`, `number 1e309 overflows IEEE 754 double precision`},
+		{Name("duplicate key"), `{"a": {"x": 1}, "a": 2}`, `duplicate object key "a"`},
+		{Name("trailing data"), `{} {}`, `invalid character '{' after top-level value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var v any
+			err := UnmarshalOptions{Interchange: true}.Unmarshal([]byte(tt.in), &v)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("%s: Unmarshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+			var serr *SyntaxError
+			if err != nil && !errors.As(err, &serr) {
+				t.Errorf("%s: Unmarshal error type = %T, want *SyntaxError", tt.Where, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalRepairSurrogates(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      string
+		want    string
+		repairs int
+	}{
+		{Name("pair"), `"\ud83d\ude00"`, "😀", 0},
+		{Name("lone high surrogate"), `"a\ud800b"`, "a\ufffdb", 1},
+		{Name("lone low surrogate"), `"\udc00"`, "\ufffd", 1},
+		{Name("reversed surrogates"), `"\ude00\ud83d"`, "\ufffd\ufffd", 2},
+		{Name("high surrogate then pair"), `"\ud800\ud83d\ude00"`, "\ufffd😀", 1},
+		{Name("escaped backslash"), `"\\ud800"`, `\ud800`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			if _, n := repairSurrogates([]byte(tt.in)); n != tt.repairs {
+				t.Errorf("%s: repairSurrogates: got %d repairs, want %d", tt.Where, n, tt.repairs)
+			}
+			opts := UnmarshalOptions{Interchange: true, RepairSurrogates: true}
+			var got string
+			if err := opts.Unmarshal([]byte(tt.in), &got); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %q\n\twant: %q", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	in := []byte(`{"raw": "\ud800"}`)
+	var v struct{ Raw RawMessage }
+	if err := (UnmarshalOptions{RepairSurrogates: true}).Unmarshal(in, &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got, want := string(v.Raw), `"\ufffd"`; got != want {
+		t.Errorf("Unmarshal RawMessage:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+	if got, want := string(in), `{"raw": "\ud800"}`; got != want {
+		t.Errorf("Unmarshal modified its input:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+type interchangeText string
+
+func (s interchangeText) MarshalText() ([]byte, error) { return []byte(s), nil }
+
+func TestMarshalInterchange(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      any
+		wantErr string
+	}{
+		{Name("valid"), map[string]any{"é": []any{"ok", 1.5, RawMessage(`{"a":1,"b":2}`)}}, ""},
+		{Name("invalid string"), "a\xff", `json: unsupported value: invalid UTF-8 in string "a\xff"`},
+		{Name("invalid map key"), map[string]int{"\xff": 1}, `json: unsupported value: invalid UTF-8 in map key "\xff"`},
+		{Name("invalid text"), interchangeText("\xfe"), `json: unsupported value: invalid UTF-8 in string "\xfe"`},
+		{Name("raw surrogate"), RawMessage(`"\udc00"`), `unpaired surrogate escape \udc00 in string`},
+		{Name("raw duplicate key"), RawMessage(`{"a":1,"a":2}`), `duplicate object key "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := MarshalOptions{Interchange: true}.Marshal(tt.in)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+		})
+	}
+}
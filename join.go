@@ -0,0 +1,116 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A RawField is a member of an object, given as its key and the encoding
+// of its value, for [JoinObject].
+type RawField struct {
+	Key   string
+	Value RawMessage
+}
+
+// JoinArray returns the JSON array whose elements are items, in order.
+// Each item must be a valid JSON value and is copied without its leading
+// and trailing white space; the rest of it, including its formatting, is
+// copied verbatim rather than decoded and encoded again. A nil item is
+// encoded as null, as by [RawMessage.MarshalJSON].
+func JoinArray(items ...RawMessage) (RawMessage, error) {
+	n := 2
+	for _, item := range items {
+		n += len(item) + 1
+	}
+	out := make(RawMessage, 0, n)
+	out = append(out, '[')
+	for i, item := range items {
+		v, err := trimRawValue(item)
+		if err != nil {
+			return nil, fmt.Errorf("json: invalid array element %d: %w", i, err)
+		}
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, v...)
+	}
+	return append(out, ']'), nil
+}
+
+// JoinObject returns the JSON object whose members are pairs, in order.
+// Keys are encoded as by [Marshal] and are not checked for duplicates;
+// values are copied as the items of [JoinArray] are.
+func JoinObject(pairs ...RawField) (RawMessage, error) {
+	n := 2
+	for _, p := range pairs {
+		n += len(p.Key) + len(p.Value) + 4
+	}
+	out := make(RawMessage, 0, n)
+	out = append(out, '{')
+	for i, p := range pairs {
+		v, err := trimRawValue(p.Value)
+		if err != nil {
+			return nil, fmt.Errorf("json: invalid value for key %q: %w", p.Key, err)
+		}
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = appendString(out, p.Key, true)
+		out = append(out, ':')
+		out = append(out, v...)
+	}
+	return append(out, '}'), nil
+}
+
+// AppendField returns a copy of the JSON object obj with a member added
+// after its last one, with the given key and the value val, which is
+// copied as the items of [JoinArray] are. The rest of obj is copied
+// verbatim, without its surrounding white space, and a member of obj with
+// the same key is kept; use [Set] to replace one.
+func AppendField(obj RawMessage, key string, val RawMessage) (RawMessage, error) {
+	v, err := trimRawValue(val)
+	if err != nil {
+		return nil, fmt.Errorf("json: invalid value for key %q: %w", key, err)
+	}
+	o, err := trimRawValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	if o[0] != '{' {
+		return nil, errors.New("json: AppendField of non-object")
+	}
+
+	// Add the member just after the last value, before any white space
+	// preceding the closing brace.
+	at := len(o) - 1
+	for isSpace(o[at-1]) {
+		at--
+	}
+	var insert []byte
+	if o[at-1] != '{' {
+		insert = append(insert, ',')
+	}
+	insert = appendString(insert, key, true)
+	insert = append(insert, ':')
+	insert = append(insert, v...)
+	return splice(o, at, at, insert), nil
+}
+
+// trimRawValue checks that b is a single JSON value and returns it without
+// its surrounding white space, or null for a nil b.
+func trimRawValue(b RawMessage) ([]byte, error) {
+	if b == nil {
+		return []byte("null"), nil
+	}
+	if err := Validate(b); err != nil {
+		return nil, err
+	}
+	start, end := 0, len(b)
+	for isSpace(b[start]) {
+		start++
+	}
+	for isSpace(b[end-1]) {
+		end--
+	}
+	return b[start:end], nil
+}
@@ -0,0 +1,95 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJoinArray(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in   []RawMessage
+		want string
+	}{
+		{Name("empty"), nil, `[]`},
+		{Name("one"), []RawMessage{RawMessage(`1`)}, `[1]`},
+		{Name("verbatim"), []RawMessage{RawMessage(" {\"a\" : [1, 2]}\n"), RawMessage(`"x"`), nil, RawMessage(`1.0e2`)}, `[{"a" : [1, 2]},"x",null,1.0e2]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := JoinArray(tt.in...)
+			if err != nil {
+				t.Fatalf("%s: JoinArray error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: JoinArray:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinObject(t *testing.T) {
+	got, err := JoinObject(
+		RawField{"a", RawMessage(` 1 `)},
+		RawField{"b<c", RawMessage(`{"x":[]}`)},
+		RawField{"a", nil},
+	)
+	if err != nil {
+		t.Fatalf("JoinObject error: %v", err)
+	}
+	if want := `{"a":1,"b\u003cc":{"x":[]},"a":null}`; string(got) != want {
+		t.Errorf("JoinObject:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+	if got, err := JoinObject(); err != nil || string(got) != `{}` {
+		t.Errorf("JoinObject() = %s, %v, want {}", got, err)
+	}
+}
+
+func TestAppendField(t *testing.T) {
+	tests := []struct {
+		CaseName
+		obj, key, val string
+		want          string
+	}{
+		{Name("empty"), `{}`, "a", `1`, `{"a":1}`},
+		{Name("empty with space"), " { \n } ", "a", `1`, "{\"a\":1 \n }"},
+		{Name("members"), `{"x": [1, 2]}`, "y", ` "z" `, `{"x": [1, 2],"y":"z"}`},
+		{Name("indented"), "{\n  \"x\": 1\n}", "y", `true`, "{\n  \"x\": 1,\"y\":true\n}"},
+		{Name("duplicate"), `{"a":1}`, "a", `2`, `{"a":1,"a":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := AppendField(RawMessage(tt.obj), tt.key, RawMessage(tt.val))
+			if err != nil {
+				t.Fatalf("%s: AppendField error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: AppendField:\n\tgot:  %q\n\twant: %q", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinErrors(t *testing.T) {
+	var se *SyntaxError
+	if _, err := JoinArray(RawMessage(`1`), RawMessage(`[`)); !errors.As(err, &se) {
+		t.Errorf("JoinArray error: got %v, want *SyntaxError", err)
+	}
+	if _, err := JoinArray(RawMessage(``)); !errors.As(err, &se) {
+		t.Errorf("JoinArray error: got %v, want *SyntaxError", err)
+	}
+	if _, err := JoinObject(RawField{"a", RawMessage(`1 2`)}); !errors.As(err, &se) {
+		t.Errorf("JoinObject error: got %v, want *SyntaxError", err)
+	}
+	if _, err := AppendField(RawMessage(`{"a":1} x`), "b", RawMessage(`2`)); !errors.As(err, &se) {
+		t.Errorf("AppendField error: got %v, want *SyntaxError", err)
+	}
+	if _, err := AppendField(RawMessage(`{}`), "b", RawMessage(`tru`)); !errors.As(err, &se) {
+		t.Errorf("AppendField error: got %v, want *SyntaxError", err)
+	}
+	for _, obj := range []string{`[1]`, `"{}"`, ``} {
+		if _, err := AppendField(RawMessage(obj), "b", RawMessage(`2`)); err == nil {
+			t.Errorf("AppendField(%#q): got nil error, want error", obj)
+		}
+	}
+}
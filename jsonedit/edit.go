@@ -0,0 +1,212 @@
+package jsonedit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	json "github.com/crunk1/gojson"
+)
+
+// Get returns the value at path within the document. Paths are as for
+// [json.Get]: each element is a string, selecting the member of an object
+// with that key, or an int, selecting the element of an array at that
+// index. If an object has duplicate keys, the first member with the key is
+// selected. If there is no value at path, the error wraps
+// [json.ErrPathNotFound].
+//
+// The returned Value is part of the document, so changes to it are
+// changes to the document.
+func (v *Value) Get(path ...any) (*Value, error) {
+	for n, p := range path {
+		var next *Value
+		switch p := p.(type) {
+		case string:
+			o, ok := v.Node.(*Object)
+			if !ok {
+				return nil, fmt.Errorf("jsonedit: value at %q is not an object", pathPointer(path[:n]))
+			}
+			if k := o.index(p); k < len(o.Members) {
+				next = &o.Members[k].Value
+			}
+		case int:
+			a, ok := v.Node.(*Array)
+			if !ok {
+				return nil, fmt.Errorf("jsonedit: value at %q is not an array", pathPointer(path[:n]))
+			}
+			if 0 <= p && p < len(a.Elements) {
+				next = &a.Elements[p]
+			}
+		default:
+			return nil, fmt.Errorf("jsonedit: invalid path element of type %T", p)
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%w: %q", json.ErrPathNotFound, pathPointer(path[:n+1]))
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// Set replaces the value at path with the encoding of value, as returned
+// by [json.Marshal], keeping the white space and comments around it.
+//
+// If the last element of path names a member missing from its object, the
+// member is added at the end of the object, and if it is the index just
+// past the end of its array, the element is appended to the array. The new
+// member or element is laid out like the last one before it, on a line of
+// its own if that one is. Other missing values are not created, and the
+// error wraps [json.ErrPathNotFound].
+func (v *Value) Set(value any, path ...any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	node := Node(Literal(b))
+	if b[0] == '{' || b[0] == '[' {
+		nv, err := Parse(b)
+		if err != nil {
+			return err
+		}
+		node = nv.Node
+	}
+
+	target, err := v.Get(path...)
+	if err == nil {
+		target.Node = node
+		return nil
+	}
+	if !errors.Is(err, json.ErrPathNotFound) {
+		return err
+	}
+	parent, err := v.Get(path[:len(path)-1]...)
+	if err != nil {
+		return err
+	}
+	switch p := path[len(path)-1].(type) {
+	case string:
+		o := parent.Node.(*Object)
+		key, _ := json.Marshal(p)
+		m := Member{Name: Value{Node: Literal(key)}, Value: Value{Node: node}}
+		if n := len(o.Members); n > 0 {
+			last := &o.Members[n-1]
+			m.Name.Before, m.Name.After, m.Value.Before = last.Name.Before, last.Name.After, last.Value.Before
+			if o.Comma {
+				m.Name.Before, o.End = moveAfter(m.Name.Before, o.End)
+			} else {
+				m.Name.Before, m.Value.After = moveAfter(m.Name.Before, last.Value.After)
+				last.Value.After = nil
+			}
+		} else {
+			m.Value.After, o.End = o.End, nil
+		}
+		o.Members = append(o.Members, m)
+	case int:
+		a := parent.Node.(*Array)
+		if p != len(a.Elements) {
+			return fmt.Errorf("%w: %q", json.ErrPathNotFound, pathPointer(path))
+		}
+		e := Value{Node: node}
+		if n := len(a.Elements); n > 0 {
+			last := &a.Elements[n-1]
+			e.Before = last.Before
+			if a.Comma {
+				e.Before, a.End = moveAfter(e.Before, a.End)
+			} else {
+				e.Before, e.After = moveAfter(e.Before, last.After)
+				last.After = nil
+			}
+		} else {
+			e.After, a.End = a.End, nil
+		}
+		a.Elements = append(a.Elements, e)
+	}
+	return nil
+}
+
+// moveAfter splits after, the white space and comments between the last
+// value of an object or array and its closing bracket, for a value added
+// after it whose Before is before. Comments on the line of the last value
+// stay on that line, moving to the start of before, past the comma that
+// now follows that value; moveAfter returns the new before and the rest of
+// after, which stays before the bracket.
+func moveAfter(before, after []byte) (newBefore, rest []byte) {
+	head, tail := after, []byte(nil)
+	if i := bytes.LastIndexByte(after, '\n'); i >= 0 {
+		head, tail = after[:i], after[i:]
+	}
+	if bytes.IndexByte(head, '/') >= 0 {
+		before = append(head[:len(head):len(head)], before...)
+	}
+	return before, tail
+}
+
+// Delete removes the value at path from its object or array, together with
+// its key and the white space and comments before it. Paths are as for
+// [Value.Get].
+func (v *Value) Delete(path ...any) error {
+	if len(path) == 0 {
+		return errors.New("jsonedit: cannot delete the whole document")
+	}
+	if _, err := v.Get(path...); err != nil {
+		return err
+	}
+	parent, _ := v.Get(path[:len(path)-1]...)
+	switch n := parent.Node.(type) {
+	case *Object:
+		k := n.index(path[len(path)-1].(string))
+		n.Members, n.End = deleteEntry(n.Members, k, n.Comma, n.End, func(m *Member) *[]byte { return &m.Value.After })
+		n.Comma = n.Comma && len(n.Members) > 0
+	case *Array:
+		k := path[len(path)-1].(int)
+		n.Elements, n.End = deleteEntry(n.Elements, k, n.Comma, n.End, func(e *Value) *[]byte { return &e.After })
+		n.Comma = n.Comma && len(n.Elements) > 0
+	}
+	return nil
+}
+
+// deleteEntry removes entries[k] from the members or elements of an object
+// or array with the given Comma and End, and returns them and the new End.
+// If the last member or element is removed, the white space and comments
+// before the closing bracket are kept, as the After of the new last one.
+func deleteEntry[E any](entries []E, k int, comma bool, end []byte, after func(*E) *[]byte) ([]E, []byte) {
+	if k == len(entries)-1 && !comma {
+		if k > 0 {
+			prev := *after(&entries[k-1])
+			*after(&entries[k-1]) = append(prev[:len(prev):len(prev)], *after(&entries[k])...)
+		} else {
+			end = *after(&entries[k])
+		}
+	}
+	return append(entries[:k:k], entries[k+1:]...), end
+}
+
+// index returns the index of the first member of o with the given key, or
+// len(o.Members) if there is none.
+func (o *Object) index(key string) int {
+	for k := range o.Members {
+		if o.Members[k].Key() == key {
+			return k
+		}
+	}
+	return len(o.Members)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pathPointer returns the JSON Pointer (RFC 6901) for path.
+func pathPointer(path []any) string {
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteByte('/')
+		switch p := p.(type) {
+		case string:
+			b.WriteString(pointerEscaper.Replace(p))
+		case int:
+			b.WriteString(strconv.Itoa(p))
+		}
+	}
+	return b.String()
+}
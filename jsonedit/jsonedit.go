@@ -0,0 +1,195 @@
+// Package jsonedit edits JSON documents while preserving their formatting,
+// for tools that rewrite files written by people, such as configuration.
+//
+// [Parse] reads a document into a [Value] that records the white space,
+// comments, and order of everything in it, as well as the exact form of
+// each string and number. Values may then be replaced, added, and deleted
+// by path, and [Value.Bytes] writes the document back: it is identical to
+// the input except for the values edited.
+//
+// The documents may be standard JSON or the extension of it in which
+// comments, in the // and /* */ forms of Go, may appear wherever white
+// space may, and the last member of an object or element of an array may be
+// followed by a comma. [Value.Standardize] converts such a document to
+// standard JSON, to be decoded by package json.
+package jsonedit
+
+import (
+	"bytes"
+
+	json "github.com/crunk1/gojson"
+)
+
+// A Value is a JSON value together with the white space and comments
+// on either side of it.
+type Value struct {
+	Before []byte // white space and comments before the value
+	Node   Node   // the value itself
+	After  []byte // white space and comments after the value
+}
+
+// A Node is a JSON value without the white space around it: an [*Object],
+// an [*Array], or a [Literal].
+type Node interface {
+	appendTo(b []byte) []byte
+}
+
+// A Literal is a string, number, true, false, or null, as written in the
+// document.
+type Literal []byte
+
+// An Object is a JSON object.
+type Object struct {
+	Members []Member
+
+	// Comma records whether the last member is followed by a comma.
+	Comma bool
+
+	// End holds the white space and comments after the opening brace of
+	// an empty object, or after the comma that follows the last member;
+	// otherwise those before the closing brace are the After of the last
+	// member's Value, and End is empty.
+	End []byte
+}
+
+// A Member is a member of an [Object]. The Before and After of its Name
+// surround the key, and those of its Value the value, on either side of
+// the colon.
+type Member struct {
+	Name  Value // a Literal string
+	Value Value
+}
+
+// An Array is a JSON array.
+type Array struct {
+	Elements []Value
+
+	// Comma and End are as for an Object.
+	Comma bool
+	End   []byte
+}
+
+// Key returns the decoded key of the member.
+func (m *Member) Key() string {
+	var s string
+	lit, _ := m.Name.Node.(Literal)
+	json.Unmarshal(lit, &s)
+	return s
+}
+
+// Parse parses the document b. The Value refers to b, which must not be
+// modified while it is in use.
+func Parse(b []byte) (*Value, error) {
+	p := parser{data: b}
+	v, err := p.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.i < len(b) {
+		return nil, p.errorf("invalid character %q after top-level value", b[p.i])
+	}
+	return &v, nil
+}
+
+// Bytes returns the document, as parsed and then edited.
+func (v *Value) Bytes() []byte {
+	return v.appendTo(nil)
+}
+
+// String returns the document as a string.
+func (v *Value) String() string {
+	return string(v.Bytes())
+}
+
+func (v *Value) appendTo(b []byte) []byte {
+	b = append(b, v.Before...)
+	b = v.Node.appendTo(b)
+	return append(b, v.After...)
+}
+
+func (lit Literal) appendTo(b []byte) []byte {
+	return append(b, lit...)
+}
+
+func (o *Object) appendTo(b []byte) []byte {
+	b = append(b, '{')
+	for i := range o.Members {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = o.Members[i].Name.appendTo(b)
+		b = append(b, ':')
+		b = o.Members[i].Value.appendTo(b)
+	}
+	if o.Comma {
+		b = append(b, ',')
+	}
+	b = append(b, o.End...)
+	return append(b, '}')
+}
+
+func (a *Array) appendTo(b []byte) []byte {
+	b = append(b, '[')
+	for i := range a.Elements {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = a.Elements[i].appendTo(b)
+	}
+	if a.Comma {
+		b = append(b, ',')
+	}
+	b = append(b, a.End...)
+	return append(b, ']')
+}
+
+// Standardize removes the comments and trailing commas from the document,
+// so that it is standard JSON. Comments are replaced by white space that
+// keeps the lines of the document where they were.
+func (v *Value) Standardize() {
+	v.Before = standardSpace(v.Before)
+	v.After = standardSpace(v.After)
+	switch n := v.Node.(type) {
+	case *Object:
+		for i := range n.Members {
+			n.Members[i].Name.Before = standardSpace(n.Members[i].Name.Before)
+			n.Members[i].Name.After = standardSpace(n.Members[i].Name.After)
+			n.Members[i].Value.Standardize()
+		}
+		n.Comma, n.End = false, standardSpace(n.End)
+	case *Array:
+		for i := range n.Elements {
+			n.Elements[i].Standardize()
+		}
+		n.Comma, n.End = false, standardSpace(n.End)
+	}
+}
+
+// standardSpace returns the white space and comments b with each comment
+// replaced by the newlines in it, or b itself if it has no comments.
+func standardSpace(b []byte) []byte {
+	if bytes.IndexByte(b, '/') < 0 {
+		return b
+	}
+	var out []byte
+	for i := 0; i < len(b); {
+		switch {
+		case b[i] != '/':
+			out = append(out, b[i])
+			i++
+		case b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+		default:
+			end := i + 2 + bytes.Index(b[i+2:], []byte("*/")) + 2
+			for _, c := range b[i:end] {
+				if c == '\n' {
+					out = append(out, c)
+				}
+			}
+			i = end
+		}
+	}
+	return out
+}
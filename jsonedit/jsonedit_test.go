@@ -0,0 +1,235 @@
+package jsonedit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	json "github.com/crunk1/gojson"
+)
+
+const config = `// Server configuration.
+{
+	"name": "api", // the service name
+	/* Ports to listen on. */
+	"ports": [
+		8080,
+		8443, // TLS
+	],
+	"limits": {"rps": 1.50e2, "burst": 10},
+	"path\/esc": "\u00e9"
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	for _, in := range []string{
+		config,
+		` 1 `,
+		`""`,
+		`{}`,
+		"[\n]",
+		`{"a":[1,{"b":null},[]],"c":true}`,
+		"[1,2,/**/]",
+		"{\"a\":1 // x\n}",
+		"// only a comment\nnull",
+	} {
+		v, err := Parse([]byte(in))
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", in, err)
+			continue
+		}
+		if got := v.String(); got != in {
+			t.Errorf("Parse(%q).String():\n\tgot:  %q\n\twant: %q", in, got, in)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`{`,
+		`[1 2]`,
+		`{"a" 1}`,
+		`{"a":1,,}`,
+		`{1:2}`,
+		`[,]`,
+		`tru`,
+		`"abc`,
+		`01`,
+		`1 2`,
+		`/* open`,
+		`[1]]`,
+	} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("Parse(%q): got nil error, want error", in)
+		}
+	}
+	if _, err := Parse([]byte(strings.Repeat("[", maxNestingDepth+1))); err == nil || !strings.Contains(err.Error(), "max depth") {
+		t.Errorf("Parse of deep document: got %v, want max depth error", err)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		edit func(v *Value) error
+		want string
+	}{{
+		name: "replace",
+		in:   config,
+		edit: func(v *Value) error { return v.Set("web", "name") },
+		want: strings.Replace(config, `"api"`, `"web"`, 1),
+	}, {
+		name: "replace nested",
+		in:   config,
+		edit: func(v *Value) error { return v.Set(9443, "ports", 1) },
+		want: strings.Replace(config, "8443", "9443", 1),
+	}, {
+		name: "replace escaped key",
+		in:   config,
+		edit: func(v *Value) error { return v.Set(map[string]int{"x": 1}, "path/esc") },
+		want: strings.Replace(config, `"\u00e9"`, `{"x":1}`, 1),
+	}, {
+		name: "replace root",
+		in:   " // c\n1\n",
+		edit: func(v *Value) error { return v.Set([]int{2}) },
+		want: " // c\n[2]\n",
+	}, {
+		name: "append with trailing comma",
+		in:   config,
+		edit: func(v *Value) error { return v.Set(9000, "ports", 2) },
+		want: strings.Replace(config, "8443, // TLS\n", "8443, // TLS\n\t\t9000,\n", 1),
+	}, {
+		name: "add member",
+		in:   config,
+		edit: func(v *Value) error { return v.Set(false, "debug") },
+		want: strings.Replace(config, "\"\\u00e9\"\n}", "\"\\u00e9\",\n\t\"debug\": false\n}", 1),
+	}, {
+		name: "add member inline",
+		in:   `{"a": 1}`,
+		edit: func(v *Value) error { return v.Set(2, "b") },
+		want: `{"a": 1,"b": 2}`,
+	}, {
+		name: "add member after comment",
+		in:   "{\n  \"a\": 1 // one\n}",
+		edit: func(v *Value) error { return v.Set(2, "b") },
+		want: "{\n  \"a\": 1, // one\n  \"b\": 2\n}",
+	}, {
+		name: "add to empty",
+		in:   `{ }`,
+		edit: func(v *Value) error { return v.Set("<", "a") },
+		want: `{"a":"\u003c" }`,
+	}, {
+		name: "append to empty",
+		in:   `[]`,
+		edit: func(v *Value) error { return v.Set(1, 0) },
+		want: `[1]`,
+	}, {
+		name: "delete first",
+		in:   config,
+		edit: func(v *Value) error { return v.Delete("name") },
+		want: strings.Replace(config, "\n\t\"name\": \"api\",", "", 1),
+	}, {
+		name: "delete last",
+		in:   config,
+		edit: func(v *Value) error { return v.Delete("path/esc") },
+		want: strings.Replace(config, ",\n\t\"path\\/esc\": \"\\u00e9\"", "", 1),
+	}, {
+		name: "delete last with trailing comma",
+		in:   config,
+		edit: func(v *Value) error { return v.Delete("ports", 1) },
+		want: strings.Replace(config, "\n\t\t8443,", "", 1),
+	}, {
+		name: "delete only",
+		in:   "[\n  1\n]",
+		edit: func(v *Value) error { return v.Delete(0) },
+		want: "[\n]",
+	}, {
+		name: "standardize",
+		in:   config,
+		edit: func(v *Value) error { v.Standardize(); return nil },
+		want: "\n{\n\t\"name\": \"api\", \n\t\n\t\"ports\": [\n\t\t8080,\n\t\t8443 \n\t],\n\t\"limits\": {\"rps\": 1.50e2, \"burst\": 10},\n\t\"path\\/esc\": \"\\u00e9\"\n}\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Parse([]byte(tt.in))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if err := tt.edit(v); err != nil {
+				t.Fatalf("edit error: %v", err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("edited:\n\tgot:  %q\n\twant: %q", got, tt.want)
+			}
+			if _, err := Parse(v.Bytes()); err != nil {
+				t.Errorf("Parse of edited document error: %v", err)
+			}
+		})
+	}
+}
+
+func TestStandardizeValid(t *testing.T) {
+	v, err := Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	v.Standardize()
+	var got struct {
+		Name  string `json:"name"`
+		Ports []int  `json:"ports"`
+	}
+	if err := json.Unmarshal(v.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got.Name != "api" || len(got.Ports) != 2 {
+		t.Errorf("Unmarshal: got %+v", got)
+	}
+}
+
+func TestGet(t *testing.T) {
+	v, err := Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	got, err := v.Get("limits", "rps")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if lit, ok := got.Node.(Literal); !ok || string(lit) != "1.50e2" {
+		t.Errorf("Get: got %q, want 1.50e2", got.Node)
+	}
+
+	tests := []struct {
+		path []any
+		err  string
+	}{
+		{[]any{"missing"}, `json: path not found: "/missing"`},
+		{[]any{"ports", 2}, `json: path not found: "/ports/2"`},
+		{[]any{"ports", "a"}, `jsonedit: value at "/ports" is not an object`},
+		{[]any{"name", 0}, `jsonedit: value at "/name" is not an array`},
+		{[]any{1.5}, `jsonedit: invalid path element of type float64`},
+	}
+	for _, tt := range tests {
+		_, err := v.Get(tt.path...)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("Get(%v) error:\n\tgot:  %v\n\twant: %s", tt.path, err, tt.err)
+		}
+		if err := v.Delete(tt.path...); err == nil || err.Error() != tt.err {
+			t.Errorf("Delete(%v) error:\n\tgot:  %v\n\twant: %s", tt.path, err, tt.err)
+		}
+	}
+	if err := v.Set(1, "ports", 3); !errors.Is(err, json.ErrPathNotFound) {
+		t.Errorf("Set past end error: got %v, want ErrPathNotFound", err)
+	}
+	if err := v.Set(1, "a", "b"); !errors.Is(err, json.ErrPathNotFound) {
+		t.Errorf("Set in missing object error: got %v, want ErrPathNotFound", err)
+	}
+	if err := v.Delete(); err == nil {
+		t.Error("Delete of document: got nil error, want error")
+	}
+	if got := v.String(); got != config {
+		t.Errorf("failed edits changed the document:\n\tgot:  %q\n\twant: %q", got, config)
+	}
+}
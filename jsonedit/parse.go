@@ -0,0 +1,182 @@
+package jsonedit
+
+import (
+	"fmt"
+
+	json "github.com/crunk1/gojson"
+)
+
+// maxNestingDepth bounds the nesting of objects and arrays, as in package
+// json, so that parsing a hostile document does not exhaust the stack.
+const maxNestingDepth = 10000
+
+type parser struct {
+	data []byte
+	i    int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("jsonedit: "+format+" at offset %d", append(args, p.i)...)
+}
+
+// value parses a value and the white space and comments around it.
+func (p *parser) value(depth int) (Value, error) {
+	var v Value
+	var err error
+	if v.Before, err = p.space(); err != nil {
+		return v, err
+	}
+	if v.Node, err = p.node(depth); err != nil {
+		return v, err
+	}
+	v.After, err = p.space()
+	return v, err
+}
+
+// space parses white space and comments.
+func (p *parser) space() ([]byte, error) {
+	start := p.i
+	for p.i < len(p.data) {
+		switch c := p.data[p.i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			p.i++
+		case c == '/' && p.i+1 < len(p.data) && p.data[p.i+1] == '/':
+			for p.i < len(p.data) && p.data[p.i] != '\n' {
+				p.i++
+			}
+		case c == '/' && p.i+1 < len(p.data) && p.data[p.i+1] == '*':
+			end := -1
+			for j := p.i + 2; j+1 < len(p.data); j++ {
+				if p.data[j] == '*' && p.data[j+1] == '/' {
+					end = j + 2
+					break
+				}
+			}
+			if end < 0 {
+				return nil, p.errorf("unterminated comment")
+			}
+			p.i = end
+		default:
+			return p.data[start:p.i:p.i], nil
+		}
+	}
+	return p.data[start:p.i:p.i], nil
+}
+
+func (p *parser) node(depth int) (Node, error) {
+	if p.i == len(p.data) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch p.data[p.i] {
+	case '{':
+		if depth == maxNestingDepth {
+			return nil, p.errorf("exceeded max depth")
+		}
+		return p.object(depth + 1)
+	case '[':
+		if depth == maxNestingDepth {
+			return nil, p.errorf("exceeded max depth")
+		}
+		return p.array(depth + 1)
+	}
+	return p.literal()
+}
+
+func (p *parser) literal() (Literal, error) {
+	start := p.i
+	if p.data[p.i] == '"' {
+		for p.i++; p.i < len(p.data) && p.data[p.i] != '"'; p.i++ {
+			if p.data[p.i] == '\\' {
+				p.i++
+			}
+		}
+		p.i++
+	} else {
+		for p.i < len(p.data) && isLiteralByte(p.data[p.i]) {
+			p.i++
+		}
+	}
+	p.i = min(p.i, len(p.data))
+	if lit := p.data[start:p.i:p.i]; len(lit) > 0 && json.Valid(lit) {
+		return lit, nil
+	}
+	p.i = start
+	return nil, p.errorf("invalid value starting with %q", p.data[start])
+}
+
+func isLiteralByte(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '-' || c == '+' || c == '.'
+}
+
+func (p *parser) object(depth int) (*Object, error) {
+	o := new(Object)
+	p.i++
+	for {
+		space, err := p.space()
+		if err != nil {
+			return nil, err
+		}
+		if p.i < len(p.data) && p.data[p.i] == '}' {
+			o.End = space
+			p.i++
+			return o, nil
+		}
+		if len(o.Members) > 0 && !o.Comma {
+			return nil, p.errorf("expected comma or '}' after object member")
+		}
+		if p.i == len(p.data) || p.data[p.i] != '"' {
+			return nil, p.errorf("expected object key")
+		}
+		var m Member
+		m.Name.Before = space
+		if m.Name.Node, err = p.literal(); err != nil {
+			return nil, err
+		}
+		if m.Name.After, err = p.space(); err != nil {
+			return nil, err
+		}
+		if p.i == len(p.data) || p.data[p.i] != ':' {
+			return nil, p.errorf("expected colon after object key")
+		}
+		p.i++
+		if m.Value, err = p.value(depth); err != nil {
+			return nil, err
+		}
+		o.Members = append(o.Members, m)
+		o.Comma = p.i < len(p.data) && p.data[p.i] == ','
+		if o.Comma {
+			p.i++
+		}
+	}
+}
+
+func (p *parser) array(depth int) (*Array, error) {
+	a := new(Array)
+	p.i++
+	for {
+		space, err := p.space()
+		if err != nil {
+			return nil, err
+		}
+		if p.i < len(p.data) && p.data[p.i] == ']' {
+			a.End = space
+			p.i++
+			return a, nil
+		}
+		if len(a.Elements) > 0 && !a.Comma {
+			return nil, p.errorf("expected comma or ']' after array element")
+		}
+		v := Value{Before: space}
+		if v.Node, err = p.node(depth); err != nil {
+			return nil, err
+		}
+		if v.After, err = p.space(); err != nil {
+			return nil, err
+		}
+		a.Elements = append(a.Elements, v)
+		a.Comma = p.i < len(p.data) && p.data[p.i] == ','
+		if a.Comma {
+			p.i++
+		}
+	}
+}
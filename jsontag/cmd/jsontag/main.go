@@ -0,0 +1,15 @@
+// The jsontag command runs the jsontag analyzer, which checks the json
+// struct tags understood by github.com/crunk1/gojson.
+//
+// It can be run on its own or by go vet:
+//
+//	go install github.com/crunk1/gojson/jsontag/cmd/jsontag@latest
+//	go vet -vettool=$(which jsontag) ./...
+package main
+
+import (
+	"github.com/crunk1/gojson/jsontag"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(jsontag.Analyzer) }
@@ -0,0 +1,315 @@
+// Package jsontag defines an Analyzer that checks the json struct tags
+// understood by github.com/crunk1/gojson.
+//
+// The package reports at run time, when a type is first encoded or
+// decoded, struct tags whose options cannot work together or do not apply
+// to the field. This analyzer finds the same mistakes statically, so that
+// they are caught before the type is ever used:
+//
+//   - optional or nullable combined with omitempty, omitdeepempty, or omitnil
+//   - optional or nullable fields without enough pointer indirection
+//     (one level for each of the two options)
+//   - the string option on fields it does not apply to
+//   - the enum option on fields that are not strings
+//   - several fields of a struct with the same JSON name, of which
+//     all but a tagged one are silently ignored
+//
+// The naming style and default options set by an embedded json.Options
+// field are applied to each field, as the package applies them.
+package jsontag
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check json struct tags for github.com/crunk1/gojson
+
+The jsontag analyzer reports struct tags that github.com/crunk1/gojson
+rejects when the type is encoded or decoded: optional or nullable with an
+omit option or without enough pointer indirection, string and enum options
+on fields they do not apply to, and duplicate JSON names.`
+
+// Analyzer checks json struct tags.
+var Analyzer = &analysis.Analyzer{
+	Name:     "jsontag",
+	Doc:      doc,
+	URL:      "https://pkg.go.dev/github.com/crunk1/gojson/jsontag",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		checkStruct(pass, n.(*ast.StructType))
+	})
+	return nil, nil
+}
+
+func checkStruct(pass *analysis.Pass, st *ast.StructType) {
+	// An embedded json.Options field sets the naming style of the fields
+	// whose tags give no name, and the default options of the fields whose
+	// tags have no comma.
+	var naming, defaults string
+	hasDefaults := false
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 && isOptionsMarker(pass.TypesInfo.TypeOf(f.Type)) {
+			naming, defaults, _ = strings.Cut(jsonTag(f), ",")
+			hasDefaults = true
+		}
+	}
+
+	seen := map[string]string{} // JSON name to the Go name of its field
+	for _, f := range st.Fields.List {
+		tag := jsonTag(f)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if hasDefaults && !strings.Contains(tag, ",") {
+			opts = defaults
+		}
+		typ := pass.TypesInfo.TypeOf(f.Type)
+		if typ == nil || len(f.Names) == 0 && isOptionsMarker(typ) {
+			continue
+		}
+
+		var goNames []string
+		if len(f.Names) == 0 {
+			// Embedded field. Untagged embedded structs contribute their
+			// own fields, which are checked where the struct is declared.
+			if name == "" && isStruct(typ) {
+				continue
+			}
+			goNames = []string{embeddedName(typ)}
+		}
+		for _, id := range f.Names {
+			goNames = append(goNames, id.Name)
+		}
+		for _, goName := range goNames {
+			if !ast.IsExported(goName) {
+				continue
+			}
+			jsonName := name
+			if jsonName == "" {
+				jsonName = applyNaming(naming, goName)
+			}
+			if prev, ok := seen[jsonName]; ok {
+				pass.Reportf(f.Pos(), "field %s has JSON name %q, also used by field %s", goName, jsonName, prev)
+			} else {
+				seen[jsonName] = goName
+			}
+			checkOptions(pass, f, jsonName, typ, tagOptions(opts))
+		}
+	}
+}
+
+// jsonTag returns the json tag of the field.
+func jsonTag(f *ast.Field) string {
+	if f.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(f.Tag.Value)
+	return reflect.StructTag(tag).Get("json")
+}
+
+// isOptionsMarker reports whether t is the Options type of package json.
+func isOptionsMarker(t types.Type) bool {
+	n, ok := types.Unalias(t).(*types.Named)
+	return ok && n.Obj().Name() == "Options" && n.Obj().Pkg() != nil &&
+		n.Obj().Pkg().Path() == "github.com/crunk1/gojson"
+}
+
+func checkOptions(pass *analysis.Pass, f *ast.Field, name string, typ types.Type, opts tagOptions) {
+	optional, nullable := opts.contains("optional"), opts.contains("nullable")
+	for _, omit := range []string{"omitempty", "omitdeepempty", "omitnil"} {
+		if !opts.contains(omit) {
+			continue
+		}
+		if optional {
+			pass.Reportf(f.Pos(), "field %q cannot have both %s and optional tags", name, omit)
+		}
+		if nullable {
+			pass.Reportf(f.Pos(), "field %q cannot have both %s and nullable tags", name, omit)
+		}
+	}
+
+	required := 0
+	if optional {
+		required++
+	}
+	if nullable {
+		required++
+	}
+	t := typ
+	for n := required; n > 0 && isPointer(t); n-- {
+		t = t.Underlying().(*types.Pointer).Elem()
+	}
+	if pointers(typ) < required {
+		switch {
+		case optional && nullable:
+			pass.Reportf(f.Pos(), "optional nullable field %q requires 2+ levels of indirection, type = %q", name, typ)
+		case optional:
+			pass.Reportf(f.Pos(), "optional field %q requires 1+ levels of indirection, type = %q", name, typ)
+		default:
+			pass.Reportf(f.Pos(), "nullable field %q requires 1+ levels of indirection, type = %q", name, typ)
+		}
+	}
+
+	if opts.contains("string") {
+		qt := t
+		if _, ok := types.Unalias(qt).(*types.Pointer); ok {
+			qt = qt.Underlying().(*types.Pointer).Elem()
+		}
+		if !isQuotable(qt) {
+			pass.Reportf(f.Pos(), "string option is ignored by field %q of type %q; it only applies to strings, floating point, integer, and boolean fields", name, typ)
+		}
+	}
+	if _, ok := opts.lookup("enum"); ok {
+		et := typ
+		for isPointer(et) {
+			et = et.Underlying().(*types.Pointer).Elem()
+		}
+		if b, ok := et.Underlying().(*types.Basic); !ok || b.Info()&types.IsString == 0 {
+			pass.Reportf(f.Pos(), "enum field %q requires a string type, type = %q", name, typ)
+		}
+	}
+}
+
+// isQuotable reports whether the string option applies to type t.
+func isQuotable(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok || b.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) == 0 ||
+		b.Info()&types.IsComplex != 0 {
+		return false
+	}
+	return !implementsMarshaler(t)
+}
+
+// implementsMarshaler reports whether t or *t has a MarshalJSON,
+// MarshalText, or AppendText method.
+func implementsMarshaler(t types.Type) bool {
+	ms := types.NewMethodSet(types.NewPointer(t))
+	for _, name := range []string{"MarshalJSON", "MarshalText", "AppendText"} {
+		if ms.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func isPointer(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Pointer)
+	return ok
+}
+
+func pointers(t types.Type) int {
+	n := 0
+	for ; isPointer(t); n++ {
+		t = t.Underlying().(*types.Pointer).Elem()
+	}
+	return n
+}
+
+func isStruct(t types.Type) bool {
+	if p, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// embeddedName returns the field name of an embedded field of type t.
+func embeddedName(t types.Type) string {
+	if p, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		return t.Obj().Name()
+	case *types.Basic:
+		return t.Name()
+	}
+	return ""
+}
+
+// applyNaming converts the Go name of a field to the naming style,
+// as in package json.
+func applyNaming(naming, name string) string {
+	if naming == "" {
+		return name
+	}
+	words := splitWords(name)
+	switch naming {
+	case "snake_case":
+		return strings.ToLower(strings.Join(words, "_"))
+	case "kebab-case":
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+	// camelCase
+	if len(words) > 0 {
+		words[0] = strings.ToLower(words[0])
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits a Go name into the words of a naming style,
+// as in package json.
+func splitWords(name string) []string {
+	var words []string
+	start := 0
+	var prev rune
+	for i, r := range name {
+		if r == '_' {
+			if i > start {
+				words = append(words, name[start:i])
+			}
+			start, prev = i+1, r
+			continue
+		}
+		if i > start && unicode.IsUpper(r) {
+			next, _ := utf8.DecodeRuneInString(name[i+utf8.RuneLen(r):])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && unicode.IsLower(next) {
+				words = append(words, name[start:i])
+				start = i
+			}
+		}
+		prev = r
+	}
+	if start < len(name) {
+		words = append(words, name[start:])
+	}
+	return words
+}
+
+// tagOptions is the part of a json tag after the name,
+// as in package json.
+type tagOptions string
+
+func (o tagOptions) contains(name string) bool {
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (o tagOptions) lookup(name string) (string, bool) {
+	for _, opt := range strings.Split(string(o), ",") {
+		if i := strings.IndexAny(opt, "=:"); i >= 0 && opt[:i] == name {
+			return opt[i+1:], true
+		}
+	}
+	return "", false
+}
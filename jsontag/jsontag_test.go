@@ -0,0 +1,12 @@
+package jsontag_test
+
+import (
+	"testing"
+
+	"github.com/crunk1/gojson/jsontag"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func Test(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), jsontag.Analyzer, "a")
+}
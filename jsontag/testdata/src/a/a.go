@@ -0,0 +1,67 @@
+package a
+
+import (
+	"time"
+
+	json "github.com/crunk1/gojson"
+)
+
+type Enum string
+
+type Text int
+
+func (Text) MarshalText() ([]byte, error) { return nil, nil }
+
+type IntPtr *int
+
+type Base struct {
+	ID int `json:"id"`
+}
+
+type good struct {
+	Base
+	A   *int     `json:"a,optional"`
+	B   **int    `json:"b,optional,nullable"`
+	C   IntPtr   `json:"c,nullable"`
+	D   *int     `json:"d,omitempty"`
+	E   *int     `json:"e,optional,string"`
+	F   Enum     `json:"f,enum=x|y"`
+	G   *string  `json:"g,enum=x|y"`
+	H   bool     `json:",string"`
+	I   int      `json:"-"`
+	J   int      `json:"-,"`
+	K   []string `json:"k,omitnil"`
+	low int      `json:"a"`
+}
+
+type bad struct {
+	A    *int      `json:"a,optional,omitempty"` // want `field "a" cannot have both omitempty and optional tags`
+	B    *int      `json:"b,nullable,omitnil"`   // want `field "b" cannot have both omitnil and nullable tags`
+	C    int       `json:"c,optional"`           // want `optional field "c" requires 1\+ levels of indirection, type = "int"`
+	D    *int      `json:"d,optional,nullable"`  // want `optional nullable field "d" requires 2\+ levels of indirection, type = "\*int"`
+	E    []int     `json:"e,nullable"`           // want `nullable field "e" requires 1\+ levels of indirection, type = "\[\]int"`
+	F    time.Time `json:"f,string"`             // want `string option is ignored by field "f" of type "time.Time"`
+	G    Text      `json:"g,string"`             // want `string option is ignored by field "g" of type "a.Text"`
+	H    **int     `json:"h,string"`             // want `string option is ignored by field "h" of type "\*\*int"`
+	I    int       `json:"i,enum=1|2"`           // want `enum field "i" requires a string type, type = "int"`
+	J    string    `json:"j"`
+	K    string    `json:"j"` // want `field K has JSON name "j", also used by field J`
+	L, M string    `json:"m"` // want `field M has JSON name "m", also used by field L`
+}
+
+type defaults struct {
+	json.Options `json:"snake_case,optional"`
+
+	A *int
+	B int    `json:"b"` // want `optional field "b" requires 1\+ levels of indirection, type = "int"`
+	C int    `json:"c,"`
+	D string `json:",string"`
+}
+
+type naming struct {
+	json.Options `json:"snake_case"`
+
+	UserID   int
+	User_ID  int // want `field User_ID has JSON name "user_id", also used by field UserID`
+	LegacyID int `json:"UserID"`
+}
@@ -0,0 +1,4 @@
+// Package json is a stub of github.com/crunk1/gojson for the tests.
+package json
+
+type Options struct{}
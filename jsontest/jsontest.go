@@ -0,0 +1,159 @@
+// Package jsontest provides helpers for tests of code that produces JSON.
+package jsontest
+
+import (
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	json "github.com/crunk1/gojson"
+)
+
+// Equal reports whether want and got hold the same JSON value, and if not,
+// reports the differences through t.Errorf, one line per differing path.
+//
+// A string, []byte, or [json.RawMessage] argument is taken to be JSON
+// text; any other argument is first encoded with [json.Marshal].
+// Values are compared semantically: the order of object members and
+// insignificant white space are ignored, and numbers are equal if they
+// have the same value, so that 1, 1.0, and 1e0 are all equal.
+//
+// Paths are reported as JSON Pointers (RFC 6901).
+func Equal(t testing.TB, want, got any) bool {
+	t.Helper()
+	w, err := decode(want)
+	if err != nil {
+		t.Errorf("jsontest.Equal: want: %v", err)
+		return false
+	}
+	g, err := decode(got)
+	if err != nil {
+		t.Errorf("jsontest.Equal: got: %v", err)
+		return false
+	}
+	lines := diff(nil, "", w, g)
+	if len(lines) == 0 {
+		return true
+	}
+	t.Errorf("JSON mismatch:\n\t%s", strings.Join(lines, "\n\t"))
+	return false
+}
+
+// decode decodes v, as interpreted by Equal, into an interface value.
+func decode(v any) (any, error) {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var x any
+	if err := (json.UnmarshalOptions{UseNumber: true}).Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// diff appends to lines a description of each difference between want and
+// got, at the JSON Pointer path.
+func diff(lines []string, path string, want, got any) []string {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			kpath := path + "/" + escapeToken(k)
+			wv, inW := w[k]
+			gv, inG := g[k]
+			switch {
+			case !inG:
+				lines = append(lines, pathName(kpath)+": missing, want "+encode(wv))
+			case !inW:
+				lines = append(lines, pathName(kpath)+": unexpected, got "+encode(gv))
+			default:
+				lines = diff(lines, kpath, wv, gv)
+			}
+		}
+		return lines
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(w), len(g)); i++ {
+			ipath := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(g):
+				lines = append(lines, pathName(ipath)+": missing, want "+encode(w[i]))
+			case i >= len(w):
+				lines = append(lines, pathName(ipath)+": unexpected, got "+encode(g[i]))
+			default:
+				lines = diff(lines, ipath, w[i], g[i])
+			}
+		}
+		return lines
+	case json.Number:
+		if g, ok := got.(json.Number); ok && numbersEqual(w, g) {
+			return lines
+		}
+	default:
+		if want == got {
+			return lines
+		}
+	}
+	return append(lines, pathName(path)+": got "+encode(got)+", want "+encode(want))
+}
+
+// numbersEqual reports whether a and b have the same numeric value.
+func numbersEqual(a, b json.Number) bool {
+	if a == b {
+		return true
+	}
+	x, ok1 := new(big.Rat).SetString(string(a))
+	y, ok2 := new(big.Rat).SetString(string(b))
+	return ok1 && ok2 && x.Cmp(y) == 0
+}
+
+// encode returns the compact encoding of a decoded value.
+func encode(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+	return string(b)
+}
+
+func pathName(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+var tokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapeToken(tok string) string {
+	return tokenEscaper.Replace(tok)
+}
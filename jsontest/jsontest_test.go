@@ -0,0 +1,101 @@
+package jsontest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recorder is a testing.TB that records the messages passed to Errorf.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestEqual(t *testing.T) {
+	type T struct {
+		A int      `json:"a"`
+		B []string `json:"b"`
+	}
+	tests := []struct {
+		name      string
+		want, got any
+		diff      []string // nil if equal
+	}{{
+		name: "Identical",
+		want: `{"a":1}`,
+		got:  []byte(`{"a":1}`),
+	}, {
+		name: "OrderAndSpace",
+		want: `{"a": 1, "b": ["x"]}`,
+		got:  `{"b":["x"],"a":1}`,
+	}, {
+		name: "SameNumber",
+		want: `[1, 100, 12345678901234567890]`,
+		got:  `[1.0, 1e2, 12345678901234567890.0]`,
+	}, {
+		name: "GoValue",
+		want: `{"a":1,"b":null}`,
+		got:  T{A: 1},
+	}, {
+		name: "ChangedMember",
+		want: `{"a":1,"b":["x","y"]}`,
+		got:  T{A: 2, B: []string{"x", "z"}},
+		diff: []string{`/a: got 2, want 1`, `/b/1: got "z", want "y"`},
+	}, {
+		name: "MissingAndUnexpected",
+		want: `{"a/b":1,"c":[1,2]}`,
+		got:  `{"c":[1],"d":true}`,
+		diff: []string{`/a~1b: missing, want 1`, `/c/1: missing, want 2`, `/d: unexpected, got true`},
+	}, {
+		name: "KindMismatch",
+		want: `{"a":[1]}`,
+		got:  `{"a":{"0":1}}`,
+		diff: []string{`/a: got {"0":1}, want [1]`},
+	}, {
+		name: "Root",
+		want: `"x"`,
+		got:  `null`,
+		diff: []string{`(root): got null, want "x"`},
+	}, {
+		name: "ExtraElement",
+		want: `[]`,
+		got:  `[{"a":1}]`,
+		diff: []string{`/0: unexpected, got {"a":1}`},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			ok := Equal(r, tt.want, tt.got)
+			if ok != (tt.diff == nil) {
+				t.Errorf("Equal = %v, want %v", ok, tt.diff == nil)
+			}
+			if tt.diff == nil {
+				if len(r.errors) > 0 {
+					t.Errorf("Equal reported errors: %q", r.errors)
+				}
+				return
+			}
+			want := "JSON mismatch:\n\t" + strings.Join(tt.diff, "\n\t")
+			if len(r.errors) != 1 || r.errors[0] != want {
+				t.Errorf("Equal errors:\n\tgot:  %q\n\twant: %q", r.errors, want)
+			}
+		})
+	}
+}
+
+func TestEqualInvalid(t *testing.T) {
+	r := &recorder{TB: t}
+	if Equal(r, `{"a":1}`, `{"a":`) {
+		t.Fatalf("Equal = true for invalid JSON")
+	}
+	if len(r.errors) != 1 || !strings.HasPrefix(r.errors[0], "jsontest.Equal: got: ") {
+		t.Errorf("Equal errors: %q, want one about got", r.errors)
+	}
+}
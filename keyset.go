@@ -0,0 +1,109 @@
+package json
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+// A DuplicateKeyFilter has the duplicate key policies other than
+// [DuplicateKeysLastWins] remember the keys of large objects in a Bloom
+// filter, a probabilistic set of fixed size, in place of the exact set of
+// keys they otherwise keep for each object being decoded, so that objects
+// with millions of keys, such as those read from a stream, are checked in
+// bounded memory. It is set with [UnmarshalOptions.DuplicateKeyFilter] and
+// [Decoder.SetDuplicateKeyFilter].
+//
+// The first thousand keys of each object are still remembered exactly.
+// Past them, the keys are moved to a filter of about
+// 1.44*log2(1/FalsePositiveRate) bits for each of Keys keys, whatever the
+// length of the keys: about 3.6 MiB with the defaults. The filter never
+// misses a duplicate, but it may take a key that has not been seen for
+// one, with a probability of at most FalsePositiveRate while the object
+// has no more than Keys keys, and more after that. Such a key is handled
+// as a duplicate: its member is skipped with [DuplicateKeysFirstWins],
+// and reported with [DuplicateKeysError].
+//
+// Objects decoded into interface values are checked against the maps
+// that hold them, and so always exactly.
+type DuplicateKeyFilter struct {
+	// Keys is the number of keys the filter is sized for.
+	// If it is not positive, it is 1<<20.
+	Keys int
+
+	// FalsePositiveRate is the probability that the filter takes a new key
+	// for a duplicate once it holds Keys keys. If it is not between 0
+	// and 1, it is 1e-6.
+	FalsePositiveRate float64
+}
+
+// exactKeys is the number of keys of an object that a keySet with a
+// filter remembers exactly.
+const exactKeys = 1000
+
+// A keySet records the keys of an object decoded so far, in a map or,
+// once there are more than exactKeys of them and a DuplicateKeyFilter is
+// in use, in a Bloom filter. The zero value is an empty set.
+type keySet struct {
+	exact  map[string]struct{}
+	bits   []uint64 // of the Bloom filter, if any
+	hashes int      // number of bits set for each key in the filter
+	seed   maphash.Seed
+}
+
+// add adds key to s and reports whether it was already there, or, once s
+// uses a filter, may have been.
+func (s *keySet) add(key string, f *DuplicateKeyFilter) bool {
+	if s.bits == nil {
+		if _, ok := s.exact[key]; ok {
+			return true
+		}
+		if f == nil || len(s.exact) < exactKeys {
+			if s.exact == nil {
+				s.exact = make(map[string]struct{})
+			}
+			s.exact[key] = struct{}{}
+			return false
+		}
+		s.initFilter(f)
+		for k := range s.exact {
+			s.filterAdd(k)
+		}
+		s.exact = nil
+	}
+	return s.filterAdd(key)
+}
+
+// initFilter allocates the Bloom filter of s as configured by f.
+func (s *keySet) initFilter(f *DuplicateKeyFilter) {
+	n, p := float64(f.Keys), f.FalsePositiveRate
+	if n <= 0 {
+		n = 1 << 20
+	}
+	if !(0 < p && p < 1) {
+		p = 1e-6
+	}
+	m := math.Ceil(-n * math.Log(p) / (math.Ln2 * math.Ln2))
+	s.bits = make([]uint64, (int(m)+63)/64)
+	s.hashes = max(1, int(math.Round(m/n*math.Ln2)))
+	s.seed = maphash.MakeSeed()
+}
+
+// filterAdd sets the bits of key in the filter of s and reports whether
+// they were all set already. The bits are chosen by double hashing, from
+// the two halves of one 64-bit hash.
+func (s *keySet) filterAdd(key string) bool {
+	h := maphash.String(s.seed, key)
+	h1, h2 := h, bits.RotateLeft64(h, 32)|1
+	m := uint64(len(s.bits)) * 64
+	found := true
+	for i := range s.hashes {
+		j := (h1 + uint64(i)*h2) % m
+		w, b := j/64, uint64(1)<<(j%64)
+		if s.bits[w]&b == 0 {
+			found = false
+			s.bits[w] |= b
+		}
+	}
+	return found
+}
@@ -0,0 +1,129 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestKeySet(t *testing.T) {
+	var exact keySet
+	for i := range 2 * exactKeys {
+		if exact.add(strconv.Itoa(i), nil) {
+			t.Fatalf("add(%d) without filter = true, want false", i)
+		}
+	}
+	if exact.bits != nil || len(exact.exact) != 2*exactKeys {
+		t.Errorf("keySet without filter has %d exact keys and %d filter words, want %d and 0", len(exact.exact), len(exact.bits), 2*exactKeys)
+	}
+
+	const n = 20000
+	f := &DuplicateKeyFilter{Keys: n, FalsePositiveRate: 0.01}
+	var s keySet
+	falsePositives := 0
+	for i := range n {
+		if s.add(strconv.Itoa(i), f) {
+			if i < exactKeys {
+				t.Fatalf("add(%d) of exact key = true, want false", i)
+			}
+			falsePositives++
+		}
+	}
+	if s.exact != nil || s.bits == nil {
+		t.Fatalf("keySet past %d keys has no filter", exactKeys)
+	}
+	if max := n / 50; falsePositives > max {
+		t.Errorf("%d false positives in %d keys, want at most %d", falsePositives, n, max)
+	}
+	for i := range n {
+		if !s.add(strconv.Itoa(i), f) {
+			t.Fatalf("add(%d) of duplicate = false, want true", i)
+		}
+	}
+	// A rate of 0.01 takes about 9.6 bits a key.
+	if got, want := len(s.bits)*64, n*96/10; got < want*95/100 || got > want*105/100 {
+		t.Errorf("filter has %d bits, want about %d", got, want)
+	}
+}
+
+func TestDuplicateKeyFilter(t *testing.T) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := range 3 * exactKeys {
+		fmt.Fprintf(&b, `"k%d":%d,`, i, i)
+	}
+	b.WriteString(`"k5":-1,"k2500":-1}`)
+	in := b.String()
+	filter := &DuplicateKeyFilter{FalsePositiveRate: 1e-12}
+
+	var got map[string]int
+	opts := UnmarshalOptions{DuplicateKeys: DuplicateKeysFirstWins, DuplicateKeyFilter: filter}
+	if err := opts.Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(got) != 3*exactKeys || got["k5"] != 5 || got["k2500"] != 2500 {
+		t.Errorf("Unmarshal: got %d keys, k5=%d, k2500=%d, want %d keys, k5=5, k2500=2500", len(got), got["k5"], got["k2500"], 3*exactKeys)
+	}
+
+	got = nil
+	opts.DuplicateKeys = DuplicateKeysError
+	err := opts.Unmarshal([]byte(in), &got)
+	var serr *SemanticError
+	if !errors.As(err, &serr) || !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("Unmarshal error: got %v, want SemanticError wrapping ErrDuplicateKey", err)
+	}
+	if want := int64(strings.Index(in, `"k5":-1`)); serr.Offset != want {
+		t.Errorf("Unmarshal error offset = %d, want %d", serr.Offset, want)
+	}
+}
+
+func TestTokenDuplicateKeys(t *testing.T) {
+	const in = `{"a":1,"b":{"a":2},"a":[3,{"a":4}],"c":4,"b":null}`
+	tests := []struct {
+		CaseName
+		policy DuplicateKeyPolicy
+		want   []any
+	}{{
+		CaseName: Name("last wins"),
+		policy:   DuplicateKeysLastWins,
+		want: []any{Delim('{'), "a", 1.0, "b", Delim('{'), "a", 2.0, Delim('}'),
+			"a", Delim('['), 3.0, Delim('{'), "a", 4.0, Delim('}'), Delim(']'), "c", 4.0, "b", nil, Delim('}')},
+	}, {
+		CaseName: Name("first wins"),
+		policy:   DuplicateKeysFirstWins,
+		want:     []any{Delim('{'), "a", 1.0, "b", Delim('{'), "a", 2.0, Delim('}'), "c", 4.0, Delim('}')},
+	}, {
+		CaseName: Name("error"),
+		policy:   DuplicateKeysError,
+		want:     []any{Delim('{'), "a", 1.0, "b", Delim('{'), "a", 2.0, Delim('}'), int64(19), "c", 4.0, int64(41), Delim('}')},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(in))
+			dec.SetDuplicateKeyPolicy(tt.policy)
+			var got []any
+			for {
+				tok, err := dec.Token()
+				if err == io.EOF {
+					break
+				}
+				var serr *SemanticError
+				if errors.As(err, &serr) && errors.Is(err, ErrDuplicateKey) {
+					got = append(got, serr.Offset)
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: Token error: %v", tt.Where, err)
+				}
+				got = append(got, tok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Token:\n\tgot:  %v\n\twant: %v", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DecodeAll reads JSON values from the input until its end and appends
+// each, decoded as by [Decoder.Decode], to the slice pointed to by v.
+// The values may be separated by white space, such as newlines in the
+// JSON Lines format, or simply concatenated.
+//
+// DecodeAll returns nil at the end of the input. If a value cannot be
+// decoded, DecodeAll returns the error, and the slice holds the values
+// decoded before it.
+func (dec *Decoder) DecodeAll(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	s := rv.Elem()
+	if s.Kind() != reflect.Slice {
+		return fmt.Errorf("json: DecodeAll of %T, which is not a pointer to a slice", v)
+	}
+	for {
+		n := s.Len()
+		if n == s.Cap() {
+			s.Grow(1)
+		}
+		s.SetLen(n + 1)
+		elem := s.Index(n)
+		elem.SetZero()
+		if err := dec.Decode(elem.Addr().Interface()); err != nil {
+			s.SetLen(n)
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// UnmarshalMany parses the JSON values in data, separated by white space
+// or concatenated, and returns them decoded as values of type T.
+// See [Decoder.DecodeAll] for the handling of errors.
+func UnmarshalMany[T any](data []byte) ([]T, error) {
+	var vs []T
+	err := NewDecoder(bytes.NewReader(data)).DecodeAll(&vs)
+	return vs, err
+}
@@ -0,0 +1,83 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeAll(t *testing.T) {
+	type Line struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	in := "{\"level\":\"info\",\"msg\":\"a\"}\n{\"level\":\"warn\",\"msg\":\"b\"}\r\n\n{\"msg\":\"c\"}{\"msg\":\"d\"}\n"
+	want := []Line{{"info", "a"}, {"warn", "b"}, {"", "c"}, {"", "d"}}
+
+	var got []Line
+	if err := NewDecoder(strings.NewReader(in)).DecodeAll(&got); err != nil {
+		t.Fatalf("DecodeAll error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeAll:\n\tgot:  %#v\n\twant: %#v", got, want)
+	}
+
+	// Values are appended, and do not inherit fields from stale elements.
+	got = append(make([]Line, 0, 8), Line{"x", "y"})
+	if err := NewDecoder(strings.NewReader(in)).DecodeAll(&got); err != nil {
+		t.Fatalf("DecodeAll error: %v", err)
+	}
+	if !reflect.DeepEqual(got[1:], want) || got[0] != (Line{"x", "y"}) {
+		t.Errorf("DecodeAll:\n\tgot:  %#v\n\twant: %#v", got, append([]Line{{"x", "y"}}, want...))
+	}
+}
+
+func TestDecodeAllError(t *testing.T) {
+	var got []int
+	err := NewDecoder(strings.NewReader(`1 2 "three" 4`)).DecodeAll(&got)
+	var ute *UnmarshalTypeError
+	if !errors.As(err, &ute) {
+		t.Errorf("DecodeAll error: got %v, want *UnmarshalTypeError", err)
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeAll: got %v, want %v", got, want)
+	}
+
+	got = nil
+	if err := NewDecoder(strings.NewReader(`1 [2`)).DecodeAll(&got); err != io.ErrUnexpectedEOF {
+		t.Errorf("DecodeAll error: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if want := []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeAll: got %v, want %v", got, want)
+	}
+
+	var iue *InvalidUnmarshalError
+	if err := NewDecoder(strings.NewReader(`1`)).DecodeAll(got); !errors.As(err, &iue) {
+		t.Errorf("DecodeAll error: got %v, want *InvalidUnmarshalError", err)
+	}
+	if err := NewDecoder(strings.NewReader(`1`)).DecodeAll(new(int)); err == nil {
+		t.Error("DecodeAll error: got nil, want error for a pointer to a non-slice")
+	}
+}
+
+func TestUnmarshalMany(t *testing.T) {
+	got, err := UnmarshalMany[map[string]any]([]byte(" {\"a\": 1}\n{}\n\n"))
+	if err != nil {
+		t.Fatalf("UnmarshalMany error: %v", err)
+	}
+	if want := []map[string]any{{"a": 1.0}, {}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalMany:\n\tgot:  %v\n\twant: %v", got, want)
+	}
+
+	got, err = UnmarshalMany[map[string]any]([]byte(" \n"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("UnmarshalMany = %v, %v, want no values and no error", got, err)
+	}
+
+	var se *SyntaxError
+	if _, err := UnmarshalMany[int]([]byte(`1 2x`)); !errors.As(err, &se) {
+		t.Errorf("UnmarshalMany error: got %v, want *SyntaxError", err)
+	}
+}
@@ -0,0 +1,114 @@
+package json
+
+// MergeArrays selects how [MergeOptions.Merge] combines two arrays.
+type MergeArrays int
+
+const (
+	// MergeArraysReplace replaces the destination array with the source array.
+	MergeArraysReplace MergeArrays = iota
+
+	// MergeArraysConcat appends the elements of the source array to
+	// those of the destination array.
+	MergeArraysConcat
+
+	// MergeArraysByIndex merges each element of the source array into the
+	// destination element at the same index. Source elements past the end
+	// of the destination array are appended, and destination elements past
+	// the end of the source array are kept.
+	MergeArraysByIndex
+)
+
+// MergeNulls selects how [MergeOptions.Merge] handles an object member
+// whose value in the source document is null.
+type MergeNulls int
+
+const (
+	// MergeNullsReplace sets the member to null in the result.
+	MergeNullsReplace MergeNulls = iota
+
+	// MergeNullsDelete removes the member from the result,
+	// as in a JSON Merge Patch (RFC 7386).
+	MergeNullsDelete
+
+	// MergeNullsIgnore leaves the destination member unchanged, or absent,
+	// so that null in the source means "not set".
+	MergeNullsIgnore
+)
+
+// MergeOptions configures [MergeOptions.Merge].
+// The zero value merges objects recursively and replaces everything else.
+type MergeOptions struct {
+	Arrays MergeArrays // how arrays are combined
+	Nulls  MergeNulls  // how null object members in the source are handled
+}
+
+// Merge merges the JSON document src into the JSON document dst, as configured
+// by opts, and returns the encoding of the result. It is intended for
+// layering configuration files, where src overrides dst.
+//
+// Merge is equivalent to opts.Merge(dst, src).
+func Merge(dst, src []byte, opts MergeOptions) ([]byte, error) {
+	return opts.Merge(dst, src)
+}
+
+// Merge merges the JSON document src into the JSON document dst and returns
+// the encoding of the result.
+//
+// Objects are merged member by member, recursively: members only in dst are
+// kept, members only in src are added, and members in both are merged.
+// Arrays are combined as selected by o.Arrays, null members of src are
+// handled as selected by o.Nulls, and any other value in src replaces the
+// value in dst.
+//
+// The result is compact, with object members sorted by key as by [Marshal].
+// Numbers keep their original form.
+func (o MergeOptions) Merge(dst, src []byte) ([]byte, error) {
+	opts := UnmarshalOptions{UseNumber: true}
+	var a, b any
+	if err := opts.Unmarshal(dst, &a); err != nil {
+		return nil, err
+	}
+	if err := opts.Unmarshal(src, &b); err != nil {
+		return nil, err
+	}
+	return Marshal(o.merge(a, b))
+}
+
+func (o MergeOptions) merge(dst, src any) any {
+	switch src := src.(type) {
+	case map[string]any:
+		dst, ok := dst.(map[string]any)
+		if !ok {
+			return o.merge(map[string]any{}, src)
+		}
+		for k, v := range src {
+			if v == nil {
+				switch o.Nulls {
+				case MergeNullsDelete:
+					delete(dst, k)
+					continue
+				case MergeNullsIgnore:
+					continue
+				}
+			}
+			dst[k] = o.merge(dst[k], v)
+		}
+		return dst
+	case []any:
+		dst, _ := dst.([]any)
+		switch o.Arrays {
+		case MergeArraysConcat:
+			return append(dst, src...)
+		case MergeArraysByIndex:
+			for i, v := range src {
+				if i < len(dst) {
+					dst[i] = o.merge(dst[i], v)
+				} else {
+					dst = append(dst, o.merge(nil, v))
+				}
+			}
+			return dst
+		}
+	}
+	return src
+}
@@ -0,0 +1,85 @@
+package json
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	const base = `{"name": "app", "port": 8080, "tags": ["a", "b"], "db": {"host": "localhost", "pool": 1.50}, "debug": true}`
+	tests := []struct {
+		CaseName
+		opts     MergeOptions
+		dst, src string
+		want     string
+	}{{
+		CaseName: Name("objects merge recursively"),
+		dst:      base,
+		src:      `{"port": 9090, "db": {"host": "db.internal"}, "extra": {}}`,
+		want:     `{"db":{"host":"db.internal","pool":1.50},"debug":true,"extra":{},"name":"app","port":9090,"tags":["a","b"]}`,
+	}, {
+		CaseName: Name("replace arrays and values"),
+		dst:      base,
+		src:      `{"tags": ["c"], "db": "url", "name": {"first": "x"}}`,
+		want:     `{"db":"url","debug":true,"name":{"first":"x"},"port":8080,"tags":["c"]}`,
+	}, {
+		CaseName: Name("concat arrays"),
+		opts:     MergeOptions{Arrays: MergeArraysConcat},
+		dst:      base,
+		src:      `{"tags": ["c"], "new": [1]}`,
+		want:     `{"db":{"host":"localhost","pool":1.50},"debug":true,"name":"app","new":[1],"port":8080,"tags":["a","b","c"]}`,
+	}, {
+		CaseName: Name("merge arrays by index"),
+		opts:     MergeOptions{Arrays: MergeArraysByIndex},
+		dst:      `[{"a": 1, "b": 2}, 3, 4]`,
+		src:      `[{"b": 5}, [6]]`,
+		want:     `[{"a":1,"b":5},[6],4]`,
+	}, {
+		CaseName: Name("merge arrays by index appends"),
+		opts:     MergeOptions{Arrays: MergeArraysByIndex},
+		dst:      `[1]`,
+		src:      `[2, 3]`,
+		want:     `[2,3]`,
+	}, {
+		CaseName: Name("null replaces"),
+		dst:      base,
+		src:      `{"db": null, "debug": null, "gone": null}`,
+		want:     `{"db":null,"debug":null,"gone":null,"name":"app","port":8080,"tags":["a","b"]}`,
+	}, {
+		CaseName: Name("null deletes"),
+		opts:     MergeOptions{Nulls: MergeNullsDelete},
+		dst:      base,
+		src:      `{"db": {"pool": null}, "debug": null, "gone": null, "new": {"x": null}}`,
+		want:     `{"db":{"host":"localhost"},"name":"app","new":{},"port":8080,"tags":["a","b"]}`,
+	}, {
+		CaseName: Name("null ignored"),
+		opts:     MergeOptions{Nulls: MergeNullsIgnore},
+		dst:      base,
+		src:      `{"db": {"pool": null}, "debug": null, "gone": null}`,
+		want:     `{"db":{"host":"localhost","pool":1.50},"debug":true,"name":"app","port":8080,"tags":["a","b"]}`,
+	}, {
+		CaseName: Name("top-level replace"),
+		dst:      `{"a": 1}`,
+		src:      `2`,
+		want:     `2`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Merge([]byte(tt.dst), []byte(tt.src), tt.opts)
+			if err != nil {
+				t.Fatalf("%s: Merge error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Merge:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeError(t *testing.T) {
+	if _, err := Merge([]byte(`{`), []byte(`{}`), MergeOptions{}); err == nil {
+		t.Error("Merge(invalid dst) error = nil, want SyntaxError")
+	}
+	if _, err := Merge([]byte(`{}`), []byte(`}`), MergeOptions{}); err == nil {
+		t.Error("Merge(invalid src) error = nil, want SyntaxError")
+	}
+}
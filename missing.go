@@ -0,0 +1,34 @@
+package json
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// A MissingField describes a struct field that was not populated by
+// [UnmarshalOptions.Unmarshal] because the object it was decoded from had no
+// member for it, recorded when the MissingFields option is set.
+type MissingField struct {
+	Path   string       // JSON Pointer (RFC 6901) the member would have in the input
+	Offset int64        // offset in the input of the object
+	Type   reflect.Type // struct type the object was decoded into
+	Name   string       // JSON name of the field
+}
+
+func (m MissingField) String() string {
+	return "missing field at " + strconv.Quote(m.Path)
+}
+
+// resolveMissingFields sorts the missing fields by offset and fills in their
+// paths in the valid JSON text data.
+func resolveMissingFields(data []byte, missing []MissingField) {
+	slices.SortStableFunc(missing, func(a, b MissingField) int { return int(a.Offset - b.Offset) })
+	offs := make([]int, len(missing))
+	for i, m := range missing {
+		offs[i] = int(m.Offset)
+	}
+	for i, p := range pointersAt(data, offs) {
+		missing[i].Path = p + "/" + escapePointerToken(missing[i].Name)
+	}
+}
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalMissingFields(t *testing.T) {
+	type (
+		Item struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		Embedded struct {
+			Extra string
+		}
+		T struct {
+			Embedded
+			Items []Item          `json:"items"`
+			Inner *Item           `json:"inner"`
+			Map   map[string]Item `json:"map"`
+			Typed any             `json:"typed"`
+			Skip  int             `json:"-"`
+		}
+	)
+	RegisterType("json.missingFieldsItem", Item{})
+	in := `{"items": [{"id": 1, "name": "a"}, {"id": 2}], "map": {"a/b": {}}, "typed": {"$type": "json.missingFieldsItem", "value": {"name": "x"}}}`
+	var missing []MissingField
+	opts := UnmarshalOptions{MissingFields: &missing, TypedInterfaces: true}
+	var v T
+	if err := opts.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	item := reflect.TypeFor[Item]()
+	want := []MissingField{
+		{"/Extra", 0, reflect.TypeFor[T](), "Extra"},
+		{"/inner", 0, reflect.TypeFor[T](), "inner"},
+		{"/items/1/name", 35, item, "name"},
+		{"/map/a~1b/id", 62, item, "id"},
+		{"/map/a~1b/name", 62, item, "name"},
+		{"/typed/value/id", 121, item, "id"},
+	}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("Unmarshal missing fields:\n\tgot:  %#v\n\twant: %#v", missing, want)
+	}
+	if got, want := missing[2].String(), `missing field at "/items/1/name"`; got != want {
+		t.Errorf("String:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	// Fields excluded by the mask are not missing.
+	missing = missing[:0]
+	opts = UnmarshalOptions{MissingFields: &missing, Mask: FieldMask{"items.id"}}
+	if err := opts.Unmarshal([]byte(`{"items": [{}]}`), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want = []MissingField{{"/items/0/id", 11, item, "id"}}
+	if !reflect.DeepEqual(missing, want) {
+		t.Errorf("Unmarshal missing fields:\n\tgot:  %#v\n\twant: %#v", missing, want)
+	}
+}
@@ -0,0 +1,375 @@
+// Package msgpack converts between MessagePack and the JSON token stream of
+// package json, for use with [json.Transcode].
+//
+// Only the part of MessagePack that corresponds to JSON is supported. When
+// reading, binary data becomes base64-encoded strings, as []byte values are
+// encoded by package json, and integer map keys become their decimal
+// strings; extension types are rejected. When writing, numbers without a
+// fraction become integers.
+package msgpack
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf8"
+
+	json "github.com/crunk1/gojson"
+)
+
+// A Reader reads MessagePack objects from an input stream as JSON tokens.
+type Reader struct {
+	r     *bufio.Reader
+	stack []level
+}
+
+// A level is an array or map being read.
+type level struct {
+	isMap bool
+	n     int // number of items, keys and values counted separately
+	items int // number of items read
+}
+
+// NewReader returns a Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Token returns the next token, of a type returned by [json.Decoder.Token]
+// with UseNumber set: integers are returned as a [json.Number] and floating
+// point numbers as a float64. A sequence of objects may be read.
+// At the end of the input, Token returns nil, io.EOF.
+func (r *Reader) Token() (json.Token, error) {
+	n := len(r.stack)
+	if n > 0 && r.stack[n-1].items == r.stack[n-1].n {
+		l := r.stack[n-1]
+		r.stack = r.stack[:n-1]
+		if n > 1 {
+			r.stack[n-2].items++
+		}
+		if l.isMap {
+			return json.Delim('}'), nil
+		}
+		return json.Delim(']'), nil
+	}
+	isKey := n > 0 && r.stack[n-1].isMap && r.stack[n-1].items%2 == 0
+	t, err := r.object()
+	if err != nil {
+		if err == io.EOF && n > 0 || err == io.ErrUnexpectedEOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch t.(type) {
+	case json.Delim:
+		if isKey {
+			return nil, errors.New("msgpack: unsupported map key of array or map type")
+		}
+		return t, nil // counted when it ends
+	case string:
+	case json.Number:
+		if isKey {
+			t = string(t.(json.Number))
+		}
+	default:
+		if isKey {
+			return nil, fmt.Errorf("msgpack: unsupported map key %v", t)
+		}
+	}
+	if n > 0 {
+		r.stack[n-1].items++
+	}
+	return t, nil
+}
+
+// object reads the next object, or the header of an array or map.
+func (r *Reader) object() (json.Token, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f: // positive fixint
+		return json.Number(strconv.Itoa(int(b))), nil
+	case b <= 0x8f: // fixmap
+		return r.begin(true, uint64(b&0x0f))
+	case b <= 0x9f: // fixarray
+		return r.begin(false, uint64(b&0x0f))
+	case b <= 0xbf: // fixstr
+		return r.str(uint64(b & 0x1f))
+	case b >= 0xe0: // negative fixint
+		return json.Number(strconv.Itoa(int(int8(b)))), nil
+	}
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6: // bin 8, 16, 32
+		n, err := r.uint(1 << (b - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := r.bytes(n)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	case 0xca: // float 32
+		n, err := r.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb: // float 64
+		n, err := r.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(n), nil
+	case 0xcc, 0xcd, 0xce, 0xcf: // uint 8, 16, 32, 64
+		n, err := r.uint(1 << (b - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatUint(n, 10)), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3: // int 8, 16, 32, 64
+		size := 1 << (b - 0xd0)
+		n, err := r.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend the value from its size.
+		shift := 64 - 8*size
+		return json.Number(strconv.FormatInt(int64(n<<shift)>>shift, 10)), nil
+	case 0xd9, 0xda, 0xdb: // str 8, 16, 32
+		n, err := r.uint(1 << (b - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return r.str(n)
+	case 0xdc, 0xdd: // array 16, 32
+		n, err := r.uint(2 << (b - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return r.begin(false, n)
+	case 0xde, 0xdf: // map 16, 32
+		n, err := r.uint(2 << (b - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return r.begin(true, n)
+	}
+	return nil, fmt.Errorf("msgpack: unsupported format 0x%02x", b)
+}
+
+// begin starts an array or map of n elements or members.
+func (r *Reader) begin(isMap bool, n uint64) (json.Token, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("msgpack: array or map length %d too large", n)
+	}
+	l := level{isMap: isMap, n: int(n)}
+	if isMap {
+		l.n *= 2
+	}
+	r.stack = append(r.stack, l)
+	if isMap {
+		return json.Delim('{'), nil
+	}
+	return json.Delim('['), nil
+}
+
+// uint reads a big-endian unsigned integer of size bytes.
+func (r *Reader) uint(size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r.r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// bytes reads n bytes.
+func (r *Reader) bytes(n uint64) ([]byte, error) {
+	// Read the data in pieces, rather than trusting n to allocate it.
+	data, err := io.ReadAll(io.LimitReader(r.r, int64(n)))
+	if err == nil && uint64(len(data)) < n {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
+
+// str reads a string of n bytes.
+func (r *Reader) str(n uint64) (json.Token, error) {
+	data, err := r.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(data) {
+		return nil, errors.New("msgpack: invalid UTF-8 in string")
+	}
+	return string(data), nil
+}
+
+// A Writer writes JSON tokens to an output stream as MessagePack objects.
+//
+// MessagePack needs the length of an array or map before its contents, so
+// each top-level array or map is held in memory until it is complete.
+type Writer struct {
+	w     io.Writer
+	stack []frame
+	buf   []byte
+}
+
+// A frame is an array or map being written.
+type frame struct {
+	end   json.Delim
+	items int // number of items written, keys and values counted separately
+	data  []byte
+}
+
+// NewWriter returns a Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// WriteToken writes the next token, which must be of a type returned by
+// [json.Decoder.Token].
+func (w *Writer) WriteToken(t json.Token) error {
+	var b []byte
+	if len(w.stack) > 0 {
+		b = w.stack[len(w.stack)-1].data
+	} else {
+		b = w.buf[:0]
+	}
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '[', '{':
+			end := json.Delim(']')
+			if t == '{' {
+				end = '}'
+			}
+			w.stack = append(w.stack, frame{end: end})
+			return nil
+		case ']', '}':
+			if len(w.stack) == 0 || w.stack[len(w.stack)-1].end != t {
+				return fmt.Errorf("msgpack: unexpected %v token", t)
+			}
+			f := w.stack[len(w.stack)-1]
+			w.stack = w.stack[:len(w.stack)-1]
+			if len(w.stack) > 0 {
+				b = w.stack[len(w.stack)-1].data
+			} else {
+				b = w.buf[:0]
+			}
+			if t == '}' {
+				b = appendHead(b, 0x80, 0xde, f.items/2)
+			} else {
+				b = appendHead(b, 0x90, 0xdc, f.items)
+			}
+			b = append(b, f.data...)
+		default:
+			return fmt.Errorf("msgpack: invalid delimiter %v", t)
+		}
+	case nil:
+		b = append(b, 0xc0)
+	case bool:
+		if t {
+			b = append(b, 0xc3)
+		} else {
+			b = append(b, 0xc2)
+		}
+	case string:
+		switch n := len(t); {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		b = append(b, t...)
+	case float64:
+		b = appendFloat(b, t)
+	case json.Number:
+		if n, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+			b = appendInt(b, n)
+		} else if n, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			b = binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+		} else if f, err := t.Float64(); err == nil {
+			b = appendFloat(b, f)
+		} else {
+			return fmt.Errorf("msgpack: cannot write number %s", t)
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported token type %T", t)
+	}
+
+	if len(w.stack) > 0 {
+		f := &w.stack[len(w.stack)-1]
+		f.data = b
+		f.items++
+		return nil
+	}
+	w.buf = b
+	_, err := w.w.Write(b)
+	return err
+}
+
+// appendHead appends the header of an array or map of n elements or members,
+// using the format fix if n is small enough and otherwise the 16 or
+// 32 bit format starting at format16.
+func appendHead(b []byte, fix, format16 byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, format16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, format16+1), uint32(n))
+}
+
+func appendInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= math.MaxInt8, n < 0 && n >= -32:
+		return append(b, byte(n))
+	case n >= 0 && n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n >= 0 && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	case n >= 0:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), uint64(n))
+	case n >= math.MinInt8:
+		return append(b, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+}
+
+// appendFloat appends f as an integer if it has no fraction and is within the
+// range of int64, and otherwise as a float 32 or float 64,
+// whichever holds it exactly.
+func appendFloat(b []byte, f float64) []byte {
+	if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 && !(f == 0 && math.Signbit(f)) {
+		return appendInt(b, int64(f))
+	}
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(f))
+}
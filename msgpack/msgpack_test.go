@@ -0,0 +1,131 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	json "github.com/crunk1/gojson"
+)
+
+// toJSON transcodes the MessagePack data to JSON text.
+func toJSON(data []byte) (string, error) {
+	var buf strings.Builder
+	err := json.Transcode(json.NewEncoder(&buf), NewReader(bytes.NewReader(data)))
+	return buf.String(), err
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"00", "0"},
+		{"7f", "127"},
+		{"ff", "-1"},
+		{"e0", "-32"},
+		{"cc80", "128"},
+		{"cd0100", "256"},
+		{"ce00010000", "65536"},
+		{"cfffffffffffffffff", "18446744073709551615"},
+		{"d080", "-128"},
+		{"d1ff00", "-256"},
+		{"d2ffff0000", "-65536"},
+		{"d38000000000000000", "-9223372036854775808"},
+		{"ca3fc00000", "1.5"},
+		{"cb3ff199999999999a", "1.1"},
+		{"c0", "null"},
+		{"c2", "false"},
+		{"c3", "true"},
+		{"a0", `""`},
+		{"a3616263", `"abc"`},
+		{"d903616263", `"abc"`},
+		{"da0003616263", `"abc"`},
+		{"c40401020304", `"AQIDBA=="`},
+		{"90", "[]"},
+		{"93019202039100", "[1,[2,3],[0]]"},
+		{"dc0002c0c0", "[null,null]"},
+		{"80", "{}"},
+		{"82a16101a16292c3c2", `{"a":1,"b":[true,false]}`},
+		{"de0001010a", `{"1":10}`},
+		{"0102", "1\n2"},
+	}
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+		got, err := toJSON(data)
+		if err != nil {
+			t.Errorf("%s: Token error: %v", tt.in, err)
+			continue
+		}
+		if want := tt.want + "\n"; got != want {
+			t.Errorf("%s: Token:\n\tgot:  %q\n\twant: %q", tt.in, got, want)
+		}
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	tests := []string{
+		"cd01",   // truncated integer
+		"9201",   // truncated array
+		"a2c328", // invalid UTF-8
+		"819000", // array key
+		"c1",     // never used
+		"d40100", // extension
+	}
+	for _, in := range tests {
+		data, _ := hex.DecodeString(in)
+		if _, err := toJSON(data); err == nil {
+			t.Errorf("%s: Token error: got nil, want error", in)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`0`, "00"},
+		{`-32`, "e0"},
+		{`-33`, "d0df"},
+		{`200`, "ccc8"},
+		{`65536`, "ce00010000"},
+		{`18446744073709551615`, "cfffffffffffffffff"},
+		{`1.5`, "ca3fc00000"},
+		{`1.1`, "cb3ff199999999999a"},
+		{`"abc"`, "a3616263"},
+		{`[1, [2, 3], {"a": null}]`, "930192020381a161c0"},
+		{`[0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15]`, "dc0010000102030405060708090a0b0c0d0e0f"},
+		{`true false`, "c3c2"},
+	}
+	for _, tt := range tests {
+		dec := json.NewDecoder(strings.NewReader(tt.in))
+		dec.UseNumber()
+		var buf bytes.Buffer
+		if err := json.Transcode(NewWriter(&buf), dec); err != nil {
+			t.Errorf("%s: WriteToken error: %v", tt.in, err)
+			continue
+		}
+		if got := hex.EncodeToString(buf.Bytes()); got != tt.want {
+			t.Errorf("%s: WriteToken:\n\tgot:  %s\n\twant: %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := `{"a":[1,-2,3.25,12345678901234567890],"b":{"c":"é","d":[]},"e":null}` + "\n"
+	dec := json.NewDecoder(strings.NewReader(in))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := json.Transcode(NewWriter(&buf), dec); err != nil {
+		t.Fatalf("Transcode error: %v", err)
+	}
+	got, err := toJSON(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Transcode error: %v", err)
+	}
+	if got != in {
+		t.Errorf("Transcode:\n\tgot:  %s\n\twant: %s", got, in)
+	}
+}
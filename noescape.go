@@ -0,0 +1,48 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"unsafe"
+)
+
+// MarshalNoEscape is like [Marshal] but does not cause v, or the value it
+// points to, to be allocated on the heap, so that a local variable passed by
+// pointer can stay on the stack of the caller.
+//
+// The value must not be retained, by its MarshalJSON or MarshalText methods
+// for example, after MarshalNoEscape returns. A returned
+// [UnsupportedValueError] does not refer to v: its Value is the zero Value.
+func MarshalNoEscape(v any) ([]byte, error) {
+	return appendNoEscape(nil, *(*any)(noescape(unsafe.Pointer(&v))))
+}
+
+// AppendNoEscape is like [MarshalNoEscape] but appends the encoding of v to
+// dst and returns the extended buffer. When dst has enough capacity and v
+// is a struct of basic types, such as strings and numbers, AppendNoEscape
+// does not allocate.
+func AppendNoEscape(dst []byte, v any) ([]byte, error) {
+	return appendNoEscape(dst, *(*any)(noescape(unsafe.Pointer(&v))))
+}
+
+func appendNoEscape(dst []byte, v any) ([]byte, error) {
+	e := newEncodeState()
+	defer putEncodeState(e, maxPooledBufferSize)
+
+	if err := e.marshal(v, encOpts{escapeHTML: true}); err != nil {
+		var uerr *UnsupportedValueError
+		if errors.As(err, &uerr) {
+			uerr.Value = reflect.Value{}
+		}
+		return dst, err
+	}
+	return append(dst, e.Bytes()...), nil
+}
+
+// noescape hides a pointer from escape analysis. It is the identity function,
+// but the compiler cannot tell that the result refers to the same memory as p.
+// This is only safe when that memory is not retained after the call returns.
+func noescape(p unsafe.Pointer) unsafe.Pointer {
+	x := uintptr(p)
+	return *(*unsafe.Pointer)(unsafe.Pointer(&x))
+}
@@ -0,0 +1,50 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+type noEscapeSample struct {
+	Name  string  `json:"name"`
+	Count int     `json:"count"`
+	Ratio float64 `json:"ratio"`
+	OK    bool    `json:"ok"`
+}
+
+func TestAppendNoEscape(t *testing.T) {
+	v := noEscapeSample{"<a>", 3, 0.5, true}
+	want, err := Marshal(&v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	got, err := AppendNoEscape([]byte("x"), &v)
+	if err != nil {
+		t.Fatalf("AppendNoEscape error: %v", err)
+	}
+	if string(got) != "x"+string(want) {
+		t.Errorf("AppendNoEscape:\n\tgot:  %s\n\twant: x%s", got, want)
+	}
+	if got, err := MarshalNoEscape(v); err != nil || string(got) != string(want) {
+		t.Errorf("MarshalNoEscape:\n\tgot:  %s, %v\n\twant: %s, nil", got, err, want)
+	}
+
+	_, err = MarshalNoEscape(math.NaN())
+	var uerr *UnsupportedValueError
+	if !errors.As(err, &uerr) || uerr.Value != (reflect.Value{}) {
+		t.Errorf("MarshalNoEscape error: got %#v, want an UnsupportedValueError with the zero Value", err)
+	}
+}
+
+func TestAppendNoEscapeAllocs(t *testing.T) {
+	buf := make([]byte, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		v := noEscapeSample{"name", 3, 0.5, true}
+		buf, _ = AppendNoEscape(buf[:0], &v)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendNoEscape allocated %v times, want 0", allocs)
+	}
+}
@@ -0,0 +1,169 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Null holds a value of type T that may be null, so that the optional and
+// nullable semantics of struct fields are available without pointers, and
+// where no tag can be written: for map values and slice elements, as in
+// map[string]Null[int].
+//
+// A Null whose Valid field is false encodes as null, and null decodes as
+// a Null with Valid false and V the zero value. Other JSON values decode
+// into V and set Valid.
+//
+// The type argument must not itself be able to encode as null, so that null
+// is never ambiguous: it must not be a pointer, interface, map, or slice
+// type, or a Null type.
+type Null[T any] struct {
+	V     T
+	Valid bool // V is not null
+}
+
+// Maybe holds a value of type T that may be absent: a Maybe whose Present
+// field is false is omitted from the enclosing object, whether it is a struct
+// field or a map value. Any JSON value, including null, decodes into V and
+// sets Present, so that a Maybe[Null[T]] tells an absent value from null and
+// from a value of type T.
+//
+// Absent values cannot be encoded elsewhere, such as in an array or at the
+// top level, where encoding them is an error. A Maybe struct field must not
+// have the optional tag option, which it implies, and the type argument must
+// not be a Maybe type. Likewise, a Null struct field must not have the
+// nullable tag option.
+type Maybe[T any] struct {
+	V       T
+	Present bool // V is present in the enclosing object
+}
+
+// wrapperKind reports whether t is an instance of [Null] or [Maybe].
+func wrapperKind(t reflect.Type) (null, maybe bool) {
+	if t.Kind() != reflect.Struct || t.PkgPath() != optionsType.PkgPath() {
+		return false, false
+	}
+	return strings.HasPrefix(t.Name(), "Null["), strings.HasPrefix(t.Name(), "Maybe[")
+}
+
+// checkWrapper checks the type argument of an instance t of Null or Maybe.
+func checkWrapper(t reflect.Type) error {
+	null, maybe := wrapperKind(t)
+	elem := t.Field(0).Type
+	elemNull, elemMaybe := wrapperKind(elem)
+	if null {
+		switch elem.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			elemNull = true
+		}
+		if elemNull {
+			return fmt.Errorf("json: %s requires a type argument that cannot be null, type = %q", t.Name(), elem.String())
+		}
+	}
+	if maybe && elemMaybe {
+		return fmt.Errorf("json: %s requires a type argument other than Maybe, type = %q", t.Name(), elem.String())
+	}
+	return nil
+}
+
+// checkElem checks the element type t of a map, slice, or array type, if it
+// is an instance of Null or Maybe, as checkStructField checks fields.
+func checkElem(t reflect.Type) error {
+	if null, maybe := wrapperKind(t); null || maybe {
+		return checkWrapper(t)
+	}
+	return nil
+}
+
+// errorEncoder returns an encoder that fails with err.
+func errorEncoder(err error) encoderFunc {
+	return func(e *encodeState, _ reflect.Value, _ encOpts) { e.error(err) }
+}
+
+// isAbsent reports whether v is a Maybe without a value.
+func isAbsent(v reflect.Value) bool {
+	_, maybe := wrapperKind(v.Type())
+	return maybe && !v.Field(1).Bool()
+}
+
+func newWrapperEncoder(t reflect.Type) encoderFunc {
+	if err := checkWrapper(t); err != nil {
+		return errorEncoder(err)
+	}
+	_, maybe := wrapperKind(t)
+	elemEnc := typeEncoder(t.Field(0).Type)
+	return func(e *encodeState, v reflect.Value, opts encOpts) {
+		if !v.Field(1).Bool() {
+			if maybe {
+				e.error(&UnsupportedValueError{v, "absent " + v.Type().Name() + " outside of an object"})
+			}
+			e.WriteString("null")
+			return
+		}
+		elemEnc(e, v.Field(0), opts)
+	}
+}
+
+// indirectWrapper returns the Null or Maybe value that v is or points to,
+// allocating nil pointers on the way, and whether there is one. A null
+// stored in a settable pointer sets it to nil instead, as for other types.
+func (d *decodeState) indirectWrapper(v reflect.Value) (reflect.Value, bool) {
+	t := v.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if null, maybe := wrapperKind(t); !null && !maybe {
+		return v, false
+	}
+	isNull := d.opcode == scanBeginLiteral && d.data[d.readIndex()] == 'n'
+	for v.Kind() == reflect.Pointer {
+		if isNull && v.CanSet() {
+			return v, false
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// wrapped consumes a JSON value from d.data[d.off-1:], decoding it into the
+// Null or Maybe value v.
+func (d *decodeState) wrapped(v reflect.Value) error {
+	if err := checkWrapper(v.Type()); err != nil {
+		d.saveError(err)
+		return d.value(reflect.Value{})
+	}
+	if null, _ := wrapperKind(v.Type()); null && d.opcode == scanBeginLiteral && d.data[d.readIndex()] == 'n' {
+		v.SetZero()
+		return d.value(reflect.Value{})
+	}
+	v.Field(1).SetBool(true)
+	return d.value(v.Field(0))
+}
+
+// emptyString reports whether the value d is about to decode is the empty
+// string "".
+func (d *decodeState) emptyString() bool {
+	return d.opcode == scanBeginLiteral && d.data[d.readIndex()] == '"' && d.off < len(d.data) && d.data[d.off] == '"'
+}
+
+// emptyAsNullType reports whether an empty string decodes as null into a
+// value of type t with UnmarshalOptions.EmptyAsNull.
+func emptyAsNullType(t reflect.Type) bool {
+	null, _ := wrapperKind(t)
+	return null || t.Kind() == reflect.Pointer || t == timeType
+}
+
+// storeEmptyAsNull consumes the empty string d is about to decode,
+// decoding null into v in its place.
+func (d *decodeState) storeEmptyAsNull(v reflect.Value) error {
+	d.rescanLiteral()
+	if null, _ := wrapperKind(v.Type()); null {
+		v.SetZero()
+		return nil
+	}
+	return d.literalStore(nullLiteral, v, false)
+}
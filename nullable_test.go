@@ -0,0 +1,165 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNullMaybeRoundTrip(t *testing.T) {
+	type T struct {
+		A Maybe[Null[int]] `json:"a"`
+		B Null[string]     `json:"b"`
+		M map[string]Maybe[Null[int]]
+		N map[string]Null[int]
+		S []Null[int]
+		P []Maybe[*int]
+	}
+	tests := []struct {
+		CaseName
+		in   string
+		want T
+		out  string
+	}{{
+		CaseName: Name("values"),
+		in:       `{"a": 1, "b": "x", "M": {"k": 2}, "N": {"k": 3}, "S": [4], "P": [5]}`,
+		want: T{
+			A: Maybe[Null[int]]{Null[int]{1, true}, true},
+			B: Null[string]{"x", true},
+			M: map[string]Maybe[Null[int]]{"k": {Null[int]{2, true}, true}},
+			N: map[string]Null[int]{"k": {3, true}},
+			S: []Null[int]{{4, true}},
+			P: []Maybe[*int]{{ptrTo(5), true}},
+		},
+		out: `{"a":1,"b":"x","M":{"k":2},"N":{"k":3},"S":[4],"P":[5]}`,
+	}, {
+		CaseName: Name("nulls"),
+		in:       `{"a": null, "b": null, "M": {"k": null}, "N": {"k": null}, "S": [null, 1], "P": [null]}`,
+		want: T{
+			A: Maybe[Null[int]]{Present: true},
+			M: map[string]Maybe[Null[int]]{"k": {Present: true}},
+			N: map[string]Null[int]{"k": {}},
+			S: []Null[int]{{}, {1, true}},
+			P: []Maybe[*int]{{nil, true}},
+		},
+		out: `{"a":null,"b":null,"M":{"k":null},"N":{"k":null},"S":[null,1],"P":[null]}`,
+	}, {
+		CaseName: Name("absent"),
+		in:       `{"M": {}}`,
+		want:     T{M: map[string]Maybe[Null[int]]{}},
+		out:      `{"b":null,"M":{},"N":null,"S":null,"P":null}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var got T
+			if err := Unmarshal([]byte(tt.in), &got); err != nil {
+				t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %+v\n\twant: %+v", tt.Where, got, tt.want)
+			}
+			b, err := Marshal(got)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(b) != tt.out {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.out)
+			}
+		})
+	}
+}
+
+func TestNullTopLevel(t *testing.T) {
+	n := Null[int]{5, true}
+	if err := Unmarshal([]byte(`null`), &n); err != nil || n.Valid || n.V != 0 {
+		t.Errorf("Unmarshal null: got %+v, %v; want invalid", n, err)
+	}
+	var p *Null[int]
+	if err := Unmarshal([]byte(`7`), &p); err != nil || p == nil || *p != (Null[int]{7, true}) {
+		t.Errorf("Unmarshal 7: got %+v, %v", p, err)
+	}
+	if err := Unmarshal([]byte(`null`), &p); err != nil || p != nil {
+		t.Errorf("Unmarshal null: got %+v, %v; want nil pointer", p, err)
+	}
+}
+
+func TestNullMaybeErrors(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      any
+		wantErr string
+	}{
+		{Name("absent element"), []Maybe[int]{{1, true}, {}}, "json: unsupported value: absent Maybe[int] outside of an object"},
+		{Name("absent top level"), Maybe[int]{}, "json: unsupported value: absent Maybe[int] outside of an object"},
+		{Name("nullable argument"), map[string]Null[*int]{}, `json: Null[*int] requires a type argument that cannot be null, type = "*int"`},
+		{Name("nested Null"), []Null[Null[int]]{}, `json: Null[github.com/crunk1/gojson.Null[int]] requires a type argument that cannot be null, type = "json.Null[int]"`},
+		{Name("nested Maybe"), []Maybe[Maybe[int]]{}, `json: Maybe[github.com/crunk1/gojson.Maybe[int]] requires a type argument other than Maybe, type = "json.Maybe[int]"`},
+		{Name("optional Maybe field"), struct {
+			A Maybe[int] `json:",optional"`
+		}{}, `json: Maybe[int] field "A" cannot have the optional tag, which its type implies`},
+		{Name("nullable Null field"), struct {
+			A Null[int] `json:",nullable"`
+		}{}, `json: Null[int] field "A" cannot have the nullable tag, which its type implies`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := Marshal(tt.in)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: Marshal error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+			}
+		})
+	}
+
+	var v map[string]Null[[]int]
+	err := Unmarshal([]byte(`{"a": [1]}`), &v)
+	if err == nil || !strings.Contains(err.Error(), "requires a type argument that cannot be null") {
+		t.Errorf("Unmarshal error: got %v, want type argument error", err)
+	}
+}
+
+func TestUnmarshalEmptyAsNull(t *testing.T) {
+	type T struct {
+		Ptr      *string      `json:"ptr"`
+		Time     time.Time    `json:"time"`
+		Nullable *int         `json:"nullable,nullable,emptyasnull"`
+		Both     **int        `json:"both,optional,nullable,emptyasnull"`
+		Count    int          `json:"count,emptyasnull"`
+		Quoted   int          `json:"quoted,string,emptyasnull"`
+		Date     *time.Time   `json:"date,format:date"`
+		Name     Null[string] `json:"name"`
+		Items    []*int       `json:"items"`
+		Plain    string       `json:"plain"`
+	}
+	in := `{"ptr": "", "time": "", "nullable": "", "both": "", "count": "", "quoted": "", "date": "", "name": "", "items": ["", 1], "plain": ""}`
+
+	var got T
+	got.Ptr = new(string)
+	if err := (UnmarshalOptions{EmptyAsNull: true}).Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	one := 1
+	var nilInt *int
+	want := T{Both: &nilInt, Items: []*int{nil, &one}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal:\n\tgot:  %+v\n\twant: %+v", got, want)
+	}
+
+	// Without the option, only fields tagged emptyasnull accept "".
+	in = `{"nullable": "", "both": "", "count": "", "quoted": ""}`
+	got = T{Count: 3}
+	if err := Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := (T{Both: &nilInt, Count: 3}); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal:\n\tgot:  %+v\n\twant: %+v", got, want)
+	}
+	var ute *UnmarshalTypeError
+	if err := Unmarshal([]byte(`{"ptr": 1}`), &got); !errors.As(err, &ute) {
+		t.Errorf("Unmarshal error: got %v, want an UnmarshalTypeError", err)
+	}
+	if err := Unmarshal([]byte(`{"time": ""}`), &got); err == nil {
+		t.Error("Unmarshal error: got nil, want an error for an empty time without EmptyAsNull")
+	}
+}
@@ -0,0 +1,54 @@
+package json
+
+import "fmt"
+
+// ToObject returns the members of the JSON object that v encodes as,
+// so that they can be processed one by one, for example to sign or filter
+// them, with the names and encodings the struct tags of v give them.
+// The result can be encoded again with [Marshal], or decoded with
+// [FromObject]. If v encodes as null, ToObject returns a nil map.
+// It returns an error if v encodes as another kind of value.
+func ToObject(v any) (map[string]RawMessage, error) {
+	return MarshalOptions{}.ToObject(v)
+}
+
+// ToObject is like the package-level [ToObject] but encodes v as configured
+// by o.
+func (o MarshalOptions) ToObject(v any) (map[string]RawMessage, error) {
+	b, err := o.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	switch b[0] {
+	case 'n':
+		return nil, nil
+	case '{':
+	default:
+		return nil, fmt.Errorf("json: ToObject of %T, which does not encode as an object", v)
+	}
+	entries, _, err := containerEntries(b, 0)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]RawMessage, len(entries))
+	for _, e := range entries {
+		m[e.key] = RawMessage(b[e.value:e.end:e.end])
+	}
+	return m, nil
+}
+
+// FromObject decodes the object with the members m into the value pointed
+// to by v, as [Unmarshal] does, so that it reverses [ToObject].
+func FromObject(m map[string]RawMessage, v any) error {
+	return UnmarshalOptions{}.FromObject(m, v)
+}
+
+// FromObject is like the package-level [FromObject] but decodes as
+// configured by o.
+func (o UnmarshalOptions) FromObject(m map[string]RawMessage, v any) error {
+	b, err := Marshal(m)
+	if err != nil {
+		return err
+	}
+	return o.Unmarshal(b, v)
+}
@@ -0,0 +1,55 @@
+package json
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestToObject(t *testing.T) {
+	type T struct {
+		Name  string         `json:"name"`
+		Tags  []string       `json:"tags,omitempty"`
+		Inner map[string]int `json:"inner"`
+		Skip  int            `json:"-"`
+	}
+	v := T{Name: "a<b", Inner: map[string]int{"x": 1}}
+	m, err := ToObject(v)
+	if err != nil {
+		t.Fatalf("ToObject error: %v", err)
+	}
+	want := map[string]RawMessage{
+		"name":  RawMessage(`"a\u003cb"`),
+		"inner": RawMessage(`{"x":1}`),
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("ToObject:\n\tgot:  %s\n\twant: %s", m, want)
+	}
+
+	// Members can be changed and added before decoding the object again.
+	m["name"] = RawMessage(`"c"`)
+	m["tags"] = RawMessage(`["t"]`)
+	var got T
+	if err := FromObject(m, &got); err != nil {
+		t.Fatalf("FromObject error: %v", err)
+	}
+	if want := (T{Name: "c", Tags: []string{"t"}, Inner: map[string]int{"x": 1}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromObject:\n\tgot:  %+v\n\twant: %+v", got, want)
+	}
+
+	m["extra"] = RawMessage(`1`)
+	err = UnmarshalOptions{DisallowUnknownFields: true}.FromObject(m, &got)
+	if err == nil || !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Errorf("FromObject error: got %v, want unknown field", err)
+	}
+
+	if m, err := ToObject((*T)(nil)); m != nil || err != nil {
+		t.Errorf("ToObject(nil) = %v, %v, want nil, nil", m, err)
+	}
+	if _, err := ToObject([]int{1}); err == nil || err.Error() != "json: ToObject of []int, which does not encode as an object" {
+		t.Errorf("ToObject error: got %v", err)
+	}
+	if m, err := (MarshalOptions{Mask: FieldMask{"name"}}).ToObject(v); err != nil || len(m) != 1 {
+		t.Errorf("ToObject with mask = %s, %v, want only name", m, err)
+	}
+}
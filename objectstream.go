@@ -0,0 +1,168 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// EncodeObjectStream writes a JSON object to the stream a member at a
+// time, followed by a newline, as [Encoder.Encode] writes a map, so that
+// an object too large to hold in memory can be written as its members are
+// produced. members is called once, with a function that writes a member
+// with the given key and, as its value, the encoding of value, as by
+// Encode; members calls it for each member in turn and returns nil once
+// all are written. The members are indented as set by
+// [Encoder.SetIndent]. Keys are written as given, so members should not
+// repeat them.
+//
+// If members returns an error, or writing a member fails, the object is
+// left unfinished and EncodeObjectStream returns the error; a member
+// whose value cannot be encoded is not written. EncodeObjectStream must
+// not be called inside a value written with [Encoder.WriteToken].
+func (enc *Encoder) EncodeObjectStream(members func(write func(key string, value any) error) error) error {
+	if enc.err != nil {
+		return enc.err
+	}
+	if enc.tokenState != tokenTopValue {
+		return errors.New("json: EncodeObjectStream inside a value written with WriteToken")
+	}
+	prefix, indent := enc.indentPrefix, enc.indentValue
+	n := 0
+	var writeErr error
+	write := func(key string, value any) error {
+		if writeErr != nil {
+			return writeErr
+		}
+		e := newEncodeState()
+		defer putEncodeState(e, enc.bufferSize())
+		defer enc.releaseBuffers()
+
+		if n == 0 {
+			e.WriteByte('{')
+		} else {
+			e.WriteByte(',')
+		}
+		if prefix != "" || indent != "" {
+			e.WriteByte('\n')
+			e.WriteString(prefix)
+			e.WriteString(indent)
+		}
+		e.Write(appendString(e.AvailableBuffer(), key, enc.escapeHTML))
+		e.WriteByte(':')
+		if prefix != "" || indent != "" {
+			e.WriteByte(' ')
+		}
+		start := e.Len()
+		if err := e.marshal(value, encOpts{escapeHTML: enc.escapeHTML}); err != nil {
+			return err
+		}
+		b := e.Bytes()
+		if prefix != "" || indent != "" {
+			var err error
+			enc.indentBuf, err = appendIndent(append(enc.indentBuf[:0], b[:start]...), b[start:], prefix+indent, indent)
+			if err != nil {
+				return err
+			}
+			b = enc.indentBuf
+		}
+		if writeErr = enc.writeToken(b); writeErr != nil {
+			return writeErr
+		}
+		n++
+		return nil
+	}
+	if err := members(write); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	end := "}\n"
+	switch {
+	case n == 0:
+		end = "{}\n"
+	case prefix != "" || indent != "":
+		end = "\n" + prefix + "}\n"
+	}
+	return enc.writeToken([]byte(end))
+}
+
+// DecodeObject reads the next JSON value from its input, which must be an
+// object, a member at a time, the counterpart of
+// [Encoder.EncodeObjectStream]: only the member being read is held in
+// memory, not the whole object. For each member, in
+// order, DecodeObject calls fn with the key and the Decoder, positioned
+// at the member's value. fn may read the value with [Decoder.Decode],
+// [Decoder.DecodeObject], [Decoder.ReadRaw], or a complete sequence of
+// tokens with [Decoder.Token], or leave it unread, in which case it is
+// skipped. The duplicate key policy applies to the members as it does to
+// the objects read with Token.
+//
+// As with [Unmarshal], a JSON null is read without calling fn. If the
+// next value is of any other type, DecodeObject returns an
+// [UnmarshalTypeError] and does not consume it. If fn returns an error,
+// DecodeObject returns it at once, leaving the rest of the object unread.
+// With [DuplicateKeysError], the members with duplicate keys are skipped
+// without calling fn, and the first of them is reported once the whole
+// object has been read.
+func (dec *Decoder) DecodeObject(fn func(key string, dec *Decoder) error) error {
+	if dec.err != nil {
+		return dec.err
+	}
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return err
+	}
+	if !dec.tokenValueAllowed() {
+		return &SyntaxError{msg: "not at beginning of value", Offset: dec.InputOffset()}
+	}
+	c, err := dec.peek()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '{':
+	case 'n':
+		_, err := dec.ReadRaw()
+		return err
+	default:
+		return &UnmarshalTypeError{Value: valueKind([]byte{c}), Type: reflect.TypeFor[map[string]any](), Offset: dec.InputOffset()}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	depth := len(dec.tokenStack)
+	var dupErr error // the first duplicate key, with DuplicateKeysError
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if errors.Is(err, ErrDuplicateKey) {
+			// The member has been skipped.
+			if dupErr == nil {
+				dupErr = err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if tok == Delim('}') {
+			return dupErr
+		}
+		key := tok.(string)
+		if err := fn(key, dec); err != nil {
+			return err
+		}
+		switch {
+		case len(dec.tokenStack) == depth && dec.tokenState == tokenObjectColon:
+			if _, err := dec.ReadRaw(); err != nil {
+				return err
+			}
+		case len(dec.tokenStack) != depth || dec.tokenState != tokenObjectComma:
+			return fmt.Errorf("json: DecodeObject callback for key %q did not read the whole value", key)
+		}
+	}
+}
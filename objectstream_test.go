@@ -0,0 +1,211 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeObjectStream(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+		Tags []int  `json:"tags"`
+	}
+	entries := []struct {
+		key   string
+		value any
+	}{{"a<b", user{"x", []int{1, 2}}}, {"n", 3}}
+	members := func(write func(string, any) error) error {
+		for _, m := range entries {
+			if err := write(m.key, m.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	want := map[string]any{"a<b": entries[0].value, "n": 3}
+
+	tests := []struct {
+		CaseName
+		prefix, indent string
+	}{
+		{CaseName: Name("compact")},
+		{CaseName: Name("indent"), indent: "\t"},
+		{CaseName: Name("prefix"), prefix: "> ", indent: "  "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var got, wantBuf bytes.Buffer
+			enc := NewEncoder(&got)
+			enc.SetIndent(tt.prefix, tt.indent)
+			if err := enc.EncodeObjectStream(members); err != nil {
+				t.Fatalf("%s: EncodeObjectStream error: %v", tt.Where, err)
+			}
+			enc = NewEncoder(&wantBuf)
+			enc.SetIndent(tt.prefix, tt.indent)
+			if err := enc.Encode(want); err != nil {
+				t.Fatalf("%s: Encode error: %v", tt.Where, err)
+			}
+			if got.String() != wantBuf.String() {
+				t.Errorf("%s: EncodeObjectStream:\n\tgot:  %q\n\twant: %q", tt.Where, got.String(), wantBuf.String())
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	if err := enc.EncodeObjectStream(func(func(string, any) error) error { return nil }); err != nil {
+		t.Fatalf("EncodeObjectStream of no members error: %v", err)
+	}
+	if got, want := buf.String(), "{}\n"; got != want {
+		t.Errorf("EncodeObjectStream of no members:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	buf.Reset()
+	errStop := errors.New("stop")
+	err := enc.EncodeObjectStream(func(write func(string, any) error) error {
+		if err := write("a", 1); err != nil {
+			return err
+		}
+		if err := write("b", make(chan int)); err == nil {
+			t.Error("write of unsupported value: got nil error, want error")
+		}
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("EncodeObjectStream error: got %v, want %v", err, errStop)
+	}
+	if got, want := buf.String(), `{"a":1`; got != want {
+		t.Errorf("EncodeObjectStream after error:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	enc = NewEncoder(io.Discard)
+	enc.WriteToken(Delim('['))
+	if err := enc.EncodeObjectStream(members); err == nil {
+		t.Error("EncodeObjectStream inside WriteToken array: got nil error, want error")
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	const in = `{"a": 1, "b": {"x": [true]}, "c": [1, 2], "d": {"e": "f"}, "g": "skipped"} null [3]`
+	dec := NewDecoder(strings.NewReader(in))
+	var keys []string
+	var a int
+	var b RawMessage
+	var e string
+	err := dec.DecodeObject(func(key string, dec *Decoder) error {
+		keys = append(keys, key)
+		switch key {
+		case "a":
+			return dec.Decode(&a)
+		case "b":
+			var err error
+			b, err = dec.ReadRaw()
+			b = bytes.Clone(b)
+			return err
+		case "c":
+			for range 4 {
+				if _, err := dec.Token(); err != nil {
+					return err
+				}
+			}
+		case "d":
+			return dec.DecodeObject(func(key string, dec *Decoder) error {
+				return dec.Decode(&e)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeObject error: %v", err)
+	}
+	if want := []string{"a", "b", "c", "d", "g"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("DecodeObject keys:\n\tgot:  %q\n\twant: %q", keys, want)
+	}
+	if a != 1 || string(b) != `{"x": [true]}` || e != "f" {
+		t.Errorf("DecodeObject values: a=%d, b=%s, e=%q", a, b, e)
+	}
+
+	called := false
+	if err := dec.DecodeObject(func(string, *Decoder) error { called = true; return nil }); err != nil || called {
+		t.Errorf("DecodeObject of null = %v, called %v, want nil, false", err, called)
+	}
+	err = dec.DecodeObject(func(string, *Decoder) error { return nil })
+	var terr *UnmarshalTypeError
+	if !errors.As(err, &terr) || terr.Value != "array" {
+		t.Errorf("DecodeObject of array: got %v, want UnmarshalTypeError", err)
+	}
+	var rest []int
+	if err := dec.Decode(&rest); err != nil || len(rest) != 1 {
+		t.Errorf("Decode after DecodeObject of array = %v, %v, want [3], nil", rest, err)
+	}
+	if err := dec.DecodeObject(func(string, *Decoder) error { return nil }); err != io.EOF {
+		t.Errorf("DecodeObject at end: got %v, want io.EOF", err)
+	}
+}
+
+func TestDecodeObjectErrors(t *testing.T) {
+	errStop := errors.New("stop")
+	tests := []struct {
+		CaseName
+		in     string
+		policy DuplicateKeyPolicy
+		fn     func(key string, dec *Decoder) error
+		keys   []string
+		err    string
+	}{{
+		CaseName: Name("callback error"),
+		in:       `{"a":1,"b":2}`,
+		fn:       func(string, *Decoder) error { return errStop },
+		keys:     []string{"a"},
+		err:      "stop",
+	}, {
+		CaseName: Name("partial value"),
+		in:       `{"a":[1,2],"b":2}`,
+		fn: func(_ string, dec *Decoder) error {
+			_, err := dec.Token()
+			return err
+		},
+		keys: []string{"a"},
+		err:  `json: DecodeObject callback for key "a" did not read the whole value`,
+	}, {
+		CaseName: Name("truncated"),
+		in:       `{"a":1,`,
+		keys:     []string{"a"},
+		err:      io.ErrUnexpectedEOF.Error(),
+	}, {
+		CaseName: Name("first wins"),
+		in:       `{"a":1,"b":2,"a":3}`,
+		policy:   DuplicateKeysFirstWins,
+		keys:     []string{"a", "b"},
+	}, {
+		CaseName: Name("duplicate error"),
+		in:       `{"a":1,"a":2,"b":3,"b":4}`,
+		policy:   DuplicateKeysError,
+		keys:     []string{"a", "b"},
+		err:      `json: duplicate key "a" in object`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.in))
+			dec.SetDuplicateKeyPolicy(tt.policy)
+			var keys []string
+			err := dec.DecodeObject(func(key string, dec *Decoder) error {
+				keys = append(keys, key)
+				if tt.fn != nil {
+					return tt.fn(key, dec)
+				}
+				return nil
+			})
+			if errStr := errString(err); errStr != tt.err {
+				t.Errorf("%s: DecodeObject error:\n\tgot:  %s\n\twant: %s", tt.Where, errStr, tt.err)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("%s: DecodeObject keys:\n\tgot:  %q\n\twant: %q", tt.Where, keys, tt.keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package json
+
+import "reflect"
+
+// MarshalOptions configures how Go values are encoded as JSON.
+// The zero value encodes values exactly like [Marshal].
+type MarshalOptions struct {
+	// OmitUnset causes struct fields holding a nil pointer, interface, map,
+	// or slice to be omitted from the encoding, as if they were tagged optional.
+	// Fields tagged optional or nullable keep their usual behavior, so a
+	// nullable field explicitly set to null still encodes as null.
+	//
+	// This is convenient for building partial-update (PATCH) request bodies,
+	// where only the fields that were set should be sent.
+	OmitUnset bool
+
+	// Mask, if non-empty, restricts the encoding to the object members it
+	// selects, for example to produce a partial response.
+	Mask FieldMask
+
+	// Include, if non-empty, restricts the encoding to the object members
+	// whose paths match one of its patterns, and the members enclosing them.
+	// Exclude omits the members whose paths match one of its patterns,
+	// even if included. Either can shape a response per call, for example
+	// by role, without a struct type for each view.
+	//
+	// A pattern is a path of object keys separated by dots, as for a
+	// [FieldMask], that matches the member it names and everything below it.
+	// Each key may be a glob in which '*' matches any sequence of characters
+	// and '?' matches one character; a key of "**" matches any number of
+	// nested members. A backslash makes the character after it, such as
+	// '.' or '*', match itself. For example, "user.secrets.*" matches every
+	// member of the "secrets" object in the "user" object, and
+	// "**.password" matches every "password" member at any depth.
+	// Arrays are passed through unchanged, as for a FieldMask.
+	Include, Exclude []string
+
+	// Cycles selects how a value that contains itself is encoded, rather
+	// than be encoded endlessly. A pointer, map, or slice is in a cycle if it
+	// is encoded within itself; one that appears more than once elsewhere,
+	// such as two fields pointing to the same struct, is encoded each time.
+	Cycles CyclePolicy
+
+	// DetectCyclesAfter, if positive, is the number of nested pointers,
+	// maps, and slices encoded before cycles are looked for. If zero, it is
+	// 1000 with CycleError, so that values of a normal depth are encoded
+	// without the cost of looking for cycles, and 0 with the other
+	// policies, so that a cycle is broken at its first repetition.
+	DetectCyclesAfter int
+
+	// VerifyEnums causes the values of struct fields with an enum tag option
+	// to be checked against the allowed values before they are encoded.
+	// Encoding fails with an error if a value is not allowed.
+	VerifyEnums bool
+
+	// Interchange restricts the output to the I-JSON profile (RFC 7493),
+	// for exchange with systems that may not accept arbitrary JSON.
+	// Strings that are not valid UTF-8 are reported as an
+	// [UnsupportedValueError] instead of being coerced, and output from
+	// [Marshaler] implementations or in a [RawMessage] is checked as
+	// described for [UnmarshalOptions.Interchange].
+	Interchange bool
+
+	// TypedInterfaces causes non-nil interface values, such as struct fields
+	// of interface type, to be encoded as an object naming the dynamic type
+	// of the value, {"$type":"name","value":...}, where name is the name
+	// the type was registered with by [RegisterType].
+	// Values of unregistered types are encoded as usual if the interface has
+	// no methods, as they decode back into such an interface; otherwise,
+	// encoding fails with an error.
+	TypedInterfaces bool
+
+	// VerbatimRawMessages causes the contents of a [RawMessage] to be written
+	// as they are, rather than compacted. With [MarshalOptions.MarshalIndent],
+	// the contents are not reindented either: each of their lines after the
+	// first is prefixed with the indentation at the position of the value,
+	// so that pre-rendered fragments keep their layout. The contents are
+	// still checked to be valid JSON, but not escaped for HTML.
+	VerbatimRawMessages bool
+
+	// EscapeRune, if non-nil, is called for each character inside the JSON
+	// strings of the output that is not otherwise escaped, and the character
+	// is written as a \u escape if it returns true. This is for consumers
+	// that mishandle some characters, such as "\u2028" or emoji, beyond those
+	// escaped for HTML. The strings produced by [Marshaler] implementations
+	// and held in a [RawMessage] are escaped too.
+	EscapeRune func(r rune) bool
+
+	// ContextValues, if non-nil, holds the time zone and clock that the
+	// encodings of time.Time values use. See [ContextValues].
+	ContextValues *ContextValues
+
+	view string // set by View
+}
+
+func (o MarshalOptions) encOpts() encOpts {
+	return encOpts{
+		escapeHTML: true,
+		omitUnset:  o.OmitUnset,
+		mask:       o.Mask.tree(),
+		filter:     newPathFilter(o.Include, o.Exclude),
+
+		detectCyclesAfter: o.DetectCyclesAfter,
+		cycles:            o.Cycles,
+		verifyEnums:       o.VerifyEnums,
+		interchange:       o.Interchange,
+
+		typedInterfaces: o.TypedInterfaces,
+		verbatimRaw:     o.VerbatimRawMessages,
+		ctx:             o.ContextValues,
+		view:            o.view,
+	}
+}
+
+// UnmarshalOptions configures how JSON is decoded into Go values.
+// The zero value decodes values exactly like [Unmarshal].
+type UnmarshalOptions struct {
+	// UseNumber causes numbers to be decoded into an interface{} as a
+	// [Number] instead of as a float64. See [Decoder.UseNumber].
+	UseNumber bool
+
+	// UseInt64 causes numbers without a fraction or exponent to be decoded
+	// into an interface{} as an int64, if they are in its range. Other numbers
+	// are decoded as selected by UseNumber.
+	UseInt64 bool
+
+	// UseOrderedObjects causes objects to be decoded into an interface{} as an
+	// [OrderedObject] instead of as a map[string]interface{}, so that the order
+	// of their members is kept. With DuplicateKeysLastWins, a duplicate key
+	// replaces the value of the first member with that key.
+	UseOrderedObjects bool
+
+	// UseRawArrays causes arrays to be decoded into an interface{} as a
+	// []RawMessage holding the encodings of the elements, which are not
+	// decoded, instead of as a []interface{}.
+	UseRawArrays bool
+
+	// DisallowUnknownFields causes an error to be returned when the destination
+	// is a struct and the input contains object keys which do not match any
+	// non-ignored, exported fields in the destination.
+	// See [Decoder.DisallowUnknownFields].
+	DisallowUnknownFields bool
+
+	// Mask, if non-empty, restricts decoding to the object members it selects,
+	// for example to accept only the updatable fields of a resource.
+	// Other members are skipped as if the destination had no such field;
+	// they are not reported by DisallowUnknownFields.
+	Mask FieldMask
+
+	// Positions, if non-nil, is filled in with the position in the input of
+	// every value, so that errors found after decoding, such as by validating
+	// the result, can be reported at the value's location in the source.
+	// Positions are recorded before decoding starts, even if it fails.
+	Positions Positions
+
+	// DuplicateKeys selects how objects with duplicate keys are decoded.
+	// See [Decoder.SetDuplicateKeyPolicy].
+	DuplicateKeys DuplicateKeyPolicy
+
+	// DuplicateKeyFilter, if non-nil, bounds the memory used to find
+	// duplicate keys in large objects. See [DuplicateKeyFilter].
+	DuplicateKeyFilter *DuplicateKeyFilter
+
+	// Interchange restricts the input to the I-JSON profile (RFC 7493).
+	// A [SyntaxError] is returned if a string holds invalid UTF-8 or a \u
+	// escape for an unpaired UTF-16 surrogate, which would otherwise be
+	// replaced by U+FFFD, if a number is too large to be represented in
+	// IEEE 754 double precision, or if an object has duplicate keys,
+	// regardless of DuplicateKeys. As with [Unmarshal], any data after the
+	// top-level value is an error.
+	Interchange bool
+
+	// RepairSurrogates causes each \u escape for an unpaired UTF-16
+	// surrogate in a string to be decoded as if it were \ufffd: into
+	// U+FFFD in a Go string, and as \ufffd in a [RawMessage]. Such escapes
+	// are then accepted with Interchange, instead of being an error.
+	RepairSurrogates bool
+
+	// Warnings, if non-nil, is appended a [Warning] for each issue with the
+	// input that does not make decoding fail, in the order of the input:
+	// object members ignored for matching no struct field, or matching one
+	// only case-insensitively, strings with invalid UTF-8 or unpaired
+	// surrogate escapes, which decode as U+FFFD, and numbers adjusted to fit
+	// as selected by Overflow. Unknown fields are not warned about when
+	// DisallowUnknownFields makes them an error.
+	Warnings *[]Warning
+
+	// TypedInterfaces causes objects with a "$type" member, as encoded with
+	// [MarshalOptions.TypedInterfaces], to be decoded into an interface value
+	// by decoding their "value" member into a new value of the type registered
+	// under that name with [RegisterType]. The interface value may have
+	// methods, as long as the registered type implements them. Other objects
+	// are decoded as usual.
+	TypedInterfaces bool
+
+	// MissingFields, if non-nil, is appended a [MissingField] for each field
+	// of a struct decoded from an object that has no member for the field,
+	// in the order of the input, so that fields no longer sent by the
+	// producer of the input can be found. Fields of structs nested in a
+	// missing field are not reported, nor are fields excluded by Mask.
+	MissingFields *[]MissingField
+
+	// CaseSensitive causes object keys to match the names of struct fields
+	// only exactly, rather than also case-insensitively.
+	CaseSensitive bool
+
+	// RejectInvalidUTF8 causes a [SyntaxError] to be returned if a string
+	// holds invalid UTF-8 or a \u escape for an unpaired UTF-16 surrogate,
+	// which would otherwise be replaced by U+FFFD. With RepairSurrogates,
+	// such escapes are accepted.
+	RejectInvalidUTF8 bool
+
+	// DisallowNull causes an [UnmarshalTypeError] for a JSON null decoded
+	// into a Go value that cannot be nil, such as a string, number, or
+	// struct, which null otherwise leaves unchanged.
+	DisallowNull bool
+
+	// EmptyAsNull causes an empty JSON string to be decoded as null into a
+	// pointer, a [Null], or a time.Time, for input that sends "" where null
+	// is meant. A struct field with the "emptyasnull" tag option does so
+	// whatever its type; see [Unmarshal].
+	EmptyAsNull bool
+
+	// Overflow selects how a number is decoded into an integer or
+	// floating-point value whose type cannot represent it.
+	// See [Decoder.SetOverflowPolicy].
+	Overflow OverflowPolicy
+
+	// AllowTrailingData causes any data after the top-level value to be
+	// ignored, rather than be a syntax error.
+	AllowTrailingData bool
+
+	// ContextValues, if non-nil, holds the time zone and clock that the
+	// format options of time.Time fields use. See [ContextValues].
+	ContextValues *ContextValues
+
+	// Profile selects a set of the options above that decode input as
+	// strictly as needed. Options set individually add to those of the
+	// profile: the profile cannot turn options off.
+	Profile Profile
+}
+
+// A DuplicateKeyPolicy selects how an object with more than one member
+// with the same key is decoded, whether into a struct, a map, or an
+// interface value. For structs, keys naming the same field, such as
+// "name" and "Name", are duplicates.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysLastWins decodes every member, so that the last of the
+	// duplicates determines the result. This is the behavior of [Unmarshal].
+	DuplicateKeysLastWins DuplicateKeyPolicy = iota
+
+	// DuplicateKeysFirstWins decodes the first of the duplicates and skips the rest.
+	DuplicateKeysFirstWins
+
+	// DuplicateKeysError reports duplicate keys as an error.
+	// As with type errors, decoding continues, keeping the first of the duplicates.
+	DuplicateKeysError
+)
+
+// Unmarshal is like the package-level [Unmarshal] but decodes as configured by o.
+func (o UnmarshalOptions) Unmarshal(data []byte, v any) error {
+	return o.UnmarshalValue(data, reflect.ValueOf(v))
+}
+
+// UnmarshalValue is like the package-level [UnmarshalValue] but decodes as
+// configured by o.
+func (o UnmarshalOptions) UnmarshalValue(data []byte, v reflect.Value) error {
+	o = o.withProfile()
+
+	// Check for well-formedness.
+	// Avoids filling out half a data structure
+	// before discovering a JSON syntax error.
+	var d decodeState
+	var err error
+	if o.AllowTrailingData {
+		data, err = leadingValue(data, &d.scan)
+	} else {
+		err = checkValid(data, &d.scan)
+	}
+	if err != nil {
+		return err
+	}
+	var surrogates, invalid []int
+	if o.Warnings != nil {
+		// Find the replaced characters before any surrogates are repaired.
+		surrogates, invalid = replacedText(data)
+	}
+	if o.RepairSurrogates {
+		data, _ = repairSurrogates(data)
+	}
+	if o.RejectInvalidUTF8 {
+		if err := checkUTF8(data); err != nil {
+			return err
+		}
+	}
+	if o.Interchange {
+		if err := checkInterchange(data); err != nil {
+			return err
+		}
+	}
+	if o.Positions != nil {
+		o.Positions.record(data)
+	}
+
+	d.init(data)
+	o.apply(&d)
+	if o.Warnings == nil && o.MissingFields == nil {
+		return d.unmarshalValue(v)
+	}
+	var warned, missing int
+	if o.Warnings != nil {
+		warned = len(*o.Warnings)
+		for _, off := range surrogates {
+			d.warn(off, ErrUnpairedSurrogate)
+		}
+		for _, off := range invalid {
+			d.warn(off, ErrInvalidUTF8)
+		}
+	}
+	if o.MissingFields != nil {
+		missing = len(*o.MissingFields)
+	}
+	err = d.unmarshalValue(v)
+	if o.Warnings != nil {
+		resolveWarnings(data, (*o.Warnings)[warned:])
+	}
+	if o.MissingFields != nil {
+		resolveMissingFields(data, (*o.MissingFields)[missing:])
+	}
+	return err
+}
+
+// apply configures d to decode as described by o.
+func (o UnmarshalOptions) apply(d *decodeState) {
+	d.useNumber = o.UseNumber
+	d.useInt64 = o.UseInt64
+	d.useOrderedObjects = o.UseOrderedObjects
+	d.useRawArrays = o.UseRawArrays
+	d.disallowUnknownFields = o.DisallowUnknownFields
+	d.fieldMask = o.Mask.tree()
+	d.duplicateKeys = o.DuplicateKeys
+	d.duplicateKeyFilter = o.DuplicateKeyFilter
+	d.typedInterfaces = o.TypedInterfaces
+	d.warnings = o.Warnings
+	d.caseSensitive = o.CaseSensitive
+	d.disallowNull = o.DisallowNull
+	d.missingFields = o.MissingFields
+	d.overflow = o.Overflow
+	d.emptyAsNull = o.EmptyAsNull
+	d.ctx = o.ContextValues
+}
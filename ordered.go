@@ -0,0 +1,68 @@
+package json
+
+import "reflect"
+
+// An OrderedObject is a JSON object whose members are kept in the order in
+// which they appear in the input. Decoding into an interface value produces
+// OrderedObjects instead of maps if [UnmarshalOptions.UseOrderedObjects]
+// is set, and an OrderedObject encodes its members in order.
+type OrderedObject []ObjectMember
+
+// An ObjectMember is a member of an [OrderedObject].
+type ObjectMember struct {
+	Key   string
+	Value any
+}
+
+// Get returns the value of the member of o with the given key,
+// and whether there is such a member.
+func (o OrderedObject) Get(key string) (any, bool) {
+	for _, m := range o {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON encodes o as a JSON object with its members in order, its
+// keys escaped as [Marshal] escapes its values. A nil OrderedObject
+// encodes as null.
+func (o OrderedObject) MarshalJSON() ([]byte, error) {
+	if o == nil {
+		return []byte("null"), nil
+	}
+	b := []byte{'{'}
+	for i, m := range o {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, m.Key, true)
+		b = append(b, ':')
+		v, err := Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, v...)
+	}
+	return append(b, '}'), nil
+}
+
+// UnmarshalJSON decodes a JSON object into o, keeping the order of its
+// members. Nested objects are decoded as OrderedObjects too.
+// Decoding null sets o to nil.
+func (o *OrderedObject) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := (UnmarshalOptions{UseOrderedObjects: true}).Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		*o = nil
+	case OrderedObject:
+		*o = v
+	default:
+		return &UnmarshalTypeError{Value: valueKind(data), Type: reflect.TypeFor[OrderedObject]()}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOrderedObjectRoundTrip(t *testing.T) {
+	const in = `{"z":1,"a":{"y":"<",  "b":[true,{"q":null}]},"m":[],"<k>":0}`
+	var o OrderedObject
+	if err := Unmarshal([]byte(in), &o); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if v, ok := o.Get("a"); !ok || reflect.TypeOf(v) != reflect.TypeFor[OrderedObject]() {
+		t.Errorf("Get(%q) = %#v, %v, want an OrderedObject", "a", v, ok)
+	}
+	if _, ok := o.Get("missing"); ok {
+		t.Errorf("Get(%q) reports a member", "missing")
+	}
+	got, err := Marshal(o)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	const want = `{"z":1,"a":{"y":"\u003c","b":[true,{"q":null}]},"m":[],"\u003ck\u003e":0}`
+	if string(got) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestOrderedObjectNull(t *testing.T) {
+	o := OrderedObject{{"a", 1}}
+	if err := Unmarshal([]byte("null"), &o); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if o != nil {
+		t.Errorf("Unmarshal(null) = %v, want nil", o)
+	}
+	if b, err := Marshal(struct{ O OrderedObject }{}); err != nil || string(b) != `{"O":null}` {
+		t.Errorf("Marshal = %s, %v, want %s", b, err, `{"O":null}`)
+	}
+	var ute *UnmarshalTypeError
+	if err := Unmarshal([]byte(`[1]`), &o); !errors.As(err, &ute) || ute.Value != "array" {
+		t.Errorf("Unmarshal([1]) error: %v, want UnmarshalTypeError for array", err)
+	}
+	if err := o.UnmarshalJSON([]byte(`"s"`)); !errors.As(err, &ute) || ute.Value != "string" {
+		t.Errorf(`UnmarshalJSON("s") error: %v, want UnmarshalTypeError for string`, err)
+	}
+}
@@ -0,0 +1,81 @@
+package json
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// An OverflowPolicy selects how a JSON number is decoded into a Go integer
+// or floating-point value whose type cannot represent it, such as 300 into
+// an int8 or -1 into a uint.
+type OverflowPolicy int
+
+const (
+	// OverflowError reports the number as an [UnmarshalTypeError] and
+	// leaves the value unchanged. This is the behavior of [Unmarshal].
+	OverflowError OverflowPolicy = iota
+
+	// OverflowClamp stores the minimum or maximum value of the type,
+	// whichever is nearer the number.
+	OverflowClamp
+
+	// OverflowWrap stores the number modulo 2ⁿ for an n-bit integer type,
+	// as a Go conversion of an integer constant would if it were allowed.
+	// Floating-point numbers out of range are clamped.
+	OverflowWrap
+)
+
+// maxUint64 is the mask for the low 64 bits of a big.Int.
+var maxUint64 = new(big.Int).SetUint64(math.MaxUint64)
+
+// storeOverflow stores the number item, which does not fit the integer or
+// floating-point value v, as selected by d.overflow, reporting whether it
+// did. It does not for a number that is out of range only for having a
+// fraction or exponent, such as 1.5 or 1e3 for an integer. off is the
+// offset of the number, for a warning.
+func (d *decodeState) storeOverflow(item []byte, v reflect.Value, off int) bool {
+	if d.overflow == OverflowError {
+		return false
+	}
+	bits := v.Type().Bits()
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, ok := new(big.Int).SetString(string(item), 10)
+		switch {
+		case !ok:
+			return false
+		case d.overflow == OverflowWrap:
+			// SetInt keeps the low bits that fit v.
+			v.SetInt(int64(x.And(x, maxUint64).Uint64()))
+		case x.Sign() < 0:
+			v.SetInt(math.MinInt64 >> (64 - bits))
+		default:
+			v.SetInt(math.MaxInt64 >> (64 - bits))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, ok := new(big.Int).SetString(string(item), 10)
+		switch {
+		case !ok:
+			return false
+		case d.overflow == OverflowWrap:
+			v.SetUint(x.And(x, maxUint64).Uint64())
+		case x.Sign() <= 0:
+			v.SetUint(0)
+		default:
+			v.SetUint(math.MaxUint64 >> (64 - bits))
+		}
+	case reflect.Float32, reflect.Float64:
+		f, _ := strconv.ParseFloat(string(item), 64)
+		max := math.MaxFloat64
+		if bits == 32 {
+			max = math.MaxFloat32
+		}
+		v.SetFloat(math.Copysign(max, f))
+	default:
+		return false
+	}
+	d.warn(off, ErrNumberOverflow)
+	return true
+}
@@ -0,0 +1,85 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type overflowValues struct {
+	I8  int8    `json:"i8"`
+	I64 int64   `json:"i64"`
+	U8  uint8   `json:"u8"`
+	U   uint    `json:"u"`
+	F32 float32 `json:"f32"`
+	F64 float64 `json:"f64"`
+	Q   int8    `json:"q,string"`
+}
+
+func TestUnmarshalOverflow(t *testing.T) {
+	in := `{"i8": 300, "i64": -1e0, "u8": -1, "u": 18446744073709551621, "f32": -1e39, "f64": 1e400, "q": "-129"}`
+	tests := []struct {
+		CaseName
+		policy OverflowPolicy
+		want   overflowValues
+	}{{
+		CaseName: Name("clamp"),
+		policy:   OverflowClamp,
+		want:     overflowValues{I8: 127, U8: 0, U: math.MaxUint64, F32: -math.MaxFloat32, F64: math.MaxFloat64, Q: -128},
+	}, {
+		CaseName: Name("wrap"),
+		policy:   OverflowWrap,
+		want:     overflowValues{I8: 44, U8: 255, U: 5, F32: -math.MaxFloat32, F64: math.MaxFloat64, Q: 127},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var warnings []Warning
+			var got overflowValues
+			err := UnmarshalOptions{Overflow: tt.policy, Warnings: &warnings}.Unmarshal([]byte(in), &got)
+			// -1e0 has an exponent, so it is still an error for an integer.
+			var ute *UnmarshalTypeError
+			if !errors.As(err, &ute) || ute.Field != "i64" {
+				t.Errorf("%s: Unmarshal error: got %v, want an UnmarshalTypeError for i64", tt.Where, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s: Unmarshal:\n\tgot:  %+v\n\twant: %+v", tt.Where, got, tt.want)
+			}
+			wantWarnings := []Warning{
+				{"/i8", 7, ErrNumberOverflow},
+				{"/u8", 31, ErrNumberOverflow},
+				{"/u", 40, ErrNumberOverflow},
+				{"/f32", 69, ErrNumberOverflow},
+				{"/f64", 83, ErrNumberOverflow},
+				{"/q", 100, ErrNumberOverflow},
+			}
+			if !reflect.DeepEqual(warnings, wantWarnings) {
+				t.Errorf("%s: Unmarshal warnings:\n\tgot:  %+v\n\twant: %+v", tt.Where, warnings, wantWarnings)
+			}
+		})
+	}
+}
+
+func TestUnmarshalOverflowError(t *testing.T) {
+	var v overflowValues
+	err := Unmarshal([]byte(`{"i8": 300}`), &v)
+	var ute *UnmarshalTypeError
+	if !errors.As(err, &ute) || v.I8 != 0 {
+		t.Errorf("Unmarshal = %+v, %v, want an UnmarshalTypeError", v, err)
+	}
+}
+
+func TestDecoderSetOverflowPolicy(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[256, 1.5]`))
+	dec.SetOverflowPolicy(OverflowWrap)
+	var got []uint8
+	err := dec.Decode(&got)
+	var ute *UnmarshalTypeError
+	if !errors.As(err, &ute) {
+		t.Errorf("Decode error: got %v, want an UnmarshalTypeError for 1.5", err)
+	}
+	if want := []uint8{0, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,161 @@
+package json
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A Patch is a JSON Patch document as defined by RFC 6902.
+type Patch []PatchOperation
+
+// A PatchOperation is a single operation of a [Patch].
+// Path and From are JSON Pointers as defined by RFC 6901.
+type PatchOperation struct {
+	Op    string     `json:"op"`
+	Path  string     `json:"path"`
+	From  string     `json:"from,omitempty"`
+	Value RawMessage `json:"value,omitempty"`
+}
+
+// DiffOptions configures [DiffOptions.Diff].
+type DiffOptions struct {
+	// ReplaceArrays causes arrays that differ to be replaced by a single
+	// "replace" operation, instead of per-element "add", "remove", and
+	// "replace" operations.
+	ReplaceArrays bool
+
+	// ReplaceObjects causes objects that differ to be replaced by a single
+	// "replace" operation, instead of per-member "add", "remove", and
+	// "replace" operations.
+	ReplaceObjects bool
+}
+
+// Diff returns a patch that transforms the JSON encoding of oldV into the
+// JSON encoding of newV. Both values are encoded with [Marshal], so struct
+// tags, including optional and nullable, are respected.
+//
+// Diff is equivalent to DiffOptions{}.Diff(oldV, newV).
+func Diff(oldV, newV any) (Patch, error) {
+	return DiffOptions{}.Diff(oldV, newV)
+}
+
+// Diff returns a patch that transforms the JSON encoding of oldV into the
+// JSON encoding of newV, as configured by o.
+//
+// Unless o.ReplaceObjects is set, object members are compared recursively
+// and the operations for them are ordered by member name. Elements removed
+// from the end of an array are removed last to first, so that every path
+// in the patch is valid when the operation is applied.
+func (o DiffOptions) Diff(oldV, newV any) (Patch, error) {
+	a, err := marshalToInterface(oldV)
+	if err != nil {
+		return nil, err
+	}
+	b, err := marshalToInterface(newV)
+	if err != nil {
+		return nil, err
+	}
+	p := Patch{}
+	if err := o.diff(&p, "", a, b); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (o DiffOptions) diff(p *Patch, path string, a, b any) error {
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || o.ReplaceObjects {
+			break
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			av, inA := a[k]
+			bv, inB := b[k]
+			kpath := path + "/" + escapePointerToken(k)
+			switch {
+			case !inB:
+				*p = append(*p, PatchOperation{Op: "remove", Path: kpath})
+			case !inA:
+				if err := p.add("add", kpath, bv); err != nil {
+					return err
+				}
+			default:
+				if err := o.diff(p, kpath, av, bv); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case []any:
+		b, ok := b.([]any)
+		if !ok || o.ReplaceArrays {
+			break
+		}
+		n := min(len(a), len(b))
+		for i := 0; i < n; i++ {
+			if err := o.diff(p, path+"/"+strconv.Itoa(i), a[i], b[i]); err != nil {
+				return err
+			}
+		}
+		for i := n; i < len(b); i++ {
+			if err := p.add("add", path+"/"+strconv.Itoa(i), b[i]); err != nil {
+				return err
+			}
+		}
+		for i := len(a) - 1; i >= n; i-- {
+			*p = append(*p, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return nil
+	}
+	return p.add("replace", path, b)
+}
+
+// add appends an operation carrying the encoding of v.
+func (p *Patch) add(op, path string, v any) error {
+	b, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, PatchOperation{Op: op, Path: path, Value: b})
+	return nil
+}
+
+// marshalToInterface returns the JSON encoding of v decoded into an interface
+// value, using Number for numbers so that their encoding is preserved.
+func marshalToInterface(v any) (any, error) {
+	b, err := Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var d decodeState
+	d.init(b)
+	d.useNumber = true
+	var x any
+	if err := d.unmarshal(&x); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+var pointerTokenEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// escapePointerToken escapes a reference token of a JSON Pointer (RFC 6901).
+func escapePointerToken(tok string) string {
+	return pointerTokenEscaper.Replace(tok)
+}
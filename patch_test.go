@@ -0,0 +1,114 @@
+package json
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type Item struct {
+		ID   int      `json:"id"`
+		Note **string `json:"note,optional,nullable"`
+	}
+	type Doc struct {
+		Name  string         `json:"name"`
+		Items []Item         `json:"items"`
+		Attrs map[string]any `json:"attrs"`
+	}
+	var nilString *string
+	note := "hi"
+	notePtr := &note
+
+	tests := []struct {
+		CaseName
+		opts     DiffOptions
+		old, new any
+		want     string
+	}{{
+		CaseName: Name("equal"),
+		old:      Doc{Name: "a"},
+		new:      Doc{Name: "a"},
+		want:     `[]`,
+	}, {
+		CaseName: Name("replace scalar"),
+		old:      1,
+		new:      2,
+		want:     `[{"op":"replace","path":"","value":2}]`,
+	}, {
+		CaseName: Name("objects"),
+		old:      Doc{Name: "a", Attrs: map[string]any{"x/y": 1, "gone": true}},
+		new:      Doc{Name: "b", Attrs: map[string]any{"x/y": 2, "new~": nil}},
+		want: `[{"op":"remove","path":"/attrs/gone"},` +
+			`{"op":"add","path":"/attrs/new~0","value":null},` +
+			`{"op":"replace","path":"/attrs/x~1y","value":2},` +
+			`{"op":"replace","path":"/name","value":"b"}]`,
+	}, {
+		CaseName: Name("optional and nullable"),
+		old:      Doc{Items: []Item{{ID: 1}, {ID: 2, Note: &notePtr}}},
+		new:      Doc{Items: []Item{{ID: 1, Note: &nilString}, {ID: 2}}},
+		want: `[{"op":"add","path":"/items/0/note","value":null},` +
+			`{"op":"remove","path":"/items/1/note"}]`,
+	}, {
+		CaseName: Name("array growth and shrinkage"),
+		old:      []int{1, 2, 3, 4},
+		new:      []int{1, 5},
+		want: `[{"op":"replace","path":"/1","value":5},` +
+			`{"op":"remove","path":"/3"},` +
+			`{"op":"remove","path":"/2"}]`,
+	}, {
+		CaseName: Name("array append"),
+		old:      []int{1},
+		new:      []int{1, 2, 3},
+		want:     `[{"op":"add","path":"/1","value":2},{"op":"add","path":"/2","value":3}]`,
+	}, {
+		CaseName: Name("replace arrays"),
+		opts:     DiffOptions{ReplaceArrays: true},
+		old:      map[string][]int{"a": {1, 2}},
+		new:      map[string][]int{"a": {1, 3}},
+		want:     `[{"op":"replace","path":"/a","value":[1,3]}]`,
+	}, {
+		CaseName: Name("replace objects"),
+		opts:     DiffOptions{ReplaceObjects: true},
+		old:      []map[string]int{{"a": 1, "b": 2}, {"c": 3}, {"d": 4}},
+		new:      []map[string]int{{"a": 1, "b": 3}, {"c": 3}, {}, {"e": 5}},
+		want: `[{"op":"replace","path":"/0","value":{"a":1,"b":3}},` +
+			`{"op":"replace","path":"/2","value":{}},` +
+			`{"op":"add","path":"/3","value":{"e":5}}]`,
+	}, {
+		CaseName: Name("replace arrays and objects"),
+		opts:     DiffOptions{ReplaceArrays: true, ReplaceObjects: true},
+		old:      Doc{Name: "a", Items: []Item{{ID: 1}}},
+		new:      Doc{Name: "a", Items: []Item{{ID: 2}}},
+		want:     `[{"op":"replace","path":"","value":{"attrs":null,"items":[{"id":2}],"name":"a"}}]`,
+	}, {
+		CaseName: Name("type change"),
+		old:      map[string]any{"a": []int{1}},
+		new:      map[string]any{"a": map[string]int{"b": 1}},
+		want:     `[{"op":"replace","path":"/a","value":{"b":1}}]`,
+	}, {
+		CaseName: Name("numbers keep their encoding"),
+		old:      1.5,
+		new:      1e21,
+		want:     `[{"op":"replace","path":"","value":1e+21}]`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p, err := tt.opts.Diff(tt.old, tt.new)
+			if err != nil {
+				t.Fatalf("%s: Diff error: %v", tt.Where, err)
+			}
+			got, err := Marshal(p)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Diff:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffError(t *testing.T) {
+	if _, err := Diff(make(chan int), 1); err == nil {
+		t.Error("Diff(chan) error = nil, want UnsupportedTypeError")
+	}
+}
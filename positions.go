@@ -0,0 +1,124 @@
+package json
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+)
+
+// A Position describes where a value appears in the input.
+type Position struct {
+	Offset int64 // byte offset of the first byte of the value
+	End    int64 // byte offset just past the last byte of the value
+	Line   int   // 1-based line number of Offset
+	Column int   // 1-based column of Offset, counted in bytes
+}
+
+// Positions maps the JSON Pointer (RFC 6901) of each value in the input,
+// such as "" for the top-level value or "/items/0/name", to its position.
+// It is filled in by [UnmarshalOptions.Unmarshal] when set in
+// [UnmarshalOptions.Positions].
+//
+// Since every value in the input is recorded, looking up a path
+// also reports whether the member it names was present.
+type Positions map[string]Position
+
+// record adds the positions of all values in data to p.
+// data must be valid JSON.
+func (p Positions) record(data []byte) {
+	var lines []int // offsets of the start of each line after the first
+	for i, b := 0, data; ; {
+		j := bytes.IndexByte(b, '\n')
+		if j < 0 {
+			break
+		}
+		i += j + 1
+		lines = append(lines, i)
+		b = b[j+1:]
+	}
+
+	var d decodeState
+	d.init(data)
+	d.scan.reset()
+	d.scanWhile(scanSkipSpace)
+	d.recordPositions(p, lines, "")
+}
+
+// recordPositions consumes a JSON value from d.data[d.off-1:] like d.value,
+// recording in p its position and the positions of the values within it.
+func (d *decodeState) recordPositions(p Positions, lines []int, path string) {
+	start := d.readIndex()
+	switch d.opcode {
+	default:
+		panic(phasePanicMsg)
+
+	case scanBeginArray:
+		for i := 0; ; i++ {
+			d.scanWhile(scanSkipSpace)
+			if d.opcode == scanEndArray {
+				break
+			}
+			d.recordPositions(p, lines, path+"/"+strconv.Itoa(i))
+			if d.opcode == scanSkipSpace {
+				d.scanWhile(scanSkipSpace)
+			}
+			if d.opcode == scanEndArray {
+				break
+			}
+			if d.opcode != scanArrayValue {
+				panic(phasePanicMsg)
+			}
+		}
+		d.scanNext()
+
+	case scanBeginObject:
+		for {
+			d.scanWhile(scanSkipSpace)
+			if d.opcode == scanEndObject {
+				break
+			}
+			if d.opcode != scanBeginLiteral {
+				panic(phasePanicMsg)
+			}
+			keyStart := d.readIndex()
+			d.rescanLiteral()
+			key, ok := unquote(d.data[keyStart:d.readIndex()])
+			if !ok {
+				panic(phasePanicMsg)
+			}
+			if d.opcode == scanSkipSpace {
+				d.scanWhile(scanSkipSpace)
+			}
+			if d.opcode != scanObjectKey {
+				panic(phasePanicMsg)
+			}
+			d.scanWhile(scanSkipSpace)
+			d.recordPositions(p, lines, path+"/"+escapePointerToken(key))
+			if d.opcode == scanSkipSpace {
+				d.scanWhile(scanSkipSpace)
+			}
+			if d.opcode == scanEndObject {
+				break
+			}
+			if d.opcode != scanObjectValue {
+				panic(phasePanicMsg)
+			}
+		}
+		d.scanNext()
+
+	case scanBeginLiteral:
+		d.rescanLiteral()
+	}
+
+	line := sort.SearchInts(lines, start+1)
+	lineStart := 0
+	if line > 0 {
+		lineStart = lines[line-1]
+	}
+	p[path] = Position{
+		Offset: int64(start),
+		End:    int64(d.readIndex()),
+		Line:   line + 1,
+		Column: start - lineStart + 1,
+	}
+}
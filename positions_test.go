@@ -0,0 +1,34 @@
+package json
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	const in = "{\"name\": \"x\",\n \"items\": [1, {\"a/b\": null}],\n \"empty\": {}} "
+	type T struct {
+		Name  string `json:"name"`
+		Items []any  `json:"items"`
+	}
+	pos := Positions{}
+	var v T
+	if err := (UnmarshalOptions{Positions: pos}).Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := Positions{
+		"":              {Offset: 0, End: 57, Line: 1, Column: 1},
+		"/name":         {Offset: 9, End: 12, Line: 1, Column: 10},
+		"/items":        {Offset: 24, End: 42, Line: 2, Column: 11},
+		"/items/0":      {Offset: 25, End: 26, Line: 2, Column: 12},
+		"/items/1":      {Offset: 28, End: 41, Line: 2, Column: 15},
+		"/items/1/a~1b": {Offset: 36, End: 40, Line: 2, Column: 23},
+		"/empty":        {Offset: 54, End: 56, Line: 3, Column: 11},
+	}
+	if !reflect.DeepEqual(pos, want) {
+		t.Errorf("Positions:\n\tgot:  %v\n\twant: %v", pos, want)
+	}
+	if _, ok := pos["/missing"]; ok {
+		t.Errorf("Positions reports /missing as present")
+	}
+}
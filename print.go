@@ -0,0 +1,211 @@
+package json
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// PrintOptions configures [PrintOptions.Fprint].
+// The zero value prints indented JSON without colors or abbreviation.
+type PrintOptions struct {
+	// Indent is the indentation for each level of nesting.
+	// If empty, two spaces are used.
+	Indent string
+
+	// Color enables ANSI terminal colors: object keys are printed in
+	// bold blue, strings in green, numbers in cyan, and true, false,
+	// and null in magenta.
+	Color bool
+
+	// MaxString, if positive, is the number of characters of a string value
+	// after which the rest of the string is replaced by "…".
+	// Object keys are never abbreviated.
+	MaxString int
+
+	// MaxDepth, if positive, is the number of levels of objects and arrays
+	// that are printed; deeper objects and arrays are folded into {…} and […].
+	MaxDepth int
+}
+
+const (
+	colorKey     = "\x1b[34;1m"
+	colorString  = "\x1b[32m"
+	colorNumber  = "\x1b[36m"
+	colorLiteral = "\x1b[35m"
+	colorReset   = "\x1b[0m"
+)
+
+// Fprint writes a human-friendly rendering of the JSON text data to w,
+// as configured by opts. It is intended for command-line tools that
+// display JSON to people.
+//
+// Fprint is equivalent to opts.Fprint(w, data).
+func Fprint(w io.Writer, data []byte, opts PrintOptions) error {
+	return opts.Fprint(w, data)
+}
+
+// Fprint writes a human-friendly rendering of the JSON text data to w.
+// Objects and arrays are indented, and every top-level value is followed by
+// a newline, so data may hold a sequence of values as read by a [Decoder].
+//
+// Unless abbreviated, strings and numbers are printed as they appear in data,
+// so the output is valid JSON if o.MaxString and o.MaxDepth are zero and
+// o.Color is false. If data is not valid JSON, Fprint returns a
+// [SyntaxError] and writes nothing.
+func (o PrintOptions) Fprint(w io.Writer, data []byte) error {
+	if o.Indent == "" {
+		o.Indent = "  "
+	}
+	p := printer{opts: o, tok: NewTokenizer(data)}
+	if err := p.print(); err != nil {
+		return err
+	}
+	_, err := w.Write(p.buf)
+	return err
+}
+
+type printer struct {
+	opts  PrintOptions
+	tok   *Tokenizer
+	buf   []byte
+	stack []printFrame
+}
+
+// A printFrame is an object or array being printed.
+type printFrame struct {
+	object bool
+	n      int // tokens printed, counting both keys and values of objects
+}
+
+func (p *printer) print() error {
+	for {
+		kind, raw, err := p.tok.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if kind == EndObjectToken || kind == EndArrayToken {
+			f := p.stack[len(p.stack)-1]
+			p.stack = p.stack[:len(p.stack)-1]
+			if f.n > 0 {
+				p.newline()
+			}
+			p.buf = append(p.buf, raw...)
+			p.endValue()
+			continue
+		}
+
+		if len(p.stack) > 0 {
+			f := &p.stack[len(p.stack)-1]
+			isKey := f.object && f.n%2 == 0
+			if isKey || !f.object {
+				if f.n > 0 {
+					p.buf = append(p.buf, ',')
+				}
+				p.newline()
+			}
+			f.n++
+			if isKey {
+				p.colored(colorKey, raw)
+				p.buf = append(p.buf, ':', ' ')
+				continue
+			}
+		}
+
+		switch kind {
+		case BeginObjectToken, BeginArrayToken:
+			if p.opts.MaxDepth > 0 && len(p.stack) >= p.opts.MaxDepth {
+				if err := p.fold(); err != nil {
+					return err
+				}
+				if kind == BeginObjectToken {
+					p.buf = append(p.buf, "{…}"...)
+				} else {
+					p.buf = append(p.buf, "[…]"...)
+				}
+				p.endValue()
+				continue
+			}
+			p.stack = append(p.stack, printFrame{object: kind == BeginObjectToken})
+			p.buf = append(p.buf, raw...)
+			continue
+		case StringToken:
+			p.colored(colorString, truncateString(raw, p.opts.MaxString))
+		case NumberToken:
+			p.colored(colorNumber, raw)
+		default:
+			p.colored(colorLiteral, raw)
+		}
+		p.endValue()
+	}
+}
+
+// fold skips the rest of the object or array whose opening delimiter
+// was just read.
+func (p *printer) fold() error {
+	for depth := 1; depth > 0; {
+		kind, _, err := p.tok.Next()
+		if err != nil { // not io.EOF, within a value
+			return err
+		}
+		switch kind {
+		case BeginObjectToken, BeginArrayToken:
+			depth++
+		case EndObjectToken, EndArrayToken:
+			depth--
+		}
+	}
+	return nil
+}
+
+// endValue ends the line after a complete top-level value.
+func (p *printer) endValue() {
+	if len(p.stack) == 0 {
+		p.buf = append(p.buf, '\n')
+	}
+}
+
+func (p *printer) newline() {
+	p.buf = append(p.buf, '\n')
+	for range len(p.stack) {
+		p.buf = append(p.buf, p.opts.Indent...)
+	}
+}
+
+func (p *printer) colored(color string, raw []byte) {
+	if !p.opts.Color {
+		p.buf = append(p.buf, raw...)
+		return
+	}
+	p.buf = append(p.buf, color...)
+	p.buf = append(p.buf, raw...)
+	p.buf = append(p.buf, colorReset...)
+}
+
+// truncateString abbreviates the string literal raw to n characters,
+// keeping escape sequences whole, if n is positive and raw is longer.
+func truncateString(raw []byte, n int) []byte {
+	if n <= 0 {
+		return raw
+	}
+	i := 1
+	for ; n > 0 && i < len(raw)-1; n-- {
+		switch {
+		case raw[i] == '\\' && raw[i+1] == 'u':
+			i += 6
+		case raw[i] == '\\':
+			i += 2
+		default:
+			_, size := utf8.DecodeRune(raw[i:])
+			i += size
+		}
+	}
+	if i >= len(raw)-1 {
+		return raw
+	}
+	b := make([]byte, 0, i+len(`…"`))
+	b = append(b, raw[:i]...)
+	return append(b, `…"`...)
+}
@@ -0,0 +1,114 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFprint(t *testing.T) {
+	const in = ` {"name": "abcdef", "tags": [], "nested": {"a": [1, {"b": null}], "c": {}}, "té": true} "x" `
+	tests := []struct {
+		CaseName
+		opts PrintOptions
+		want string
+	}{{
+		CaseName: Name("Default"),
+		want: `{
+  "name": "abcdef",
+  "tags": [],
+  "nested": {
+    "a": [
+      1,
+      {
+        "b": null
+      }
+    ],
+    "c": {}
+  },
+  "té": true
+}
+"x"
+`,
+	}, {
+		CaseName: Name("IndentAndDepth"),
+		opts:     PrintOptions{Indent: "\t", MaxDepth: 2},
+		want: `{
+	"name": "abcdef",
+	"tags": [],
+	"nested": {
+		"a": […],
+		"c": {…}
+	},
+	"té": true
+}
+"x"
+`,
+	}, {
+		CaseName: Name("MaxString"),
+		opts:     PrintOptions{MaxString: 3, MaxDepth: 1},
+		want: `{
+  "name": "abc…",
+  "tags": […],
+  "nested": {…},
+  "té": true
+}
+"x"
+`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Fprint(&buf, []byte(in), tt.opts); err != nil {
+				t.Fatalf("%s: Fprint error: %v", tt.Where, err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("%s: Fprint:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFprintColor(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Fprint(&buf, []byte(`{"k":["s",1,false]}`), PrintOptions{Color: true, Indent: " "}); err != nil {
+		t.Fatalf("Fprint error: %v", err)
+	}
+	want := "{\n \x1b[34;1m\"k\"\x1b[0m: [\n  \x1b[32m\"s\"\x1b[0m,\n  \x1b[36m1\x1b[0m,\n  \x1b[35mfalse\x1b[0m\n ]\n}\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Fprint:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+}
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{`"abc"`, 3, `"abc"`},
+		{`"abcd"`, 3, `"abc…"`},
+		{`"a\nbéc"`, 3, `"a\nb…"`},
+		{`"aéb"`, 2, `"aé…"`},
+		{`"ééé"`, 2, `"éé…"`},
+	}
+	for _, tt := range tests {
+		if got := string(truncateString([]byte(tt.in), tt.n)); got != tt.want {
+			t.Errorf("truncateString(%s, %d) = %s, want %s", tt.in, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFprintSyntaxError(t *testing.T) {
+	var buf bytes.Buffer
+	for _, in := range []string{`{"a": [1, 2}`, `[{"a": [1]`} {
+		err := Fprint(&buf, []byte(in), PrintOptions{MaxDepth: 1})
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("Fprint(%s) error: %v, want SyntaxError", in, err)
+		}
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Fprint wrote %q on error", buf.String())
+	}
+}
@@ -0,0 +1,83 @@
+package json
+
+// A Profile is a named set of [UnmarshalOptions] for how strictly the input
+// is checked, so that the options fit together.
+type Profile int
+
+const (
+	// ProfileDefault decodes as [Unmarshal]: object members matching no
+	// struct field are ignored, the last of duplicate keys wins, keys match
+	// field names case-insensitively, invalid UTF-8 decodes as U+FFFD, null
+	// leaves values that cannot be nil unchanged, and data after the
+	// top-level value is an error.
+	ProfileDefault Profile = iota
+
+	// ProfilePermissive is like ProfileDefault, but also ignores data after
+	// the top-level value, as with AllowTrailingData.
+	ProfilePermissive
+
+	// ProfileStrict rejects all of the above: it sets DisallowUnknownFields,
+	// CaseSensitive, RejectInvalidUTF8, and DisallowNull, and sets
+	// DuplicateKeys to DuplicateKeysError, unless another policy is set.
+	ProfileStrict
+
+	// ProfileInterchange is like ProfileStrict, but also restricts the input
+	// to the I-JSON profile (RFC 7493), as with Interchange.
+	ProfileInterchange
+)
+
+// withProfile returns o with the options of its profile set.
+func (o UnmarshalOptions) withProfile() UnmarshalOptions {
+	switch o.Profile {
+	case ProfilePermissive:
+		o.AllowTrailingData = true
+	case ProfileStrict, ProfileInterchange:
+		o.DisallowUnknownFields = true
+		o.CaseSensitive = true
+		o.RejectInvalidUTF8 = true
+		o.DisallowNull = true
+		if o.DuplicateKeys == DuplicateKeysLastWins {
+			o.DuplicateKeys = DuplicateKeysError
+		}
+		o.Interchange = o.Interchange || o.Profile == ProfileInterchange
+	}
+	return o
+}
+
+// leadingValue returns the JSON value at the start of data, with any space
+// before it, and checks that it is valid. What follows it is ignored.
+func leadingValue(data []byte, scan *scanner) ([]byte, error) {
+	scan.reset()
+	for i, c := range data {
+		scan.bytes++
+		switch scan.step(scan, c) {
+		case scanEnd:
+			return data[:i], nil
+		case scanEndObject, scanEndArray:
+			// scanEnd is delayed one byte; end the value here.
+			if stateEndValue(scan, ' ') == scanEnd {
+				return data[:i+1], nil
+			}
+		case scanError:
+			return nil, scan.err
+		}
+	}
+	if scan.eof() == scanError {
+		return nil, scan.err
+	}
+	return data, nil
+}
+
+// checkUTF8 reports the first string in the valid JSON text data with
+// invalid UTF-8 or an escape for an unpaired surrogate.
+func checkUTF8(data []byte) error {
+	surrogates, invalid := replacedText(data)
+	switch {
+	case len(surrogates) > 0 && (len(invalid) == 0 || surrogates[0] < invalid[0]):
+		i := surrogates[0]
+		return &SyntaxError{"unpaired surrogate escape " + string(data[i:i+6]) + " in string", int64(i + 1)}
+	case len(invalid) > 0:
+		return &SyntaxError{"invalid UTF-8 in string", int64(invalid[0] + 1)}
+	}
+	return nil
+}
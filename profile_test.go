@@ -0,0 +1,131 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalProfile(t *testing.T) {
+	type T struct {
+		Name  string
+		Count int
+	}
+	tests := []struct {
+		CaseName
+		in      string
+		opts    UnmarshalOptions
+		want    T
+		wantErr error
+	}{{
+		CaseName: Name("default/trailing data"),
+		in:       `{"Name": "a"} {}`,
+		wantErr:  &SyntaxError{"invalid character '{' after top-level value", 15},
+	}, {
+		CaseName: Name("permissive/trailing data"),
+		in:       `{"Name": "a"} {}`,
+		opts:     UnmarshalOptions{Profile: ProfilePermissive},
+		want:     T{Name: "a"},
+	}, {
+		CaseName: Name("permissive/trailing garbage"),
+		in:       ` 5x`,
+		opts:     UnmarshalOptions{Profile: ProfilePermissive},
+		wantErr:  &UnmarshalTypeError{Value: "number", Type: reflect.TypeFor[T](), Offset: 2},
+	}, {
+		CaseName: Name("permissive/invalid value"),
+		in:       `{"Name": } {}`,
+		opts:     UnmarshalOptions{Profile: ProfilePermissive},
+		wantErr:  &SyntaxError{"invalid character '}' looking for beginning of value", 10},
+	}, {
+		CaseName: Name("default/lenient"),
+		in:       `{"name": "a", "Count": null, "extra": 1, "Count": 2}`,
+		want:     T{Name: "a", Count: 2},
+	}, {
+		CaseName: Name("strict/case"),
+		in:       `{"name": "a"}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		wantErr:  ErrUnknownField,
+	}, {
+		CaseName: Name("strict/null"),
+		in:       `{"Count": null}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		wantErr:  &UnmarshalTypeError{Value: "null", Type: reflect.TypeFor[int](), Offset: 14, Struct: "T", Field: "Count"},
+	}, {
+		CaseName: Name("strict/duplicate"),
+		in:       `{"Count": 1, "Count": 2}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		wantErr:  ErrDuplicateKey,
+	}, {
+		CaseName: Name("strict/duplicate policy kept"),
+		in:       `{"Count": 1, "Count": 2}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict, DuplicateKeys: DuplicateKeysFirstWins},
+		want:     T{Count: 1},
+	}, {
+		CaseName: Name("strict/invalid UTF-8"),
+		in:       "{\"Name\": \"a\xff\"}",
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		wantErr:  &SyntaxError{"invalid UTF-8 in string", 12},
+	}, {
+		CaseName: Name("strict/unpaired surrogate"),
+		in:       `{"Name": "\udc00"}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		wantErr:  &SyntaxError{`unpaired surrogate escape \udc00 in string`, 11},
+	}, {
+		CaseName: Name("strict/repaired surrogate"),
+		in:       `{"Name": "\udc00"}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict, RepairSurrogates: true},
+		want:     T{Name: "�"},
+	}, {
+		CaseName: Name("strict/valid"),
+		in:       `{"Name": "a", "Count": 3}`,
+		opts:     UnmarshalOptions{Profile: ProfileStrict},
+		want:     T{Name: "a", Count: 3},
+	}, {
+		CaseName: Name("interchange/number"),
+		in:       `{"Count": 1e400}`,
+		opts:     UnmarshalOptions{Profile: ProfileInterchange},
+		wantErr:  &SyntaxError{"number 1e400 overflows IEEE 754 double precision", 11},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var got T
+			err := tt.opts.Unmarshal([]byte(tt.in), &got)
+			switch want := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("%s: Unmarshal error: %v", tt.Where, err)
+				}
+				if got != tt.want {
+					t.Errorf("%s: Unmarshal:\n\tgot:  %+v\n\twant: %+v", tt.Where, got, tt.want)
+				}
+			case *SyntaxError, *UnmarshalTypeError:
+				if !reflect.DeepEqual(err, want) {
+					t.Errorf("%s: Unmarshal error:\n\tgot:  %#v\n\twant: %#v", tt.Where, err, want)
+				}
+			default:
+				if !errors.Is(err, want) {
+					t.Errorf("%s: Unmarshal error: got %v, want %v", tt.Where, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLeadingValue(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`1`, `1`},
+		{` 12 3`, ` 12`},
+		{`{"a":[1]}[]`, `{"a":[1]}`},
+		{`"s" "t"`, `"s"`},
+		{`true false`, `true`},
+	}
+	for _, tt := range tests {
+		var scan scanner
+		got, err := leadingValue([]byte(tt.in), &scan)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("leadingValue(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
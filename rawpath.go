@@ -0,0 +1,372 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPathNotFound is returned, possibly wrapped, by [Get], [Set], and
+// [Delete] when the document has no value at the requested path.
+var ErrPathNotFound = errors.New("json: path not found")
+
+// Get returns the encoding of the value at path within the JSON document data.
+// Each element of path is either a string, selecting the member of an object
+// with that key, or an int, selecting the element of an array at that index.
+// An empty path selects the whole document.
+//
+// Get scans data only as far as needed to find the value, without decoding
+// anything along the way, so it is suited to extracting a few values from a
+// large document. As a consequence, syntax errors in the parts of data
+// after the value are not reported. If an object has duplicate keys,
+// the first member with the key is selected.
+//
+// The returned RawMessage refers to data; it is not copied.
+// If there is no value at path, the error wraps [ErrPathNotFound].
+func Get(data []byte, path ...any) (RawMessage, error) {
+	start, err := findPath(data, path)
+	if err != nil {
+		return nil, err
+	}
+	end, err := valueEnd(data, start)
+	if err != nil {
+		return nil, err
+	}
+	return data[start:end:end], nil
+}
+
+// Set returns a copy of the JSON document data with the value at path
+// replaced by the encoding of value, as returned by [Marshal].
+// Paths are interpreted as by [Get].
+//
+// If the last element of path names a member missing from its object,
+// the member is added at the end of the object, and if it is the index
+// just past the end of its array, the element is appended to the array.
+// Other missing values are not created, and the error wraps [ErrPathNotFound].
+//
+// Only the bytes of the replaced value change: the rest of the document,
+// including its formatting, key order, and the form of its numbers,
+// is copied verbatim.
+func Set(data []byte, value any, path ...any) ([]byte, error) {
+	b, err := Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		start, err := findPath(data, nil)
+		if err != nil {
+			return nil, err
+		}
+		end, err := valueEnd(data, start)
+		if err != nil {
+			return nil, err
+		}
+		return splice(data, start, end, b), nil
+	}
+
+	entries, k, end, err := findEntry(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if k < len(entries) {
+		return splice(data, entries[k].value, entries[k].end, b), nil
+	}
+
+	// Add a new member or element to the end of its object or array.
+	var insert []byte
+	at := end
+	if len(entries) > 0 {
+		at = entries[len(entries)-1].end
+		insert = append(insert, ',')
+	}
+	if key, ok := path[len(path)-1].(string); ok {
+		insert = appendString(insert, key, true)
+		insert = append(insert, ':')
+	}
+	insert = append(insert, b...)
+	return splice(data, at, at, insert), nil
+}
+
+// Delete returns a copy of the JSON document data with the value at path
+// removed from its object or array, together with its key and the comma
+// separating it from its neighbors. Paths are interpreted as by [Get],
+// and the rest of the document is copied verbatim.
+func Delete(data []byte, path ...any) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, errors.New("json: cannot delete the whole document")
+	}
+	entries, k, _, err := findEntry(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if k == len(entries) {
+		return nil, fmt.Errorf("%w: %q", ErrPathNotFound, pathPointer(path))
+	}
+	start, end := entries[k].start, entries[k].end
+	switch {
+	case k < len(entries)-1:
+		end = entries[k+1].start
+	case k > 0:
+		start = entries[k-1].end
+	}
+	return splice(data, start, end, nil), nil
+}
+
+// splice returns a copy of data with data[start:end] replaced by b.
+func splice(data []byte, start, end int, b []byte) []byte {
+	out := make([]byte, 0, len(data)-(end-start)+len(b))
+	out = append(out, data[:start]...)
+	out = append(out, b...)
+	return append(out, data[end:]...)
+}
+
+// A rawEntry is a member of an object or an element of an array.
+type rawEntry struct {
+	key   string
+	start int // offset of the key, or of the value for array elements
+	value int // offset of the value
+	end   int // offset just past the value
+}
+
+// findEntry finds the object or array holding the value at a non-empty path.
+// It returns the entries of that object or array, the index in entries of the
+// value, and the offset of the closing delimiter. The index is len(entries)
+// if the value is missing but could be added by [Set].
+func findEntry(data []byte, path []any) (entries []rawEntry, k, end int, err error) {
+	i, err := findPath(data, path[:len(path)-1])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if i >= len(data) {
+		_, err := valueEnd(data, i)
+		return nil, 0, 0, err
+	}
+	parent := pathPointer(path[:len(path)-1])
+	switch p := path[len(path)-1].(type) {
+	case string:
+		if data[i] != '{' {
+			return nil, 0, 0, fmt.Errorf("json: value at %q is not an object", parent)
+		}
+		if entries, end, err = containerEntries(data, i); err != nil {
+			return nil, 0, 0, err
+		}
+		for k = 0; k < len(entries) && entries[k].key != p; k++ {
+		}
+	case int:
+		if data[i] != '[' {
+			return nil, 0, 0, fmt.Errorf("json: value at %q is not an array", parent)
+		}
+		if entries, end, err = containerEntries(data, i); err != nil {
+			return nil, 0, 0, err
+		}
+		if k = p; p < 0 || p > len(entries) {
+			return nil, 0, 0, fmt.Errorf("%w: %q", ErrPathNotFound, pathPointer(path))
+		}
+	default:
+		return nil, 0, 0, fmt.Errorf("json: invalid path element of type %T", p)
+	}
+	return entries, k, end, nil
+}
+
+// containerEntries returns the entries of the object or array starting at
+// data[i], and the offset of its closing delimiter.
+func containerEntries(data []byte, i int) ([]rawEntry, int, error) {
+	isObject := data[i] == '{'
+	closing := byte(']')
+	if isObject {
+		closing = '}'
+	}
+	var entries []rawEntry
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == closing {
+		return nil, i, nil
+	}
+	for {
+		e := rawEntry{start: i, value: i}
+		if isObject {
+			end, err := valueEnd(data, i)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := unquote(data[i:end])
+			if !ok {
+				return nil, 0, &SyntaxError{"invalid object key", int64(i + 1)}
+			}
+			e.key = k
+			if i, err = expectByte(data, skipSpace(data, end), ':', "after object key"); err != nil {
+				return nil, 0, err
+			}
+			e.value = skipSpace(data, i)
+		}
+		var err error
+		if e.end, err = valueEnd(data, e.value); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+		i = skipSpace(data, e.end)
+		if i < len(data) && data[i] == closing {
+			return entries, i, nil
+		}
+		context := "after array element"
+		if isObject {
+			context = "after object key:value pair"
+		}
+		if i, err = expectByte(data, i, ',', context); err != nil {
+			return nil, 0, err
+		}
+		i = skipSpace(data, i)
+	}
+}
+
+// findPath returns the offset in data of the first byte of the value at path.
+func findPath(data []byte, path []any) (int, error) {
+	i := skipSpace(data, 0)
+	for n, p := range path {
+		if i >= len(data) {
+			break // reported by valueEnd
+		}
+		var err error
+		switch p := p.(type) {
+		case string:
+			if data[i] != '{' {
+				return 0, fmt.Errorf("json: value at %q is not an object", pathPointer(path[:n]))
+			}
+			i, err = findMember(data, i, p)
+		case int:
+			if data[i] != '[' {
+				return 0, fmt.Errorf("json: value at %q is not an array", pathPointer(path[:n]))
+			}
+			i, err = findElement(data, i, p)
+		default:
+			return 0, fmt.Errorf("json: invalid path element of type %T", p)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if i < 0 {
+			return 0, fmt.Errorf("%w: %q", ErrPathNotFound, pathPointer(path[:n+1]))
+		}
+	}
+	return i, nil
+}
+
+// findMember returns the offset of the value of the first member with the
+// given key in the object starting at data[i], or -1 if there is none.
+func findMember(data []byte, i int, key string) (int, error) {
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return -1, nil
+	}
+	for {
+		end, err := valueEnd(data, i)
+		if err != nil {
+			return 0, err
+		}
+		k, ok := unquote(data[i:end])
+		if !ok {
+			return 0, &SyntaxError{"invalid object key", int64(i + 1)}
+		}
+		i, err = expectByte(data, skipSpace(data, end), ':', "after object key")
+		if err != nil {
+			return 0, err
+		}
+		i = skipSpace(data, i)
+		if k == key {
+			return i, nil
+		}
+		if i, err = valueEnd(data, i); err != nil {
+			return 0, err
+		}
+		i = skipSpace(data, i)
+		if i < len(data) && data[i] == '}' {
+			return -1, nil
+		}
+		if i, err = expectByte(data, i, ',', "after object key:value pair"); err != nil {
+			return 0, err
+		}
+		i = skipSpace(data, i)
+	}
+}
+
+// findElement returns the offset of the element at index n in the array
+// starting at data[i], or -1 if there is none.
+func findElement(data []byte, i, n int) (int, error) {
+	i = skipSpace(data, i+1)
+	if n < 0 || i < len(data) && data[i] == ']' {
+		return -1, nil
+	}
+	for ; n > 0; n-- {
+		var err error
+		if i, err = valueEnd(data, i); err != nil {
+			return 0, err
+		}
+		i = skipSpace(data, i)
+		if i < len(data) && data[i] == ']' {
+			return -1, nil
+		}
+		if i, err = expectByte(data, i, ',', "after array element"); err != nil {
+			return 0, err
+		}
+		i = skipSpace(data, i)
+	}
+	return i, nil
+}
+
+// valueEnd returns the offset just past the end of the value
+// starting at data[i], checking its syntax.
+func valueEnd(data []byte, i int) (int, error) {
+	var s scanner
+	s.reset()
+	s.bytes = int64(i)
+	for j := i; j < len(data); j++ {
+		s.bytes++
+		switch s.step(&s, data[j]) {
+		case scanError:
+			return 0, s.err
+		case scanEnd:
+			return j, nil
+		case scanEndObject, scanEndArray:
+			if len(s.parseState) == 0 {
+				return j + 1, nil
+			}
+		}
+	}
+	if s.eof() == scanError {
+		return 0, s.err
+	}
+	return len(data), nil
+}
+
+// expectByte checks that data[i] is c and returns the offset after it.
+func expectByte(data []byte, i int, c byte, context string) (int, error) {
+	if i >= len(data) {
+		return 0, &SyntaxError{"unexpected end of JSON input", int64(len(data))}
+	}
+	if data[i] != c {
+		return 0, &SyntaxError{"invalid character " + quoteChar(data[i]) + " " + context, int64(i + 1)}
+	}
+	return i + 1, nil
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+// pathPointer returns the JSON Pointer (RFC 6901) for path.
+func pathPointer(path []any) string {
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteByte('/')
+		switch p := p.(type) {
+		case string:
+			b.WriteString(escapePointerToken(p))
+		case int:
+			b.WriteString(strconv.Itoa(p))
+		}
+	}
+	return b.String()
+}
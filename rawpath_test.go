@@ -0,0 +1,164 @@
+package json
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	const doc = ` {"a": {"b~/c": [10, 2.50, {"d": true}]}, "e": "x", "e": "y", "f": [], "g": {}} `
+	tests := []struct {
+		CaseName
+		path []any
+		want string
+	}{
+		{Name("whole document"), nil, `{"a": {"b~/c": [10, 2.50, {"d": true}]}, "e": "x", "e": "y", "f": [], "g": {}}`},
+		{Name("member"), []any{"a"}, `{"b~/c": [10, 2.50, {"d": true}]}`},
+		{Name("element"), []any{"a", "b~/c", 1}, `2.50`},
+		{Name("nested"), []any{"a", "b~/c", 2, "d"}, `true`},
+		{Name("first duplicate"), []any{"e"}, `"x"`},
+		{Name("empty array"), []any{"f"}, `[]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Get([]byte(doc), tt.path...)
+			if err != nil {
+				t.Fatalf("%s: Get error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Get:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetError(t *testing.T) {
+	tests := []struct {
+		CaseName
+		doc      string
+		path     []any
+		notFound bool
+		want     string
+	}{
+		{Name("missing member"), `{"a": {"b": 1}}`, []any{"a", "c"}, true, `json: path not found: "/a/c"`},
+		{Name("empty object"), `{"g": {}}`, []any{"g", "x"}, true, `json: path not found: "/g/x"`},
+		{Name("index out of range"), `[1, 2]`, []any{2}, true, `json: path not found: "/2"`},
+		{Name("negative index"), `[1]`, []any{-1}, true, `json: path not found: "/-1"`},
+		{Name("empty array"), `[]`, []any{0}, true, `json: path not found: "/0"`},
+		{Name("not an object"), `[1]`, []any{"a"}, false, `json: value at "" is not an object`},
+		{Name("not an array"), `{"a": 1}`, []any{"a", 0}, false, `json: value at "/a" is not an array`},
+		{Name("bad path element"), `{}`, []any{1.5}, false, `json: invalid path element of type float64`},
+		{Name("syntax error"), `{"a" 1}`, []any{"a"}, false, `invalid character '1' after object key`},
+		{Name("truncated"), `{"a": [1, `, []any{"a", 1}, false, `unexpected end of JSON input`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := Get([]byte(tt.doc), tt.path...)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("%s: Get error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.want)
+			}
+			if errors.Is(err, ErrPathNotFound) != tt.notFound {
+				t.Errorf("%s: errors.Is(err, ErrPathNotFound) = %v, want %v", tt.Where, !tt.notFound, tt.notFound)
+			}
+		})
+	}
+
+	// Syntax errors after the value are not reported.
+	if got, err := Get([]byte(`{"a": 1, "b": ]`), "a"); err != nil || string(got) != "1" {
+		t.Errorf("Get = %s, %v, want 1, nil", got, err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	const doc = "{\n  \"a\": 1.50,\n  \"b\": [1, 2]\n}\n"
+	tests := []struct {
+		CaseName
+		doc   string
+		path  []any
+		value any
+		want  string
+	}{
+		{Name("whole document"), ` [1] `, nil, 2, ` 2 `},
+		{Name("replace member"), doc, []any{"a"}, "x", "{\n  \"a\": \"x\",\n  \"b\": [1, 2]\n}\n"},
+		{Name("replace element"), doc, []any{"b", 0}, map[string]int{"c": 3}, "{\n  \"a\": 1.50,\n  \"b\": [{\"c\":3}, 2]\n}\n"},
+		{Name("add member"), doc, []any{"<c>"}, true, "{\n  \"a\": 1.50,\n  \"b\": [1, 2],\"\\u003cc\\u003e\":true\n}\n"},
+		{Name("append element"), doc, []any{"b", 2}, nil, "{\n  \"a\": 1.50,\n  \"b\": [1, 2,null]\n}\n"},
+		{Name("add to empty object"), `{ }`, []any{"a"}, 1, `{ "a":1}`},
+		{Name("append to empty array"), `[]`, []any{0}, 1, `[1]`},
+		{Name("first duplicate"), `{"a":1,"a":2}`, []any{"a"}, 3, `{"a":3,"a":2}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Set([]byte(tt.doc), tt.value, tt.path...)
+			if err != nil {
+				t.Fatalf("%s: Set error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Set:\n\tgot:  %q\n\twant: %q", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	errTests := []struct {
+		CaseName
+		doc  string
+		path []any
+		want string
+	}{
+		{Name("missing parent"), `{"a": {}}`, []any{"b", "c"}, `json: path not found: "/b"`},
+		{Name("index past end"), `[1]`, []any{2}, `json: path not found: "/2"`},
+		{Name("not an array"), `{"a": {}}`, []any{"a", 0}, `json: value at "/a" is not an array`},
+		{Name("syntax error"), `{"a": 1 "b": 2}`, []any{"b"}, `invalid character '"' after object key:value pair`},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := Set([]byte(tt.doc), 1, tt.path...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("%s: Set error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Set([]byte(`{}`), make(chan int), "a"); err == nil {
+		t.Error("Set(chan) error = nil, want UnsupportedTypeError")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		CaseName
+		doc  string
+		path []any
+		want string
+	}{
+		{Name("first member"), `{"a": 1, "b": 2, "c": 3}`, []any{"a"}, `{"b": 2, "c": 3}`},
+		{Name("middle member"), `{"a": 1, "b": 2, "c": 3}`, []any{"b"}, `{"a": 1, "c": 3}`},
+		{Name("last member"), `{"a": 1, "b": 2, "c": 3}`, []any{"c"}, `{"a": 1, "b": 2}`},
+		{Name("only member"), `{ "a": 1 }`, []any{"a"}, `{  }`},
+		{Name("element"), "[1,\n 2.0,\n 3]", []any{1}, "[1,\n 3]"},
+		{Name("nested"), `{"a": [{"b": 1, "c": 2}]}`, []any{"a", 0, "c"}, `{"a": [{"b": 1}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := Delete([]byte(tt.doc), tt.path...)
+			if err != nil {
+				t.Fatalf("%s: Delete error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Delete:\n\tgot:  %q\n\twant: %q", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	for _, path := range [][]any{{"x"}, {1}} {
+		if _, err := Delete([]byte(`{"a": [1]}`), append([]any{"a"}, path...)...); err == nil {
+			t.Errorf("Delete(%v) error = nil, want error", path)
+		}
+	}
+	if _, err := Delete([]byte(`{"a": 1}`), "b"); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Delete(missing) error = %v, want ErrPathNotFound", err)
+	}
+	if _, err := Delete([]byte(`{}`)); err == nil {
+		t.Error("Delete(whole document) error = nil, want error")
+	}
+}
@@ -0,0 +1,136 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// RawNumber is a JSON number literal, such as 1.50e2, held as the bytes
+// of the input it was decoded from. Unlike a [Number], which is decoded
+// from the same literals, it shares the representation of [RawMessage],
+// and unlike a RawMessage, decoding any other kind of value into it is an
+// [UnmarshalTypeError]. It is encoded as it is, so a number decoded into
+// a RawNumber is encoded again byte for byte, and is parsed only if and
+// when its methods are called. A nil RawNumber is encoded as null.
+type RawNumber []byte
+
+// String returns the literal text of the number.
+func (n RawNumber) String() string { return string(n) }
+
+// Float64 returns the number as a float64.
+func (n RawNumber) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// Int64 returns the number as an int64.
+func (n RawNumber) Int64() (int64, error) {
+	return strconv.ParseInt(string(n), 10, 64)
+}
+
+// MarshalJSON returns n, which must be a valid number literal.
+func (n RawNumber) MarshalJSON() ([]byte, error) {
+	if n == nil {
+		return []byte("null"), nil
+	}
+	if !isValidNumber(string(n)) {
+		return nil, fmt.Errorf("json: invalid number literal %q", n)
+	}
+	return n, nil
+}
+
+// UnmarshalJSON sets *n to a copy of data, which must be a number, or
+// leaves it unchanged if data is null.
+func (n *RawNumber) UnmarshalJSON(data []byte) error {
+	if n == nil {
+		return errors.New("json.RawNumber: UnmarshalJSON on nil pointer")
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	if !n.accepts(data) {
+		return n.typeError(valueKind(data), 0)
+	}
+	*n = append((*n)[0:0], data...)
+	return nil
+}
+
+// RawString is a JSON string literal, held as the bytes of the input it
+// was decoded from, including its quotes and escape sequences. Decoding
+// any other kind of value into it is an [UnmarshalTypeError]. It is
+// encoded as it is, without the escaping of HTML characters that
+// [Marshal] otherwise does, so a string decoded into a RawString is
+// encoded again byte for byte, and is unquoted only if and when
+// [RawString.Unquote] is called. A nil RawString is encoded as null.
+type RawString []byte
+
+// Unquote returns the text of the string, with its escape sequences
+// decoded as [Unmarshal] decodes them into a Go string.
+func (s RawString) Unquote() (string, error) {
+	var text string
+	if err := Unmarshal(s, &text); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// MarshalJSON returns s, which must be a valid string literal.
+func (s RawString) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	var scan scanner
+	if !s.accepts(s) || checkValid(s, &scan) != nil {
+		return nil, fmt.Errorf("json: invalid string literal %q", s)
+	}
+	return s, nil
+}
+
+// UnmarshalJSON sets *s to a copy of data, which must be a string, or
+// leaves it unchanged if data is null.
+func (s *RawString) UnmarshalJSON(data []byte) error {
+	if s == nil {
+		return errors.New("json.RawString: UnmarshalJSON on nil pointer")
+	}
+	if string(data) == "null" {
+		return nil
+	}
+	if !s.accepts(data) {
+		return s.typeError(valueKind(data), 0)
+	}
+	*s = append((*s)[0:0], data...)
+	return nil
+}
+
+var (
+	_ Marshaler   = RawNumber(nil)
+	_ Unmarshaler = (*RawNumber)(nil)
+	_ Marshaler   = RawString(nil)
+	_ Unmarshaler = (*RawString)(nil)
+)
+
+// A rawScalar is a *RawNumber or *RawString, which accepts only one kind
+// of JSON literal. The decoder checks the kind itself, to report a value
+// of another kind at its offset, and go on decoding.
+type rawScalar interface {
+	Unmarshaler
+	accepts(data []byte) bool
+	typeError(value string, off int) error
+}
+
+func (*RawNumber) accepts(data []byte) bool {
+	return len(data) > 0 && (data[0] == '-' || '0' <= data[0] && data[0] <= '9')
+}
+
+func (*RawString) accepts(data []byte) bool {
+	return len(data) > 0 && data[0] == '"'
+}
+
+func (*RawNumber) typeError(value string, off int) error {
+	return &UnmarshalTypeError{Value: value, Type: reflect.TypeFor[RawNumber](), Offset: int64(off)}
+}
+
+func (*RawString) typeError(value string, off int) error {
+	return &UnmarshalTypeError{Value: value, Type: reflect.TypeFor[RawString](), Offset: int64(off)}
+}
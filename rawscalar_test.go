@@ -0,0 +1,116 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type rawScalars struct {
+	N  RawNumber  `json:"n"`
+	S  RawString  `json:"s"`
+	PN *RawNumber `json:"pn"`
+	PS *RawString `json:"ps"`
+}
+
+func TestRawScalarRoundTrip(t *testing.T) {
+	const in = `{"n":1.50E+2,"s":"a<b\/c <d>","pn":-0.0e-0,"ps":"😀"}`
+	var v rawScalars
+	if err := Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if string(v.N) != `1.50E+2` || string(v.S) != `"a<b\/c <d>"` || string(*v.PN) != `-0.0e-0` || string(*v.PS) != `"😀"` {
+		t.Errorf("Unmarshal: got %s %s %s %s", v.N, v.S, *v.PN, *v.PS)
+	}
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if string(got) != in {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, in)
+	}
+	if size := EstimateSize(v); size != len(in) {
+		t.Errorf("EstimateSize = %d, want %d", size, len(in))
+	}
+
+	if f, err := v.N.Float64(); err != nil || f != 150 {
+		t.Errorf("RawNumber.Float64 = %v, %v, want 150, nil", f, err)
+	}
+	if _, err := v.N.Int64(); err == nil {
+		t.Errorf("RawNumber.Int64 of %s: got nil error, want error", v.N)
+	}
+	if s, err := v.S.Unquote(); err != nil || s != "a<b/c <d>" {
+		t.Errorf("RawString.Unquote = %q, %v, want %q, nil", s, err, "a<b/c <d>")
+	}
+
+	got, err = Marshal(rawScalars{})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if want := `{"n":null,"s":null,"pn":null,"ps":null}`; string(got) != want {
+		t.Errorf("Marshal of zero value:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestRawScalarTypeErrors(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in   string
+		want *UnmarshalTypeError
+	}{{
+		CaseName: Name("number into string"),
+		in:       `{"s":12,"n":3}`,
+		want:     &UnmarshalTypeError{Value: "number", Type: rawStringType, Offset: 5, Struct: "rawScalars", Field: "s"},
+	}, {
+		CaseName: Name("string into number"),
+		in:       `{"n":"12","s":"x"}`,
+		want:     &UnmarshalTypeError{Value: "string", Type: rawNumberType, Offset: 5, Struct: "rawScalars", Field: "n"},
+	}, {
+		CaseName: Name("bool into number"),
+		in:       `{"n":true}`,
+		want:     &UnmarshalTypeError{Value: "bool", Type: rawNumberType, Offset: 5, Struct: "rawScalars", Field: "n"},
+	}, {
+		CaseName: Name("array into pointer"),
+		in:       `{"pn":[1]}`,
+		want:     &UnmarshalTypeError{Value: "array", Type: rawNumberType, Offset: 7, Struct: "rawScalars", Field: "pn"},
+	}, {
+		CaseName: Name("object into pointer"),
+		in:       `{"ps":{"a":"b"}}`,
+		want:     &UnmarshalTypeError{Value: "object", Type: rawStringType, Offset: 7, Struct: "rawScalars", Field: "ps"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var v rawScalars
+			err := Unmarshal([]byte(tt.in), &v)
+			var got *UnmarshalTypeError
+			if !errors.As(err, &got) {
+				t.Fatalf("%s: Unmarshal error: %v, want UnmarshalTypeError", tt.Where, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Unmarshal error:\n\tgot:  %+v\n\twant: %+v", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	// Decoding goes on after a type error.
+	var v rawScalars
+	Unmarshal([]byte(`{"s":12,"n":3}`), &v)
+	if string(v.N) != "3" {
+		t.Errorf("Unmarshal after type error: N = %s, want 3", v.N)
+	}
+}
+
+func TestRawScalarMarshalInvalid(t *testing.T) {
+	for _, v := range []any{RawNumber(""), RawNumber("01"), RawNumber(`"1"`), RawString(`abc`), RawString(`"abc`), RawString(`"a" "b"`), RawString(`1`)} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%T(%s)): got nil error, want error", v, v)
+		}
+	}
+	var n RawNumber
+	if err := n.UnmarshalJSON([]byte(`"1"`)); err == nil {
+		t.Error("RawNumber.UnmarshalJSON of string: got nil error, want error")
+	}
+	if err := n.UnmarshalJSON([]byte(`null`)); err != nil || n != nil {
+		t.Errorf("RawNumber.UnmarshalJSON of null = %v, %s, want nil, nil", err, n)
+	}
+}
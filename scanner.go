@@ -19,10 +19,11 @@ import (
 )
 
 // Valid reports whether data is a valid JSON encoding.
+// It uses no memory proportional to the nesting of data, so it is safe to
+// call on untrusted input; see [ValidateOptions] to limit its length and
+// nesting further.
 func Valid(data []byte) bool {
-	scan := newScanner()
-	defer freeScanner(scan)
-	return checkValid(data, scan) == nil
+	return validate(data, maxNestingDepth) == nil
 }
 
 // checkValid verifies that data is valid JSON-encoded data.
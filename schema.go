@@ -0,0 +1,423 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// schemaDialect is the JSON Schema dialect emitted by SchemaOf.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// A Schema is a JSON Schema (draft 2020-12) describing the JSON encoding of a Go type.
+// It is also a valid OpenAPI 3.1 Schema Object.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Type                 SchemaTypes        `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+}
+
+// SchemaTypes is the value of a Schema's "type" keyword.
+// It encodes as a single string when it holds exactly one type
+// and as an array of strings otherwise.
+type SchemaTypes []string
+
+// MarshalJSON implements [Marshaler].
+func (t SchemaTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return Marshal(t[0])
+	}
+	return Marshal([]string(t))
+}
+
+// UnmarshalJSON implements [Unmarshaler].
+func (t *SchemaTypes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := Unmarshal(data, &s); err == nil {
+		*t = SchemaTypes{s}
+		return nil
+	}
+	return Unmarshal(data, (*[]string)(t))
+}
+
+// SchemaOf returns a JSON Schema describing the JSON encoding of v's type,
+// following the same field resolution rules as [Marshal].
+// Top-level pointers are dereferenced.
+//
+// The optional and nullable struct tags are reflected in the schema:
+// fields that are not optional or tagged with one of the omit options are
+// listed as required, and nullable fields (as well as plain pointers and interfaces)
+// additionally accept null, as do slice and map fields that are required,
+// since their nil values are encoded as null.
+// Recursive types are described using "$defs" and "$ref".
+//
+// SchemaOf returns an [UnsupportedTypeError] for types that cannot be encoded,
+// and the same error [Marshal] would return for invalid struct tags.
+func SchemaOf(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("json: SchemaOf(nil)")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	g := &schemaGen{refPrefix: "#/$defs/", defs: map[string]*Schema{}}
+	s, err := g.schema(t)
+	if err != nil {
+		return nil, err
+	}
+	if s.Ref != "" {
+		// Keep the root reference separate from the definitions.
+		s = &Schema{Ref: s.Ref}
+	}
+	s.Schema = schemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s, nil
+}
+
+// OpenAPIComponents collects the reusable schemas of an OpenAPI 3.1 document,
+// as found under "components" in the document.
+// The zero value is ready to use.
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+
+	// TypeName returns the component name to use for a named struct type.
+	// If TypeName is nil or returns "", the Go type name is used,
+	// with characters not allowed in component names replaced by '_'.
+	TypeName func(t reflect.Type) string `json:"-"`
+
+	names map[reflect.Type]string
+}
+
+// Ref adds the schema for v's type, and the schemas of all named struct types
+// reachable from it, to c.Schemas. Named struct types are referred to using
+// "$ref" rather than being inlined. Top-level pointers are dereferenced.
+//
+// Ref returns the schema to use for v's type in the rest of the document;
+// for a named struct type this is a reference to its component.
+// It is an error for two distinct types to map to the same component name.
+func (c *OpenAPIComponents) Ref(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("json: OpenAPIComponents.Ref(nil)")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if c.Schemas == nil {
+		c.Schemas = map[string]*Schema{}
+	}
+	if c.names == nil {
+		c.names = map[reflect.Type]string{}
+	}
+	g := &schemaGen{
+		refPrefix: "#/components/schemas/",
+		defs:      c.Schemas,
+		names:     c.names,
+		typeName:  c.TypeName,
+		refAll:    true,
+	}
+	return g.schema(t)
+}
+
+// schemaGen builds schemas for Go types.
+type schemaGen struct {
+	refPrefix string
+	defs      map[string]*Schema
+	typeName  func(reflect.Type) string
+
+	// refAll causes every named struct type to be referenced by name.
+	// Otherwise only recursive types are.
+	refAll bool
+
+	names     map[reflect.Type]string // definition name of each referenced type
+	building  map[reflect.Type]bool   // types whose schema is being built
+	recursive map[reflect.Type]bool   // types that refer back to themselves
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	timePtrType    = reflect.TypeFor[*time.Time]()
+	rawMessageType = reflect.TypeFor[RawMessage]()
+	rawNumberType  = reflect.TypeFor[RawNumber]()
+	rawStringType  = reflect.TypeFor[RawString]()
+)
+
+func (g *schemaGen) schema(t reflect.Type) (*Schema, error) {
+	switch {
+	case t == timeType:
+		return &Schema{Type: SchemaTypes{"string"}, Format: "date-time"}, nil
+	case t == numberType:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case t == rawMessageType:
+		return &Schema{}, nil
+	case t == rawNumberType:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case t == rawStringType:
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	case t == readerType || t == streamStringType:
+		return &Schema{Type: SchemaTypes{"string", "null"}}, nil
+	case lookupEnum(t) != nil:
+		return lookupEnum(t).schema(), nil
+	case t.Kind() == reflect.Pointer && lookupEnum(t.Elem()) != nil:
+		s, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return schemaWithNull(s), nil
+	case t.Implements(marshalerType), reflect.PointerTo(t).Implements(marshalerType):
+		// The encoding is not known statically.
+		return &Schema{}, nil
+	case implementsText(t), implementsText(reflect.PointerTo(t)):
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: SchemaTypes{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: SchemaTypes{"integer"}}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: SchemaTypes{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: SchemaTypes{"string"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		s, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return schemaWithNull(s), nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			p := reflect.PointerTo(t.Elem())
+			if !p.Implements(marshalerType) && !implementsText(p) {
+				return &Schema{Type: SchemaTypes{"string"}, ContentEncoding: "base64"}, nil
+			}
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaTypes{"array"}, Items: items}, nil
+	case reflect.Array:
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		n := t.Len()
+		return &Schema{Type: SchemaTypes{"array"}, Items: items, MinItems: &n, MaxItems: &n}, nil
+	case reflect.Map:
+		switch t.Key().Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !implementsText(t.Key()) {
+				return nil, &UnsupportedTypeError{t}
+			}
+		}
+		elem, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaTypes{"object"}, AdditionalProperties: elem}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	case reflect.Func, reflect.Chan:
+		var elem reflect.Type
+		switch {
+		case t.Kind() == reflect.Func && isSeq(t):
+			elem = t.In(0).In(0)
+		case t.Kind() == reflect.Chan && t.ChanDir() == reflect.RecvDir:
+			elem = t.Elem()
+		default:
+			return nil, &UnsupportedTypeError{t}
+		}
+		items, err := g.schema(elem)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaTypes{"array"}, Items: items}, nil
+	}
+	return nil, &UnsupportedTypeError{t}
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) (*Schema, error) {
+	named := t.Name() != ""
+	if named {
+		if name, ok := g.names[t]; ok && (g.refAll || g.recursive[t]) {
+			return &Schema{Ref: g.refPrefix + name}, nil
+		}
+		if g.building[t] {
+			// t refers back to itself; describe it by reference.
+			if g.recursive == nil {
+				g.recursive = map[reflect.Type]bool{}
+			}
+			g.recursive[t] = true
+			name, err := g.defName(t)
+			if err != nil {
+				return nil, err
+			}
+			return &Schema{Ref: g.refPrefix + name}, nil
+		}
+		if g.refAll {
+			if _, err := g.defName(t); err != nil {
+				return nil, err
+			}
+		}
+		if g.building == nil {
+			g.building = map[reflect.Type]bool{}
+		}
+		g.building[t] = true
+		defer delete(g.building, t)
+	}
+
+	fields := cachedTypeFields(t)
+	if fields.error != nil {
+		return nil, fields.error
+	}
+	s := &Schema{Type: SchemaTypes{"object"}, Properties: map[string]*Schema{}}
+	for i := range fields.list {
+		f := &fields.list[i]
+		ft := typeByIndex(t, f.index)
+		if f.optional {
+			ft = ft.Elem()
+		}
+		if f.nullable {
+			ft = ft.Elem()
+		}
+		var fs *Schema
+		if f.quoted {
+			fs = &Schema{Type: SchemaTypes{"string"}}
+		} else if f.fieldFormat != nil {
+			s := f.fieldFormat.schema
+			fs = &s
+			if ft.Kind() == reflect.Pointer {
+				fs = schemaWithNull(fs)
+			}
+		} else {
+			var err error
+			if fs, err = g.schema(ft); err != nil {
+				return nil, err
+			}
+		}
+		if f.enum != nil && !f.quoted {
+			for _, e := range f.enum {
+				fs.Enum = append(fs.Enum, e)
+			}
+			if slices.Contains(fs.Type, "null") {
+				fs.Enum = append(fs.Enum, nil)
+			}
+		}
+		omitted := f.optional || f.omitEmpty || f.omitDeepEmpty || f.omitNil
+		if f.nullable || !omitted && !f.quoted && (ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map) {
+			// Nil slices and maps are encoded as null unless omitted.
+			fs = schemaWithNull(fs)
+		}
+		s.Properties[f.name] = fs
+		if !omitted && !embedsPointer(t, f.index) {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+
+	if named && (g.refAll || g.recursive[t]) {
+		name, err := g.defName(t)
+		if err != nil {
+			return nil, err
+		}
+		g.defs[name] = s
+		return &Schema{Ref: g.refPrefix + name}, nil
+	}
+	return s, nil
+}
+
+// defName returns the definition name of t, assigning one if needed.
+func (g *schemaGen) defName(t reflect.Type) (string, error) {
+	if name, ok := g.names[t]; ok {
+		return name, nil
+	}
+	var name string
+	if g.typeName != nil {
+		name = g.typeName(t)
+	}
+	if name == "" {
+		name = sanitizeComponentName(t.Name())
+	}
+	if g.names == nil {
+		g.names = map[reflect.Type]string{}
+	}
+	for other, otherName := range g.names {
+		if otherName == name {
+			return "", fmt.Errorf("json: schema name %q used by both %v and %v", name, other, t)
+		}
+	}
+	g.names[t] = name
+	return name, nil
+}
+
+// sanitizeComponentName replaces the characters of name that
+// OpenAPI does not allow in component names.
+func sanitizeComponentName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// schemaWithNull returns a schema that accepts null in addition to what s accepts.
+func schemaWithNull(s *Schema) *Schema {
+	switch {
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: SchemaTypes{"null"}}}}
+	case len(s.Type) == 0:
+		return s // already accepts anything
+	}
+	for _, typ := range s.Type {
+		if typ == "null" {
+			return s
+		}
+	}
+	s2 := *s
+	s2.Type = append(SchemaTypes{}, s.Type...)
+	s2.Type = append(s2.Type, "null")
+	if len(s.Enum) > 0 {
+		s2.Enum = append(append([]any{}, s.Enum...), nil)
+	}
+	return &s2
+}
+
+// embedsPointer reports whether the field at index is reached through an
+// embedded pointer, in which case it may be missing from the encoding.
+func embedsPointer(t reflect.Type, index []int) bool {
+	for _, i := range index[:len(index)-1] {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		t = t.Field(i).Type
+		if t.Kind() == reflect.Pointer {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city"`
+}
+
+type schemaUser struct {
+	Name     string            `json:"name"`
+	Age      *int              `json:"age,optional"`
+	Nickname *string           `json:"nickname,nullable"`
+	Email    **string          `json:"email,optional,nullable"`
+	Tags     []string          `json:"tags,omitempty"`
+	Data     []byte            `json:"data"`
+	Created  time.Time         `json:"created"`
+	Count    int64             `json:"count,string"`
+	Home     schemaAddress     `json:"home"`
+	Work     *schemaAddress    `json:"work,nullable"`
+	Extra    map[string]any    `json:"extra"`
+	Pair     [2]float64        `json:"pair"`
+	Raw      RawMessage        `json:"raw"`
+	Labels   map[string]string `json:"-"`
+}
+
+type schemaNode struct {
+	Value    int           `json:"value"`
+	Children []*schemaNode `json:"children"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in   any
+		want string
+	}{{
+		CaseName: Name("basic"),
+		in:       new(int),
+		want:     `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer"}`,
+	}, {
+		CaseName: Name("struct"),
+		in:       schemaUser{},
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"age":{"type":"integer"},` +
+			`"count":{"type":"string"},` +
+			`"created":{"type":"string","format":"date-time"},` +
+			`"data":{"type":["string","null"],"contentEncoding":"base64"},` +
+			`"email":{"type":["string","null"]},` +
+			`"extra":{"type":["object","null"],"additionalProperties":{}},` +
+			`"home":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]},` +
+			`"name":{"type":"string"},` +
+			`"nickname":{"type":["string","null"]},` +
+			`"pair":{"type":"array","items":{"type":"number"},"minItems":2,"maxItems":2},` +
+			`"raw":{},` +
+			`"tags":{"type":"array","items":{"type":"string"}},` +
+			`"work":{"type":["object","null"],"properties":{"city":{"type":"string"}},"required":["city"]}},` +
+			`"required":["name","nickname","data","created","count","home","work","extra","pair","raw"]}`,
+	}, {
+		CaseName: Name("enum"),
+		in: struct {
+			S string   `json:"s,enum=a|b"`
+			P *string  `json:"p,nullable,enum=a|b"`
+			Q **string `json:"q,enum=a"`
+		}{},
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"p":{"type":["string","null"],"enum":["a","b",null]},` +
+			`"q":{"type":["string","null"],"enum":["a",null]},` +
+			`"s":{"type":"string","enum":["a","b"]}},"required":["s","p","q"]}`,
+	}, {
+		CaseName: Name("recursive"),
+		in:       schemaNode{},
+		want: `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/schemaNode","$defs":{"schemaNode":` +
+			`{"type":"object","properties":{"children":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#/$defs/schemaNode"},{"type":"null"}]}},` +
+			`"value":{"type":"integer"}},"required":["value","children"]}}}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			s, err := SchemaOf(tt.in)
+			if err != nil {
+				t.Fatalf("%s: SchemaOf error: %v", tt.Where, err)
+			}
+			got, err := Marshal(s)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: SchemaOf:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaOfAcceptsZeroValue(t *testing.T) {
+	for _, v := range []any{
+		schemaUser{},
+		schemaNode{},
+		struct {
+			S []int          `json:"s"`
+			M map[string]int `json:"m"`
+			O []int          `json:"o,omitempty"`
+			B []byte         `json:"b"`
+		}{},
+	} {
+		s, err := SchemaOf(v)
+		if err != nil {
+			t.Fatalf("SchemaOf(%T) error: %v", v, err)
+		}
+		b, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%T) error: %v", v, err)
+		}
+		var doc any
+		if err := Unmarshal(b, &doc); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if err := schemaCheck(s, s.Defs, doc, ""); err != nil {
+			t.Errorf("SchemaOf(%T) rejects %s: %v", v, b, err)
+		}
+	}
+}
+
+// schemaCheck reports why v does not match s, for the parts of JSON
+// Schema that SchemaOf produces.
+func schemaCheck(s *Schema, defs map[string]*Schema, v any, path string) error {
+	if s.Ref != "" {
+		return schemaCheck(defs[strings.TrimPrefix(s.Ref, "#/$defs/")], defs, v, path)
+	}
+	if len(s.AnyOf) > 0 {
+		for _, sub := range s.AnyOf {
+			if schemaCheck(sub, defs, v, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %v matches none of anyOf", path, v)
+	}
+	if len(s.Type) > 0 {
+		var typ string
+		switch v := v.(type) {
+		case nil:
+			typ = "null"
+		case bool:
+			typ = "boolean"
+		case float64:
+			typ = "number"
+			if v == float64(int64(v)) && slices.Contains(s.Type, "integer") {
+				typ = "integer"
+			}
+		case string:
+			typ = "string"
+		case []any:
+			typ = "array"
+		case map[string]any:
+			typ = "object"
+		}
+		if !slices.Contains(s.Type, typ) {
+			return fmt.Errorf("%s: %s value, want %v", path, typ, s.Type)
+		}
+	}
+	switch v := v.(type) {
+	case []any:
+		for i, e := range v {
+			if s.Items != nil {
+				if err := schemaCheck(s.Items, defs, e, fmt.Sprintf("%s/%d", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required %q", path, name)
+			}
+		}
+		for name, e := range v {
+			sub := s.Properties[name]
+			if sub == nil {
+				sub = s.AdditionalProperties
+			}
+			if sub != nil {
+				if err := schemaCheck(sub, defs, e, path+"/"+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func TestSchemaOfErrors(t *testing.T) {
+	if _, err := SchemaOf(struct{ C chan int }{}); err == nil {
+		t.Error("SchemaOf(chan field) error = nil, want UnsupportedTypeError")
+	}
+	_, err := SchemaOf(OptionalsNullablesBadNotEnoughIndirection1{})
+	if err == nil || !strings.Contains(err.Error(), "requires 1+ levels of indirection") {
+		t.Errorf("SchemaOf(bad tags) error = %v, want indirection error", err)
+	}
+}
+
+func TestOpenAPIComponents(t *testing.T) {
+	type Wrapper struct {
+		User  schemaUser    `json:"user"`
+		Other *schemaUser   `json:"other,optional"`
+		Nodes []schemaNode  `json:"nodes"`
+		Addr  schemaAddress `json:"addr"`
+	}
+	var c OpenAPIComponents
+	c.TypeName = func(t reflect.Type) string {
+		if t == reflect.TypeFor[schemaAddress]() {
+			return "Address"
+		}
+		return ""
+	}
+	ref, err := c.Ref(&Wrapper{})
+	if err != nil {
+		t.Fatalf("Ref error: %v", err)
+	}
+	if ref.Ref != "#/components/schemas/Wrapper" {
+		t.Errorf("Ref = %q, want #/components/schemas/Wrapper", ref.Ref)
+	}
+	for _, name := range []string{"Wrapper", "schemaUser", "schemaNode", "Address"} {
+		if c.Schemas[name] == nil {
+			t.Errorf("missing component %q", name)
+		}
+	}
+	got, err := Marshal(c.Schemas["Wrapper"].Properties)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"addr":{"$ref":"#/components/schemas/Address"},` +
+		`"nodes":{"type":["array","null"],"items":{"$ref":"#/components/schemas/schemaNode"}},` +
+		`"other":{"$ref":"#/components/schemas/schemaUser"},` +
+		`"user":{"$ref":"#/components/schemas/schemaUser"}}`
+	if string(got) != want {
+		t.Errorf("Wrapper properties:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	// A second type reuses the existing components.
+	if _, err := c.Ref(schemaAddress{}); err != nil {
+		t.Fatalf("Ref error: %v", err)
+	}
+	if len(c.Schemas) != 4 {
+		t.Errorf("len(Schemas) = %d, want 4", len(c.Schemas))
+	}
+
+	// Distinct types with the same name are rejected.
+	c.TypeName = func(reflect.Type) string { return "Same" }
+	type A struct{ X int }
+	type B struct{ A A }
+	if _, err := c.Ref(B{}); err == nil {
+		t.Error("Ref with colliding names error = nil, want error")
+	}
+}
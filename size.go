@@ -0,0 +1,388 @@
+package json
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// EstimateSize returns an estimate of the length in bytes of the encoding
+// of v by [Marshal], without encoding it. It may be used to allocate an
+// output buffer or to check that a response fits within a budget before
+// it is encoded.
+//
+// The estimate is exact for booleans, integers, nil values, byte slices,
+// [RawNumber] and [RawString] values, and strings that need no escaping.
+// Floating-point numbers are counted in their shortest form and strings by
+// their length in bytes, so escaped characters make the estimate too
+// small. Values encoded by [Marshaler], [encoding.TextMarshaler], a format
+// or codec tag option, or a function are encoded without being called, so
+// they are counted as a few bytes each. Values that Marshal cannot encode
+// are not counted, and neither is anything after the first cycle found in
+// a cyclic value.
+//
+// Like Marshal, EstimateSize walks the whole value, using the per-type
+// programs that Marshal compiles, so it costs a fraction of encoding v.
+func EstimateSize(v any) int {
+	return estimateValueSize(reflect.ValueOf(v))
+}
+
+// unknownSize is the size counted for a value whose encoding is not known
+// without calling the method or function that computes it.
+const unknownSize = 16
+
+// estimateValueSize is EstimateSize for a reflect.Value.
+func estimateValueSize(v reflect.Value) int {
+	if !v.IsValid() {
+		return len("null")
+	}
+	return typeSizer(v.Type())(v, new(sizeState))
+}
+
+// A sizerFunc returns the estimated size of the encoding of v, within the
+// value being sized by s. Each type has one, built alongside its
+// encoderFunc.
+type sizerFunc func(v reflect.Value, s *sizeState) int
+
+// A sizeState holds what is known of the value being sized: as in
+// encodeState, the pointers, maps, and slices being sized, past the first
+// startDetectingCyclesAfter levels, and whether a cycle has been found.
+type sizeState struct {
+	ptrLevel uint
+	ptrSeen  map[any]struct{}
+	cycle    bool
+}
+
+// enter records that the pointer, map, or slice identified by key is
+// being sized, and reports whether to size it. Marshal cannot encode a
+// cycle, so once one is found nothing more is counted.
+func (s *sizeState) enter(key any) bool {
+	if s.cycle {
+		return false
+	}
+	if s.ptrLevel++; s.ptrLevel > startDetectingCyclesAfter {
+		if _, ok := s.ptrSeen[key]; ok {
+			s.cycle = true
+			s.ptrLevel--
+			return false
+		}
+		if s.ptrSeen == nil {
+			s.ptrSeen = make(map[any]struct{})
+		}
+		s.ptrSeen[key] = struct{}{}
+	}
+	return true
+}
+
+// leave undoes enter once the value identified by key has been sized.
+func (s *sizeState) leave(key any) {
+	if s.ptrLevel > startDetectingCyclesAfter {
+		delete(s.ptrSeen, key)
+	}
+	s.ptrLevel--
+}
+
+var sizerCache typeCache // map[reflect.Type]sizerFunc
+
+// typeSizer is typeEncoder for sizerFuncs.
+func typeSizer(t reflect.Type) sizerFunc {
+	if fi, ok := sizerCache.Load(t); ok {
+		return fi.(sizerFunc)
+	}
+
+	// As in typeEncoder, store an indirect func for recursive types
+	// before building the real one.
+	var (
+		wg sync.WaitGroup
+		f  sizerFunc
+	)
+	wg.Add(1)
+	fi, loaded := sizerCache.LoadOrStorePending(t, sizerFunc(func(v reflect.Value, s *sizeState) int {
+		wg.Wait()
+		return f(v, s)
+	}))
+	if loaded {
+		return fi.(sizerFunc)
+	}
+
+	f = newTypeSizer(t, true)
+	wg.Done()
+	sizerCache.Store(t, f)
+	return f
+}
+
+// newTypeSizer constructs a sizerFunc for a type, choosing among the same
+// cases as newTypeEncoder.
+func newTypeSizer(t reflect.Type, allowAddr bool) sizerFunc {
+	if t == readerType || t == streamStringType {
+		return unknownSizer
+	}
+	if ec := lookupEnum(t); ec != nil {
+		return func(v reflect.Value, _ *sizeState) int {
+			if _, ok := ec.byValue[integerBits(v)]; ok {
+				return ec.keySize(v) + 2
+			}
+			return ec.keySize(v)
+		}
+	}
+	if t.Kind() == reflect.Pointer && lookupEnum(t.Elem()) != nil {
+		return newPtrSizer(t)
+	}
+	if t == rawNumberType || t == rawStringType {
+		return rawScalarSizer
+	}
+	if t.Kind() == reflect.Pointer && (t.Elem() == rawNumberType || t.Elem() == rawStringType) {
+		return newPtrSizer(t)
+	}
+	if t.Kind() != reflect.Pointer && allowAddr && (reflect.PointerTo(t).Implements(marshalerType) || implementsText(reflect.PointerTo(t))) {
+		// The methods of *T are only used for addressable values.
+		elem := newTypeSizer(t, false)
+		return func(v reflect.Value, s *sizeState) int {
+			if v.CanAddr() {
+				return unknownSize
+			}
+			return elem(v, s)
+		}
+	}
+	if t.Implements(marshalerType) || implementsText(t) {
+		if t.Kind() == reflect.Pointer {
+			return func(v reflect.Value, s *sizeState) int {
+				if v.IsNil() {
+					return len("null")
+				}
+				return unknownSize
+			}
+		}
+		return unknownSizer
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return boolSizer
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intSizer
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return uintSizer
+	case reflect.Float32:
+		return floatSizer(32)
+	case reflect.Float64:
+		return floatSizer(64)
+	case reflect.String:
+		if t == numberType {
+			return numberSizer
+		}
+		return stringSizer
+	case reflect.Interface:
+		return interfaceSizer
+	case reflect.Struct:
+		if null, maybe := wrapperKind(t); null || maybe {
+			return newWrapperSizer(t)
+		}
+		return newStructSizer(t)
+	case reflect.Map:
+		return newMapSizer(t)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !implementsMarshaler(t.Elem()) {
+			return byteSliceSizer
+		}
+		return newArraySizer(t, true)
+	case reflect.Array:
+		return newArraySizer(t, false)
+	case reflect.Pointer:
+		return newPtrSizer(t)
+	case reflect.Func, reflect.Chan:
+		if t.Kind() == reflect.Func && isSeq(t) || t.Kind() == reflect.Chan && t.ChanDir() == reflect.RecvDir {
+			return unknownSizer
+		}
+	}
+	return func(reflect.Value, *sizeState) int { return 0 }
+}
+
+func unknownSizer(reflect.Value, *sizeState) int { return unknownSize }
+
+func boolSizer(v reflect.Value, _ *sizeState) int {
+	if v.Bool() {
+		return len("true")
+	}
+	return len("false")
+}
+
+func intSizer(v reflect.Value, _ *sizeState) int {
+	var buf [20]byte
+	return len(strconv.AppendInt(buf[:0], v.Int(), 10))
+}
+
+func uintSizer(v reflect.Value, _ *sizeState) int {
+	var buf [20]byte
+	return len(strconv.AppendUint(buf[:0], v.Uint(), 10))
+}
+
+func floatSizer(bits int) sizerFunc {
+	return func(v reflect.Value, _ *sizeState) int {
+		var buf [32]byte
+		return len(strconv.AppendFloat(buf[:0], v.Float(), 'g', -1, bits))
+	}
+}
+
+func numberSizer(v reflect.Value, _ *sizeState) int {
+	return max(v.Len(), 1) // the empty Number encodes as 0
+}
+
+// rawScalarSizer sizes a RawNumber or RawString, which is encoded as it is.
+func rawScalarSizer(v reflect.Value, _ *sizeState) int {
+	if v.IsNil() {
+		return len("null")
+	}
+	return v.Len()
+}
+
+func stringSizer(v reflect.Value, _ *sizeState) int {
+	return v.Len() + 2
+}
+
+func byteSliceSizer(v reflect.Value, _ *sizeState) int {
+	if v.IsNil() {
+		return len("null")
+	}
+	return base64.StdEncoding.EncodedLen(v.Len()) + 2
+}
+
+func interfaceSizer(v reflect.Value, s *sizeState) int {
+	if v.IsNil() {
+		return len("null")
+	}
+	return typeSizer(v.Elem().Type())(v.Elem(), s)
+}
+
+func newWrapperSizer(t reflect.Type) sizerFunc {
+	elem := typeSizer(t.Field(0).Type)
+	return func(v reflect.Value, s *sizeState) int {
+		if !v.Field(1).Bool() {
+			return len("null")
+		}
+		return elem(v.Field(0), s)
+	}
+}
+
+// newStructSizer counts the fields that structEncoder encodes, without
+// applying the options of MarshalOptions.
+func newStructSizer(t reflect.Type) sizerFunc {
+	fields := cachedTypeFields(t).list
+	sizers := make([]sizerFunc, len(fields))
+	for i, f := range fields {
+		if f.fieldFormat != nil {
+			sizers[i] = unknownSizer
+		} else {
+			sizers[i] = typeSizer(typeByIndex(t, f.index))
+		}
+	}
+	return func(v reflect.Value, s *sizeState) int {
+		n := 0
+	FieldLoop:
+		for i := range fields {
+			f := &fields[i]
+			fv := v
+			for _, i := range f.index {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue FieldLoop
+					}
+					fv = fv.Elem()
+				}
+				fv = fv.Field(i)
+			}
+			switch {
+			case f.maybe && !fv.Field(1).Bool(),
+				f.omitEmpty && isEmptyValue(fv),
+				f.omitDeepEmpty && isDeepEmptyValue(fv),
+				f.omitNil && isNilValue(fv),
+				f.optional && fv.IsNil():
+				continue
+			}
+			n += len(f.nameNonEsc) + 1 + sizers[i](fv, s)
+			if f.quoted {
+				n += 2
+			}
+		}
+		return max(n+1, 2) // the commas and braces
+	}
+}
+
+func newMapSizer(t reflect.Type) sizerFunc {
+	// Keys are resolved in the order of resolveKeyName.
+	keySize := func(k reflect.Value) int { return unknownSize }
+	switch kind := t.Key().Kind(); {
+	case kind == reflect.String:
+		keySize = func(k reflect.Value) int { return k.Len() }
+	case lookupEnum(t.Key()) != nil:
+		keySize = lookupEnum(t.Key()).keySize
+	case implementsText(t.Key()):
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		keySize = func(k reflect.Value) int { return intSizer(k, nil) }
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		keySize = func(k reflect.Value) int { return uintSizer(k, nil) }
+	}
+	elem := typeSizer(t.Elem())
+	_, maybeElem := wrapperKind(t.Elem())
+	return func(v reflect.Value, s *sizeState) int {
+		if v.IsNil() {
+			return len("null")
+		}
+		ptr := v.UnsafePointer()
+		if !s.enter(ptr) {
+			return 0
+		}
+		defer s.leave(ptr)
+		n := 0
+		for iter := v.MapRange(); iter.Next(); {
+			if maybeElem && isAbsent(iter.Value()) {
+				continue
+			}
+			// The quoted key, colon, and comma.
+			n += keySize(iter.Key()) + 4 + elem(iter.Value(), s)
+		}
+		return max(n+1, 2)
+	}
+}
+
+func newArraySizer(t reflect.Type, slice bool) sizerFunc {
+	elem := typeSizer(t.Elem())
+	return func(v reflect.Value, s *sizeState) int {
+		if slice && v.IsNil() {
+			return len("null")
+		}
+		if slice {
+			// As in sliceEncoder, a slice is identified by its first
+			// element and its length.
+			ptr := struct {
+				ptr any
+				len int
+			}{v.UnsafePointer(), v.Len()}
+			if !s.enter(ptr) {
+				return 0
+			}
+			defer s.leave(ptr)
+		}
+		n := 0
+		for i := range v.Len() {
+			n += elem(v.Index(i), s) + 1
+		}
+		return max(n+1, 2)
+	}
+}
+
+func newPtrSizer(t reflect.Type) sizerFunc {
+	elem := typeSizer(t.Elem())
+	return func(v reflect.Value, s *sizeState) int {
+		if v.IsNil() {
+			return len("null")
+		}
+		ptr := v.Interface()
+		if !s.enter(ptr) {
+			return 0
+		}
+		defer s.leave(ptr)
+		return elem(v.Elem(), s)
+	}
+}
@@ -0,0 +1,135 @@
+package json
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+type sizeInner struct {
+	B bool   `json:"b"`
+	S string `json:"s,omitempty"`
+}
+
+type sizeOuter struct {
+	ID      int64              `json:"id"`
+	U       uint8              `json:"u,string"`
+	Name    string             `json:"name"`
+	Tags    []string           `json:"tags"`
+	Data    []byte             `json:"data"`
+	Inner   *sizeInner         `json:"inner"`
+	Counts  map[string]int     `json:"counts"`
+	ByID    map[int]bool       `json:"by_id,omitempty"`
+	Any     any                `json:"any"`
+	Num     Number             `json:"num"`
+	Arr     [2]int             `json:"arr"`
+	Opt     *int               `json:"opt,optional"`
+	Skip    string             `json:"-"`
+	Empty   []int              `json:"empty,omitempty"`
+	Nested  []map[string][]int `json:"nested"`
+	Maybe   Null[int]          `json:"maybe"`
+	private int
+	*sizeInner
+}
+
+func TestEstimateSizeExact(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in any
+	}{
+		{Name("nil"), nil},
+		{Name("bool"), false},
+		{Name("int"), math.MinInt64},
+		{Name("uint"), uint64(math.MaxUint64)},
+		{Name("float"), 1.25},
+		{Name("string"), "hello"},
+		{Name("bytes"), []byte("hello, world")},
+		{Name("nil slice"), []int(nil)},
+		{Name("empty slice"), []int{}},
+		{Name("empty map"), map[string]int{}},
+		{Name("empty struct"), struct{}{}},
+		{Name("zero struct"), sizeOuter{}},
+		{Name("absent map values"), map[string]Maybe[int]{"a": {}, "b": {V: 1, Present: true}}},
+		{Name("struct"), &sizeOuter{
+			ID:        -42,
+			U:         200,
+			Name:      "name",
+			Tags:      []string{"a", "bc", ""},
+			Data:      []byte{1, 2, 3, 4},
+			Inner:     &sizeInner{true, "s"},
+			Counts:    map[string]int{"x": 1, "yy": 22},
+			ByID:      map[int]bool{-1: true, 10: false},
+			Any:       []any{nil, "x", 3.5, map[string]any{"k": uint(7)}},
+			Num:       "1e100",
+			Arr:       [2]int{1, 2},
+			Opt:       new(int),
+			Skip:      "skipped",
+			Nested:    []map[string][]int{nil, {"a": {1, 2}}},
+			Maybe:     Null[int]{5, true},
+			sizeInner: &sizeInner{S: "embedded"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if got := EstimateSize(tt.in); got != len(b) {
+				t.Errorf("%s: EstimateSize = %d, want %d for %s", tt.Where, got, len(b), b)
+			}
+		})
+	}
+}
+
+type sizeMarshaler struct{}
+
+func (sizeMarshaler) MarshalJSON() ([]byte, error) { panic("MarshalJSON called by EstimateSize") }
+
+type sizeCycle struct {
+	Next *sizeCycle
+}
+
+type sizeBranchingCycle struct {
+	A, B *sizeBranchingCycle
+}
+
+func TestEstimateSizeInexact(t *testing.T) {
+	// Methods are not called; their values count as a few bytes.
+	if got := EstimateSize([]sizeMarshaler{{}, {}}); got != 2*unknownSize+3 {
+		t.Errorf("EstimateSize of Marshalers = %d, want %d", got, 2*unknownSize+3)
+	}
+	if got := EstimateSize(map[time.Time]int{{}: 1}); got != unknownSize+6 {
+		t.Errorf("EstimateSize with TextMarshaler key = %d, want %d", got, unknownSize+6)
+	}
+	// Escaped characters are counted as themselves.
+	if got, want := EstimateSize("a\nb<"), len(`"a\nb<"`)-1; got != want {
+		t.Errorf("EstimateSize of escaped string = %d, want %d", got, want)
+	}
+	// A cycle is counted up to where it is found, rather than endlessly.
+	c := new(sizeCycle)
+	c.Next = c
+	if got := EstimateSize(c); got <= 0 {
+		t.Errorf("EstimateSize of a cycle = %d, want a positive size", got)
+	}
+	// Nor are the paths through a branching cycle counted one by one.
+	b := new(sizeBranchingCycle)
+	b.A, b.B = b, b
+	if got := EstimateSize(b); got <= 0 {
+		t.Errorf("EstimateSize of a branching cycle = %d, want a positive size", got)
+	}
+	// Values that cannot be encoded are not counted.
+	if got := EstimateSize([]any{func() {}}); got != 2 {
+		t.Errorf("EstimateSize of a func = %d, want 2", got)
+	}
+	// Receive-only channels are encoded by draining them, so their
+	// elements are not known.
+	ch := make(chan int)
+	close(ch)
+	if got, want := EstimateSize(struct{ C <-chan int }{ch}), len(`{"C":}`)+unknownSize; got != want {
+		t.Errorf("EstimateSize of a chan field = %d, want %d", got, want)
+	}
+	if got := EstimateSize(struct{ C chan int }{ch}); got != len(`{"C":}`) {
+		t.Errorf("EstimateSize of a bidirectional chan field = %d, want %d", got, len(`{"C":}`))
+	}
+}
@@ -7,7 +7,11 @@ package json
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"reflect"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // A Decoder reads and decodes JSON values from an input stream.
@@ -20,8 +24,15 @@ type Decoder struct {
 	scan    scanner
 	err     error
 
+	linesp    int   // end of data in buf already counted by countLines
+	line      int   // number of newlines before linesp
+	lineStart int64 // input offset of the start of the current line
+
+	checkBOM bool // whether to look for a byte order mark before reading
+
 	tokenState int
 	tokenStack []int
+	tokenKeys  []keySet // of the objects in tokenStack
 }
 
 // NewDecoder returns a new decoder that reads from r.
@@ -41,6 +52,57 @@ func (dec *Decoder) UseNumber() { dec.d.useNumber = true }
 // non-ignored, exported fields in the destination.
 func (dec *Decoder) DisallowUnknownFields() { dec.d.disallowUnknownFields = true }
 
+// SkipBOM causes the Decoder to skip a UTF-8 byte order mark at the start
+// of its input, as written by some Windows tools. Input starting with a
+// UTF-16 byte order mark is rejected with an error saying so.
+// Without SkipBOM, a byte order mark is reported as an invalid character.
+// SkipBOM must be called before the first call to another Decoder method.
+func (dec *Decoder) SkipBOM() { dec.checkBOM = true }
+
+// skipBOM skips a UTF-8 byte order mark at the start of the input
+// and rejects a UTF-16 one. It is called once, before the first read.
+func (dec *Decoder) skipBOM() error {
+	dec.checkBOM = false
+	var err error
+	for len(dec.buf) < len(utf8BOM) && err == nil {
+		err = dec.refill()
+	}
+	if err != nil && err != io.EOF {
+		dec.err = err
+		return err
+	}
+	switch {
+	case bytes.HasPrefix(dec.buf, utf8BOM):
+		dec.scanp = len(utf8BOM)
+	case bytes.HasPrefix(dec.buf, []byte{0xfe, 0xff}), bytes.HasPrefix(dec.buf, []byte{0xff, 0xfe}):
+		dec.err = &SyntaxError{"input begins with a UTF-16 byte order mark; JSON must be encoded as UTF-8", 0}
+		return dec.err
+	}
+	return nil
+}
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// SetDuplicateKeyPolicy selects how the Decoder decodes objects with
+// duplicate keys. The default is [DuplicateKeysLastWins].
+//
+// The policy also applies to the objects read with [Decoder.Token]: with
+// [DuplicateKeysFirstWins] and [DuplicateKeysError], Token skips a member
+// whose key is a duplicate, returning the token after it, and with
+// DuplicateKeysError it returns a [SemanticError] wrapping
+// [ErrDuplicateKey] in place of the key. Reading may go on after the error.
+func (dec *Decoder) SetDuplicateKeyPolicy(p DuplicateKeyPolicy) { dec.d.duplicateKeys = p }
+
+// SetDuplicateKeyFilter sets the filter that bounds the memory used to
+// find duplicate keys in large objects, or, if f is nil, the default,
+// has every key remembered exactly. See [DuplicateKeyFilter].
+func (dec *Decoder) SetDuplicateKeyFilter(f *DuplicateKeyFilter) { dec.d.duplicateKeyFilter = f }
+
+// SetOverflowPolicy selects how the Decoder decodes a number into an
+// integer or floating-point value whose type cannot represent it.
+// The default is [OverflowError].
+func (dec *Decoder) SetOverflowPolicy(p OverflowPolicy) { dec.d.overflow = p }
+
 // Decode reads the next JSON-encoded value from its
 // input and stores it in the value pointed to by v.
 //
@@ -78,15 +140,293 @@ func (dec *Decoder) Decode(v any) error {
 	return err
 }
 
+// ReadRaw reads the next JSON-encoded value from its input and returns
+// its exact bytes, without leading white space, as Decode(&raw) would for
+// a [RawMessage] raw. The value is checked for syntax but not decoded.
+//
+// The returned RawMessage refers to the Decoder's buffer and is valid
+// only until the next call to a Decoder method; callers that keep it
+// must copy it.
+func (dec *Decoder) ReadRaw() (RawMessage, error) {
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return nil, err
+	}
+
+	if !dec.tokenValueAllowed() {
+		return nil, &SyntaxError{msg: "not at beginning of value", Offset: dec.InputOffset()}
+	}
+
+	n, err := dec.readValue()
+	if err != nil {
+		return nil, err
+	}
+	raw := dec.buf[dec.scanp : dec.scanp+n : dec.scanp+n]
+	dec.scanp += n
+	dec.tokenValueEnd()
+
+	return bytes.TrimLeft(raw, " \t\r\n"), nil
+}
+
+// ReadStringTo reads the next JSON-encoded value from its input, which must be
+// a string, and writes the unescaped contents of the string to w as they are
+// decoded, so that the string is never held in memory as a whole.
+// This is suited to large embedded payloads such as base64-encoded files.
+// Invalid UTF-8 and invalid UTF-16 surrogate pairs are replaced by U+FFFD,
+// as by [Unmarshal].
+//
+// As with [Unmarshal], a JSON null is read without writing anything.
+// If the next value is of any other type, ReadStringTo returns an
+// [UnmarshalTypeError] and does not consume it. An error from w leaves
+// the Decoder in the middle of the string, so it is unusable afterwards.
+func (dec *Decoder) ReadStringTo(w io.Writer) error {
+	if dec.err != nil {
+		return dec.err
+	}
+
+	if err := dec.tokenPrepareForDecode(); err != nil {
+		return err
+	}
+
+	if !dec.tokenValueAllowed() {
+		return &SyntaxError{msg: "not at beginning of value", Offset: dec.InputOffset()}
+	}
+
+	c, err := dec.peek()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '"':
+	case 'n':
+		if _, err := dec.ReadRaw(); err != nil {
+			return err
+		}
+		return nil
+	case '{', '[', 't', 'f', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return &UnmarshalTypeError{Value: valueKind([]byte{c}), Type: reflect.TypeFor[string](), Offset: dec.InputOffset()}
+	default:
+		dec.err = &SyntaxError{msg: "invalid character " + quoteChar(c) + " looking for beginning of value", Offset: dec.InputOffset()}
+		return dec.err
+	}
+
+	if err := dec.copyString(w); err != nil {
+		dec.err = err
+		return err
+	}
+	dec.tokenValueEnd()
+	return nil
+}
+
+// copyString unescapes the string literal at dec.buf[dec.scanp:] to w,
+// reading more input as needed.
+func (dec *Decoder) copyString(w io.Writer) error {
+	const flushSize = 4096
+	out := make([]byte, 0, flushSize+utf8.UTFMax)
+	dec.scanp++ // opening quote
+	for {
+		if err := dec.ensure(1); err != nil {
+			return err
+		}
+		c := dec.buf[dec.scanp]
+		switch {
+		case c == '"':
+			dec.scanp++
+			_, err := w.Write(out)
+			return err
+		case c == '\\':
+			if err := dec.ensure(2); err != nil {
+				return err
+			}
+			switch e := dec.buf[dec.scanp+1]; e {
+			case '"', '\\', '/':
+				out = append(out, e)
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'u':
+				if err := dec.ensure(6); err != nil {
+					return err
+				}
+				r := getu4(dec.buf[dec.scanp:])
+				if r < 0 {
+					return &SyntaxError{"invalid character in \\u hexadecimal character escape", dec.InputOffset() + 2}
+				}
+				if utf16.IsSurrogate(r) {
+					// A missing second half is decoded as U+FFFD below.
+					if err := dec.ensure(12); err != nil && err != io.ErrUnexpectedEOF {
+						return err
+					}
+					r = utf16.DecodeRune(r, getu4(dec.buf[dec.scanp+6:]))
+					if r != utf8.RuneError {
+						dec.scanp += 6
+					}
+				}
+				out = utf8.AppendRune(out, r)
+				dec.scanp += 4
+			default:
+				return &SyntaxError{"invalid character " + quoteChar(e) + " in string escape code", dec.InputOffset() + 2}
+			}
+			dec.scanp += 2
+		case c < ' ':
+			return &SyntaxError{"invalid character " + quoteChar(c) + " in string literal", dec.InputOffset() + 1}
+		case c < utf8.RuneSelf:
+			out = append(out, c)
+			dec.scanp++
+		default:
+			if err := dec.ensure(utf8.UTFMax); err != nil && err != io.ErrUnexpectedEOF {
+				return err
+			}
+			r, size := utf8.DecodeRune(dec.buf[dec.scanp:])
+			out = utf8.AppendRune(out, r)
+			dec.scanp += size
+		}
+		if len(out) >= flushSize {
+			if _, err := w.Write(out); err != nil {
+				return err
+			}
+			out = out[:0]
+		}
+	}
+}
+
+// ensure reads input until at least n bytes are available in
+// dec.buf[dec.scanp:], returning io.ErrUnexpectedEOF if the input ends first.
+func (dec *Decoder) ensure(n int) error {
+	for len(dec.buf)-dec.scanp < n {
+		err := dec.refill()
+		if len(dec.buf)-dec.scanp >= n {
+			break
+		}
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Buffered returns a reader of the data remaining in the Decoder's
 // buffer. The reader is valid until the next call to [Decoder.Decode].
 func (dec *Decoder) Buffered() io.Reader {
 	return bytes.NewReader(dec.buf[dec.scanp:])
 }
 
+// BufferedLen returns the number of bytes available from [Decoder.Buffered]:
+// those read from the input but not yet decoded. It does not read any more.
+func (dec *Decoder) BufferedLen() int {
+	return len(dec.buf) - dec.scanp
+}
+
+// SkipToNextValue discards input up to the start of the next value at the
+// current nesting level, so that a stream can be read on after a bad value.
+// It clears the error after a [SyntaxError] or an unexpected end of input,
+// which otherwise make all later calls fail, but not an error from the
+// underlying reader, which it returns.
+//
+// At the top level, input is discarded through the next newline, so that
+// a bad record in JSON Lines input is skipped; after an error that did not
+// disturb the stream, such as an [UnmarshalTypeError], only the newline
+// ending the record is discarded. Within an array or object being read
+// with [Decoder.Token], input is discarded through the next comma, or up to
+// the closing delimiter, of that array or object.
+func (dec *Decoder) SkipToNextValue() error {
+	// After a syntax error in Decode, the bad value may be preceded by
+	// the white space, and newline, that ended the previous one.
+	leadingSpace := false
+	if dec.err != nil {
+		if _, ok := dec.err.(*SyntaxError); !ok && dec.err != io.ErrUnexpectedEOF {
+			return dec.err
+		}
+		dec.err = nil
+		leadingSpace = true
+	}
+	inArray, inObject := false, false
+	switch dec.tokenState {
+	case tokenArrayStart, tokenArrayValue, tokenArrayComma:
+		inArray = true
+	case tokenObjectStart, tokenObjectKey, tokenObjectColon, tokenObjectValue, tokenObjectComma:
+		inObject = true
+	}
+
+	var open []byte // opening delimiters of nested arrays and objects
+	inString, escaped := false, false
+	var err error
+	for {
+		for ; dec.scanp < len(dec.buf); dec.scanp++ {
+			c := dec.buf[dec.scanp]
+			if !isSpace(c) {
+				leadingSpace = false
+			}
+			switch {
+			case leadingSpace:
+			case !inArray && !inObject:
+				if c == '\n' {
+					dec.scanp++
+					return nil
+				}
+			case inString:
+				if escaped {
+					escaped = false
+				} else {
+					escaped = c == '\\'
+					inString = c != '"'
+				}
+			case c == '"':
+				inString = true
+			case c == '[' || c == '{':
+				open = append(open, c)
+			case (c == ']' || c == '}') && len(open) > 0:
+				// Ignore a closing delimiter that does not match.
+				if open[len(open)-1] == c-2 {
+					open = open[:len(open)-1]
+				}
+			case c == ']' || c == '}':
+				dec.tokenState = tokenArrayComma
+				if inObject {
+					dec.tokenState = tokenObjectComma
+				}
+				return nil
+			case c == ',' && len(open) == 0:
+				dec.scanp++
+				dec.tokenState = tokenArrayValue
+				if inObject {
+					dec.tokenState = tokenObjectKey
+				}
+				return nil
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			dec.err = err
+			return err
+		}
+		err = dec.refill()
+	}
+}
+
 // readValue reads a JSON value into dec.buf.
 // It returns the length of the encoding.
 func (dec *Decoder) readValue() (int, error) {
+	if dec.checkBOM {
+		if err := dec.skipBOM(); err != nil {
+			return 0, err
+		}
+	}
 	dec.scan.reset()
 
 	scanp := dec.scanp
@@ -147,6 +487,8 @@ func (dec *Decoder) refill() error {
 	// Make room to read more into the buffer.
 	// First slide down data already consumed.
 	if dec.scanp > 0 {
+		dec.countLines()
+		dec.linesp = 0
 		dec.scanned += int64(dec.scanp)
 		n := copy(dec.buf, dec.buf[dec.scanp:])
 		dec.buf = dec.buf[:n]
@@ -182,10 +524,17 @@ type Encoder struct {
 	w          io.Writer
 	err        error
 	escapeHTML bool
+	escapeRune func(rune) bool
+	escapeBuf  []byte
 
 	indentBuf    []byte
 	indentPrefix string
 	indentValue  string
+
+	bufSize int // set by SetBufferSize, or 0 for the default
+
+	tokenState int // as for Decoder, for WriteToken
+	tokenStack []int
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -199,15 +548,40 @@ func NewEncoder(w io.Writer) *Encoder {
 // See the documentation for [Marshal] for details about the
 // conversion of Go values to JSON.
 func (enc *Encoder) Encode(v any) error {
+	return enc.encode(v, enc.indentPrefix, enc.indentValue)
+}
+
+// EncodeIndent is like [Encoder.Encode], but formats v as if indented by
+// the package-level function Indent(dst, src, prefix, indent), regardless of
+// any indentation set by [Encoder.SetIndent]. It does not change the
+// indentation used by subsequent calls to Encode.
+// Calling EncodeIndent(v, "", "") writes v in compact form.
+func (enc *Encoder) EncodeIndent(v any, prefix, indent string) error {
+	return enc.encode(v, prefix, indent)
+}
+
+func (enc *Encoder) encode(v any, prefix, indent string) error {
 	if enc.err != nil {
 		return enc.err
 	}
 
 	e := newEncodeState()
-	defer encodeStatePool.Put(e)
+	defer putEncodeState(e, enc.bufferSize())
+	e.Grow(enc.bufSize)
+	defer enc.releaseBuffers()
 
+	if prefix == "" && indent == "" && enc.escapeRune == nil {
+		e.stream = enc.w
+	}
 	err := e.marshal(v, encOpts{escapeHTML: enc.escapeHTML})
 	if err != nil {
+		if e.streamErr != nil {
+			enc.err = e.streamErr
+		} else if e.flushed {
+			// Part of the value has been written; another value after
+			// it would not be valid JSON.
+			enc.err = err
+		}
 		return err
 	}
 
@@ -220,13 +594,17 @@ func (enc *Encoder) Encode(v any) error {
 	e.WriteByte('\n')
 
 	b := e.Bytes()
-	if enc.indentPrefix != "" || enc.indentValue != "" {
-		enc.indentBuf, err = appendIndent(enc.indentBuf[:0], b, enc.indentPrefix, enc.indentValue)
+	if prefix != "" || indent != "" {
+		enc.indentBuf, err = appendIndent(enc.indentBuf[:0], b, prefix, indent)
 		if err != nil {
 			return err
 		}
 		b = enc.indentBuf
 	}
+	if enc.escapeRune != nil {
+		enc.escapeBuf = appendEscapeRunes(enc.escapeBuf[:0], b, enc.escapeRune)
+		b = enc.escapeBuf
+	}
 	if _, err = enc.w.Write(b); err != nil {
 		enc.err = err
 	}
@@ -241,6 +619,35 @@ func (enc *Encoder) SetIndent(prefix, indent string) {
 	enc.indentValue = indent
 }
 
+// SetBufferSize sets the size, in bytes, of the buffer in which the encoder
+// encodes each value. Encoding a larger value grows the buffer only for the
+// duration of the call: afterwards, buffers larger than n are released rather
+// than kept for later calls, so that one very large value does not keep its
+// memory in use. Calling SetBufferSize with n <= 0 restores the default of
+// 64 KiB.
+func (enc *Encoder) SetBufferSize(n int) {
+	enc.bufSize = max(n, 0)
+}
+
+// bufferSize returns the largest buffer the encoder keeps between calls.
+func (enc *Encoder) bufferSize() int {
+	if enc.bufSize > 0 {
+		return enc.bufSize
+	}
+	return maxPooledBufferSize
+}
+
+// releaseBuffers releases the buffers of the encoder larger than its
+// buffer size.
+func (enc *Encoder) releaseBuffers() {
+	if cap(enc.indentBuf) > enc.bufferSize() {
+		enc.indentBuf = nil
+	}
+	if cap(enc.escapeBuf) > enc.bufferSize() {
+		enc.escapeBuf = nil
+	}
+}
+
 // SetEscapeHTML specifies whether problematic HTML characters
 // should be escaped inside JSON quoted strings.
 // The default behavior is to escape &, <, and > to \u0026, \u003c, and \u003e
@@ -252,6 +659,105 @@ func (enc *Encoder) SetEscapeHTML(on bool) {
 	enc.escapeHTML = on
 }
 
+// SetEscapeRune specifies a function that selects additional characters to
+// be escaped inside JSON quoted strings, as described for
+// [MarshalOptions.EscapeRune]. Calling SetEscapeRune(nil) restores the
+// default behavior.
+func (enc *Encoder) SetEscapeRune(escape func(r rune) bool) {
+	enc.escapeRune = escape
+}
+
+// WriteToken writes the next JSON token to the stream, so that a value can
+// be written a token at a time, as read by [Decoder.Token]. Commas and colons
+// are inserted as needed, a string written where an object key is expected
+// is the key, and a complete top-level value is followed by a newline, as
+// with [Encoder.Encode]. Values other than tokens, such as structs, are
+// encoded as by Encode, as a single value. The output is compact,
+// regardless of [Encoder.SetIndent].
+//
+// WriteToken returns an error if the delimiters written are not properly
+// nested and matched, or if an object key is not a string.
+// Calls to Encode must not be interleaved with tokens of an unfinished value.
+func (enc *Encoder) WriteToken(t Token) error {
+	if enc.err != nil {
+		return enc.err
+	}
+
+	e := newEncodeState()
+	defer putEncodeState(e, enc.bufferSize())
+	defer enc.releaseBuffers()
+
+	switch enc.tokenState {
+	case tokenArrayComma:
+		if t != Delim(']') {
+			e.WriteByte(',')
+		}
+	case tokenObjectStart, tokenObjectComma:
+		if t == Delim('}') {
+			break
+		}
+		key, ok := t.(string)
+		if !ok {
+			return fmt.Errorf("json: object key token must be a string, got %T", t)
+		}
+		if enc.tokenState == tokenObjectComma {
+			e.WriteByte(',')
+		}
+		e.Write(appendString(e.AvailableBuffer(), key, enc.escapeHTML))
+		e.WriteByte(':')
+		enc.tokenState = tokenObjectColon
+		return enc.writeToken(e.Bytes())
+	}
+
+	switch t {
+	case Delim('['), Delim('{'):
+		enc.tokenStack = append(enc.tokenStack, enc.tokenState)
+		enc.tokenState = tokenArrayStart
+		if t == Delim('{') {
+			enc.tokenState = tokenObjectStart
+		}
+		e.WriteByte(byte(t.(Delim)))
+		return enc.writeToken(e.Bytes())
+	case Delim(']'), Delim('}'):
+		if t == Delim(']') && enc.tokenState != tokenArrayStart && enc.tokenState != tokenArrayComma ||
+			t == Delim('}') && enc.tokenState != tokenObjectStart && enc.tokenState != tokenObjectComma {
+			return fmt.Errorf("json: unexpected %v token", t)
+		}
+		enc.tokenState = enc.tokenStack[len(enc.tokenStack)-1]
+		enc.tokenStack = enc.tokenStack[:len(enc.tokenStack)-1]
+		e.WriteByte(byte(t.(Delim)))
+	default:
+		if _, ok := t.(Delim); ok {
+			return fmt.Errorf("json: invalid delimiter %v", t)
+		}
+		if err := e.marshal(t, encOpts{escapeHTML: enc.escapeHTML}); err != nil {
+			return err
+		}
+	}
+	switch enc.tokenState {
+	case tokenTopValue:
+		e.WriteByte('\n')
+	case tokenArrayStart:
+		enc.tokenState = tokenArrayComma
+	case tokenObjectColon:
+		enc.tokenState = tokenObjectComma
+	}
+	return enc.writeToken(e.Bytes())
+}
+
+// writeToken writes the encoding b of a token, or of its separators.
+func (enc *Encoder) writeToken(b []byte) error {
+	if enc.escapeRune != nil {
+		enc.escapeBuf = appendEscapeRunes(enc.escapeBuf[:0], b, enc.escapeRune)
+		b = enc.escapeBuf
+	}
+	if _, err := enc.w.Write(b); err != nil {
+		enc.err = err
+		return err
+	}
+	return nil
+}
+
 // RawMessage is a raw encoded JSON value.
 // It implements [Marshaler] and [Unmarshaler] and can
 // be used to delay JSON decoding or precompute a JSON encoding.
@@ -396,6 +902,7 @@ func (dec *Decoder) Token() (Token, error) {
 			}
 			dec.scanp++
 			dec.tokenStack = append(dec.tokenStack, dec.tokenState)
+			dec.tokenKeys = append(dec.tokenKeys, keySet{})
 			dec.tokenState = tokenObjectStart
 			return Delim('{'), nil
 
@@ -406,6 +913,8 @@ func (dec *Decoder) Token() (Token, error) {
 			dec.scanp++
 			dec.tokenState = dec.tokenStack[len(dec.tokenStack)-1]
 			dec.tokenStack = dec.tokenStack[:len(dec.tokenStack)-1]
+			dec.tokenKeys[len(dec.tokenKeys)-1] = keySet{}
+			dec.tokenKeys = dec.tokenKeys[:len(dec.tokenKeys)-1]
 			dec.tokenValueEnd()
 			return Delim('}'), nil
 
@@ -433,6 +942,7 @@ func (dec *Decoder) Token() (Token, error) {
 		case '"':
 			if dec.tokenState == tokenObjectStart || dec.tokenState == tokenObjectKey {
 				var x string
+				off := dec.InputOffset()
 				old := dec.tokenState
 				dec.tokenState = tokenTopValue
 				err := dec.Decode(&x)
@@ -441,6 +951,16 @@ func (dec *Decoder) Token() (Token, error) {
 					return nil, err
 				}
 				dec.tokenState = tokenObjectColon
+				if dec.d.duplicateKeys != DuplicateKeysLastWins && dec.tokenKeys[len(dec.tokenKeys)-1].add(x, dec.d.duplicateKeyFilter) {
+					// Skip the member.
+					if err := dec.Decode(new(RawMessage)); err != nil {
+						return nil, err
+					}
+					if dec.d.duplicateKeys == DuplicateKeysError {
+						return nil, newSemanticError(int(off), nil, ErrDuplicateKey, "json: duplicate key %q in object", x)
+					}
+					continue
+				}
 				return x, nil
 			}
 			fallthrough
@@ -484,7 +1004,41 @@ func (dec *Decoder) More() bool {
 	return err == nil && c != ']' && c != '}'
 }
 
+// MoreElements reports whether there is another element in the array, or
+// member in the object, that is being read with [Decoder.Token]. Unlike
+// More, it reports false outside of an array or object, where More
+// reports whether there is another top-level value.
+func (dec *Decoder) MoreElements() bool {
+	return dec.tokenState != tokenTopValue && dec.More()
+}
+
+// MoreValues reports whether there is another top-level value in the input,
+// reading from it as needed. It reports false while an array or object is
+// being read with [Decoder.Token], even if more values follow it.
+func (dec *Decoder) MoreValues() bool {
+	if dec.tokenState != tokenTopValue {
+		return false
+	}
+	_, err := dec.peek()
+	return err == nil
+}
+
+// AtEOF reports whether the whole input has been read: whether nothing but
+// white space follows the values read so far and the [io.Reader] has
+// returned [io.EOF]. It reads from the input as needed, so it blocks
+// until more data or the end of the input arrives. After an error from
+// the reader other than io.EOF, AtEOF reports false.
+func (dec *Decoder) AtEOF() bool {
+	_, err := dec.peek()
+	return err == io.EOF
+}
+
 func (dec *Decoder) peek() (byte, error) {
+	if dec.checkBOM {
+		if err := dec.skipBOM(); err != nil {
+			return 0, err
+		}
+	}
 	var err error
 	for {
 		for i := dec.scanp; i < len(dec.buf); i++ {
@@ -509,3 +1063,30 @@ func (dec *Decoder) peek() (byte, error) {
 func (dec *Decoder) InputOffset() int64 {
 	return dec.scanned + int64(dec.scanp)
 }
+
+// InputLine returns the 1-based line number of the current decoder position,
+// as given by [Decoder.InputOffset]. Lines are terminated by '\n'.
+func (dec *Decoder) InputLine() int {
+	dec.countLines()
+	return dec.line + 1
+}
+
+// InputColumn returns the 1-based column of the current decoder position,
+// as given by [Decoder.InputOffset]. The column is counted in bytes
+// from the start of the line.
+func (dec *Decoder) InputColumn() int {
+	dec.countLines()
+	return int(dec.InputOffset()-dec.lineStart) + 1
+}
+
+// countLines advances the line count over the data consumed since
+// the last call, so that the cost of line tracking is proportional
+// to the input read rather than to the number of calls.
+func (dec *Decoder) countLines() {
+	b := dec.buf[dec.linesp:dec.scanp]
+	if i := bytes.LastIndexByte(b, '\n'); i >= 0 {
+		dec.line += bytes.Count(b, []byte{'\n'})
+		dec.lineStart = dec.scanned + int64(dec.linesp+i+1)
+	}
+	dec.linesp = dec.scanp
+}
@@ -16,8 +16,11 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode"
 )
 
 // TODO(https://go.dev/issue/52751): Replace with native testing support.
@@ -154,6 +157,21 @@ func TestEncoderIndent(t *testing.T) {
 	}
 }
 
+func TestEncoderEncodeIndent(t *testing.T) {
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	enc.EncodeIndent(map[string]int{"a": 1}, "", "  ")
+	enc.Encode(map[string]int{"b": 2})
+	enc.SetIndent(">", ".")
+	enc.EncodeIndent(map[string]int{"c": 3}, "", "")
+	enc.Encode(map[string]int{"d": 4})
+	want := "{\n  \"a\": 1\n}\n{\"b\":2}\n{\"c\":3}\n{\n>.\"d\": 4\n>}\n"
+	if have := buf.String(); have != want {
+		t.Error("Encode mismatch:")
+		diff(t, []byte(have), []byte(want))
+	}
+}
+
 type strMarshaler string
 
 func (s strMarshaler) MarshalJSON() ([]byte, error) {
@@ -166,6 +184,68 @@ func (s *strPtrMarshaler) MarshalJSON() ([]byte, error) {
 	return []byte(*s), nil
 }
 
+func TestEncoderSetEscapeRune(t *testing.T) {
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	enc.SetEscapeRune(func(r rune) bool { return r > unicode.MaxASCII })
+	in := map[string]any{"é": []any{"a\u2029b", "😀 \\u00e9", RawMessage(`"ü\""`)}}
+	if err := enc.Encode(in); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	enc.SetEscapeRune(nil)
+	if err := enc.Encode("é"); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	want := `{"\u00e9":["a\u2029b","\ud83d\ude00 \\u00e9","\u00fc\""]}` + "\n\"é\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Encode:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}
+
+func TestEncoderSetBufferSize(t *testing.T) {
+	var buf strings.Builder
+	enc := NewEncoder(&buf)
+	enc.SetIndent("", " ")
+	enc.SetBufferSize(1 << 10)
+	if err := enc.Encode([]int{1, 2}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if enc.indentBuf == nil {
+		t.Errorf("Encode released the buffer of a small value")
+	}
+	big := strings.Repeat("x", 4<<10)
+	if err := enc.Encode([]string{big}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if enc.indentBuf != nil {
+		t.Errorf("Encode kept a buffer of %d bytes, want at most %d", cap(enc.indentBuf), 1<<10)
+	}
+	want := "[\n 1,\n 2\n]\n[\n \"" + big + "\"\n]\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Encode:\n\tgot:  %.40q\n\twant: %.40q", got, want)
+	}
+
+	// The default size keeps the buffer.
+	enc.SetBufferSize(0)
+	if err := enc.Encode([]string{big}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if enc.indentBuf == nil {
+		t.Errorf("Encode released a buffer of %d bytes with the default size", len(big))
+	}
+}
+
+func TestPutEncodeState(t *testing.T) {
+	e := newEncodeState()
+	e.Grow(maxPooledBufferSize + 1)
+	putEncodeState(e, maxPooledBufferSize)
+	for range 10 {
+		if e2 := newEncodeState(); e2 == e {
+			t.Fatalf("newEncodeState returned an encodeState with a buffer of %d bytes", e.Cap())
+		}
+	}
+}
+
 func TestEncoderSetEscapeHTML(t *testing.T) {
 	var c C
 	var ct CText
@@ -289,6 +369,63 @@ func TestDecoderBuffered(t *testing.T) {
 	}
 }
 
+func TestDecoderIntrospection(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(` [1, {"a": 2}] 3 `))
+	if n := dec.BufferedLen(); n != 0 {
+		t.Errorf("BufferedLen before reading = %d, want 0", n)
+	}
+	if !dec.MoreValues() || dec.MoreElements() || dec.AtEOF() {
+		t.Fatalf("at start: MoreValues, MoreElements, AtEOF = %v, %v, %v, want true, false, false", dec.MoreValues(), dec.MoreElements(), dec.AtEOF())
+	}
+	if n := dec.BufferedLen(); n != 16 {
+		t.Errorf("BufferedLen after peeking = %d, want 16", n)
+	}
+
+	// Step through the tokens of the first value, checking what is
+	// reported before each one.
+	type state struct {
+		tok                 Token
+		moreElems, moreVals bool
+	}
+	want := []state{
+		{Delim('['), false, true},
+		{1.0, true, false},
+		{Delim('{'), true, false},
+		{"a", true, false},
+		{2.0, true, false},
+		{Delim('}'), false, false},
+		{Delim(']'), false, false},
+		{3.0, false, true},
+	}
+	for i, w := range want {
+		if got := dec.MoreElements(); got != w.moreElems {
+			t.Errorf("before token %d (%v): MoreElements = %v, want %v", i, w.tok, got, w.moreElems)
+		}
+		if got := dec.MoreValues(); got != w.moreVals {
+			t.Errorf("before token %d (%v): MoreValues = %v, want %v", i, w.tok, got, w.moreVals)
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			t.Fatalf("Token %d error: %v", i, err)
+		}
+		if tok != w.tok {
+			t.Fatalf("Token %d = %v, want %v", i, tok, w.tok)
+		}
+	}
+	if dec.MoreValues() || !dec.AtEOF() {
+		t.Errorf("at end: MoreValues, AtEOF = %v, %v, want false, true", dec.MoreValues(), dec.AtEOF())
+	}
+	if n := dec.BufferedLen(); n != 1 {
+		t.Errorf("BufferedLen at end = %d, want 1 for the trailing space", n)
+	}
+
+	// A failing reader is not at the end of its input.
+	dec = NewDecoder(iotest.ErrReader(io.ErrClosedPipe))
+	if dec.AtEOF() || dec.MoreValues() {
+		t.Errorf("after read error: AtEOF, MoreValues = %v, %v, want false, false", dec.AtEOF(), dec.MoreValues())
+	}
+}
+
 func nlines(s string, n int) string {
 	if n <= 0 {
 		return ""
@@ -383,6 +520,260 @@ type decodeThis struct {
 	v any
 }
 
+func TestDecoderReadRaw(t *testing.T) {
+	const in = ` {"a": [1, 2]} 3.5 [ "x" , null ]`
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(in)))
+	var got []string
+	for {
+		raw, err := dec.ReadRaw()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("ReadRaw error: %v", err)
+		}
+		got = append(got, string(raw))
+	}
+	want := []string{`{"a": [1, 2]}`, `3.5`, `[ "x" , null ]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadRaw:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	// ReadRaw works within a token stream.
+	dec = NewDecoder(strings.NewReader(`[{"b": 1}, 2]`))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("Token error: %v", err)
+	}
+	raw, err := dec.ReadRaw()
+	if err != nil || string(raw) != `{"b": 1}` {
+		t.Errorf("ReadRaw = %q, %v, want %q", raw, err, `{"b": 1}`)
+	}
+	if _, err := dec.ReadRaw(); err != nil {
+		t.Errorf("ReadRaw error: %v", err)
+	}
+	if tok, err := dec.Token(); err != nil || tok != Delim(']') {
+		t.Errorf("Token = %v, %v, want ]", tok, err)
+	}
+
+	dec = NewDecoder(strings.NewReader(`{"a" 1}`))
+	if _, err := dec.ReadRaw(); err == nil {
+		t.Error("ReadRaw of invalid input error = nil, want SyntaxError")
+	}
+}
+
+func TestDecoderReadStringTo(t *testing.T) {
+	long := strings.Repeat("abcdefgh", 1000)
+	in := `["a\"b\\c\/\b\f\n\r\t", "\u00e9\ud83d\ude00\ud800x", "é` + "\xff" + `", null, "` + long + `", 1]`
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(in)))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("Token error: %v", err)
+	}
+	want := []string{"a\"b\\c/\b\f\n\r\t", "é😀\ufffdx", "é\ufffd", "", long}
+	for i, want := range want {
+		var buf strings.Builder
+		if err := dec.ReadStringTo(&buf); err != nil {
+			t.Fatalf("ReadStringTo %d error: %v", i, err)
+		}
+		if got := buf.String(); got != want {
+			t.Errorf("ReadStringTo %d:\n\tgot:  %q\n\twant: %q", i, got, want)
+		}
+	}
+	var buf strings.Builder
+	err := dec.ReadStringTo(&buf)
+	if _, ok := err.(*UnmarshalTypeError); !ok {
+		t.Errorf("ReadStringTo(number) error = %v, want UnmarshalTypeError", err)
+	}
+	var n int
+	if err := dec.Decode(&n); err != nil || n != 1 {
+		t.Errorf("Decode = %d, %v, want 1", n, err)
+	}
+
+	dec = NewDecoder(strings.NewReader(` xyz`))
+	err = dec.ReadStringTo(&buf)
+	if serr, ok := err.(*SyntaxError); !ok || serr.Offset != 1 || serr.Error() != "invalid character 'x' looking for beginning of value" {
+		t.Errorf("ReadStringTo(invalid) error = %#v, want SyntaxError at offset 1", err)
+	}
+
+	for _, in := range []string{`"abc`, `"a\x"`, "\"a\nb\"", `"\u12"`} {
+		dec := NewDecoder(strings.NewReader(in))
+		if err := dec.ReadStringTo(io.Discard); err == nil {
+			t.Errorf("ReadStringTo(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestDecoderSkipBOM(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in      string
+		skip    bool
+		want    []any
+		wantErr string
+	}{
+		{Name("UTF-8 BOM"), "\xef\xbb\xbf{\"a\": 1} 2", true, []any{map[string]any{"a": 1.0}, 2.0}, ""},
+		{Name("no BOM"), `"\ufeff" 1`, true, []any{"\ufeff", 1.0}, ""},
+		{Name("short input"), "1", true, []any{1.0}, ""},
+		{Name("empty input"), "", true, nil, ""},
+		{Name("UTF-16BE BOM"), "\xfe\xff\x00{\x00}", true, nil, "input begins with a UTF-16 byte order mark; JSON must be encoded as UTF-8"},
+		{Name("UTF-16LE BOM"), "\xff\xfe{\x00}\x00", true, nil, "input begins with a UTF-16 byte order mark; JSON must be encoded as UTF-8"},
+		{Name("BOM not skipped"), "\xef\xbb\xbf{}", false, nil, "invalid character 'ï' looking for beginning of value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			dec := NewDecoder(iotest.OneByteReader(strings.NewReader(tt.in)))
+			if tt.skip {
+				dec.SkipBOM()
+			}
+			var got []any
+			for {
+				var v any
+				err := dec.Decode(&v)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if err.Error() != tt.wantErr {
+						t.Fatalf("%s: Decode error:\n\tgot:  %v\n\twant: %s", tt.Where, err, tt.wantErr)
+					}
+					return
+				}
+				got = append(got, v)
+			}
+			if tt.wantErr != "" {
+				t.Fatalf("%s: Decode error = nil, want %s", tt.Where, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: Decode:\n\tgot:  %#v\n\twant: %#v", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	// Token also skips the BOM.
+	dec := NewDecoder(strings.NewReader("\xef\xbb\xbf[]"))
+	dec.SkipBOM()
+	if tok, err := dec.Token(); err != nil || tok != Delim('[') {
+		t.Errorf("Token = %v, %v, want [", tok, err)
+	}
+}
+
+func TestDecoderSkipToNextValue(t *testing.T) {
+	// JSON Lines with a syntax error, a type error, and a truncated record.
+	in := "{\"n\": 1}\n{\"n\": x, \"s\": \"}\"}\n{\"n\": \"2\"}\n{\"n\": 3}\n{\"n\": "
+	dec := NewDecoder(iotest.OneByteReader(strings.NewReader(in)))
+	var got []string
+	for {
+		var v struct{ N int }
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			got = append(got, fmt.Sprintf("error at %d", dec.InputOffset()))
+			if err := dec.SkipToNextValue(); err != nil {
+				t.Fatalf("SkipToNextValue error: %v", err)
+			}
+			continue
+		}
+		got = append(got, strconv.Itoa(v.N))
+	}
+	want := []string{"1", "error at 8", "error at 38", "3", "error at 47"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	// Elements of an array, read with Token.
+	dec = NewDecoder(strings.NewReader(`[1, [2, "]"] x, "a", {"b": ]}, 4 5, 6]`))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("Token error: %v", err)
+	}
+	got = nil
+	for dec.More() {
+		var v int
+		if err := dec.Decode(&v); err != nil {
+			got = append(got, "error")
+			if err := dec.SkipToNextValue(); err != nil {
+				t.Fatalf("SkipToNextValue error: %v", err)
+			}
+			continue
+		}
+		got = append(got, strconv.Itoa(v))
+	}
+	if tok, err := dec.Token(); tok != Delim(']') || err != nil {
+		t.Errorf("Token: got %v, %v, want ], nil", tok, err)
+	}
+	want = []string{"1", "error", "error", "error", "4", "error", "6"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	// Members of an object.
+	dec = NewDecoder(strings.NewReader(`{"a": tru, "b": 2}`))
+	if _, err := dec.Token(); err != nil {
+		t.Fatalf("Token error: %v", err)
+	}
+	if key, _ := dec.Token(); key != "a" {
+		t.Fatalf("Token: got %v, want a", key)
+	}
+	if _, err := dec.Token(); err == nil {
+		t.Fatal("Token error: got nil, want non-nil")
+	}
+	if err := dec.SkipToNextValue(); err != nil {
+		t.Fatalf("SkipToNextValue error: %v", err)
+	}
+	var toks []Token
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token error: %v", err)
+		}
+		toks = append(toks, tok)
+	}
+	if want := []Token{"b", 2.0, Delim('}')}; !reflect.DeepEqual(toks, want) {
+		t.Errorf("Token:\n\tgot:  %v\n\twant: %v", toks, want)
+	}
+
+	// Errors from the reader are kept.
+	dec = NewDecoder(iotest.TimeoutReader(strings.NewReader("[1,")))
+	var v any
+	if err := dec.Decode(&v); err != iotest.ErrTimeout {
+		t.Fatalf("Decode error: got %v, want %v", err, iotest.ErrTimeout)
+	}
+	if err := dec.SkipToNextValue(); err != iotest.ErrTimeout {
+		t.Errorf("SkipToNextValue error: got %v, want %v", err, iotest.ErrTimeout)
+	}
+}
+
+func TestDecoderInputLineColumn(t *testing.T) {
+	const in = "{\"a\": 1,\n  \"b\": [true,\n\t\tnull]}\n\"x\""
+	type pos struct{ line, col int }
+	want := []pos{{1, 2}, {1, 5}, {1, 8}, {2, 6}, {2, 9}, {2, 13}, {3, 7}, {3, 8}, {3, 9}, {4, 4}}
+	for _, r := range []struct {
+		name string
+		r    io.Reader
+	}{
+		{"whole", strings.NewReader(in)},
+		{"one byte", iotest.OneByteReader(strings.NewReader(in))},
+	} {
+		t.Run(r.name, func(t *testing.T) {
+			dec := NewDecoder(r.r)
+			var got []pos
+			for {
+				if _, err := dec.Token(); err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatalf("Token error: %v", err)
+				}
+				got = append(got, pos{dec.InputLine(), dec.InputColumn()})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("positions:\n\tgot:  %v\n\twant: %v", got, want)
+			}
+		})
+	}
+}
+
 func TestDecodeInStream(t *testing.T) {
 	tests := []struct {
 		CaseName
@@ -0,0 +1,104 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"unicode/utf8"
+)
+
+// StreamString wraps an io.Reader whose contents encode as a JSON string.
+// The contents are read to EOF and escaped a chunk at a time as the value is
+// encoded, without first being collected into a Go string. When encoding
+// with an [Encoder] that has no indentation or [Encoder.SetEscapeRune]
+// function set, the output is written as it is produced, so that a large
+// file can be embedded in a JSON payload without ever being held in memory
+// as a whole. If the encoding of the value then fails after part of it has
+// been written, that partial output is left in the stream, and the Encoder
+// returns the error from every later call.
+//
+// Struct fields, slice elements, and map values whose type is io.Reader
+// are encoded the same way. A nil Reader encodes as null.
+//
+// The contents are treated as UTF-8 text, and invalid UTF-8 is replaced by
+// U+FFFD. To embed binary data, wrap the reader so that it produces base64,
+// for instance with [encoding/base64.NewEncoder] and [io.Pipe].
+// The Reader is not closed.
+type StreamString struct {
+	io.Reader
+}
+
+var (
+	readerType       = reflect.TypeFor[io.Reader]()
+	streamStringType = reflect.TypeFor[StreamString]()
+)
+
+// streamFlushSize is the amount of buffered output above which encoders of
+// streamed values write the output to encodeState.stream, if set.
+const streamFlushSize = 32 << 10
+
+func readerEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	if v.Type() == streamStringType {
+		v = v.Field(0)
+	}
+	if v.IsNil() {
+		e.WriteString("null")
+		return
+	}
+	r := v.Interface().(io.Reader)
+
+	e.WriteByte('"')
+	buf := make([]byte, 8<<10)
+	carry := 0 // bytes of an incomplete rune kept from the last read
+	for {
+		n, err := r.Read(buf[carry:])
+		data := buf[:carry+n]
+		if err != nil && err != io.EOF {
+			e.error(fmt.Errorf("json: error reading %s: %w", v.Type(), err))
+		}
+		cut := len(data)
+		if err == nil {
+			cut = incompleteRuneStart(data)
+		}
+		if opts.interchange && !utf8.Valid(data[:cut]) {
+			e.error(&UnsupportedValueError{v, "invalid UTF-8 in " + v.Type().String() + " contents"})
+		}
+		// appendString quotes its output; keep only the escaped contents.
+		b := appendString(e.AvailableBuffer(), data[:cut], opts.escapeHTML)
+		e.Write(b[1 : len(b)-1])
+		if err == io.EOF {
+			break
+		}
+		carry = copy(buf, data[cut:])
+		e.flushStream()
+	}
+	e.WriteByte('"')
+}
+
+// incompleteRuneStart returns the offset of an incomplete UTF-8 encoding
+// at the end of b, or len(b) if the encoding of the last rune is complete.
+func incompleteRuneStart(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
+
+// flushStream writes the output accumulated so far to e.stream,
+// if set and the output is large enough to be worth writing.
+func (e *encodeState) flushStream() {
+	if e.stream == nil || e.Len() < streamFlushSize {
+		return
+	}
+	if _, err := e.stream.Write(e.Bytes()); err != nil {
+		e.streamErr = err
+		e.error(err)
+	}
+	e.flushed = true
+	e.Reset()
+}
@@ -0,0 +1,112 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestMarshalStreamString(t *testing.T) {
+	type Upload struct {
+		Name string       `json:"name"`
+		Body io.Reader    `json:"body"`
+		Alt  StreamString `json:"alt"`
+	}
+	// A multi-byte rune is split across reads by the one-byte reader.
+	in := Upload{
+		Name: "f",
+		Body: iotest.OneByteReader(strings.NewReader("é\"<x>\n\xff")),
+		Alt:  StreamString{strings.NewReader("alt")},
+	}
+	got, err := Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `{"name":"f","body":"é\"\u003cx\u003e\n\ufffd","alt":"alt"}`
+	if string(got) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	got, err = Marshal(map[string]any{"a": StreamString{}, "b": []io.Reader{nil}})
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if want := `{"a":null,"b":[null]}`; string(got) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+
+	readErr := errors.New("read failed")
+	_, err = Marshal(StreamString{iotest.ErrReader(readErr)})
+	if !errors.Is(err, readErr) {
+		t.Errorf("Marshal error = %v, want %v", err, readErr)
+	}
+}
+
+// countingWriter records the size of each write.
+type countingWriter struct {
+	bytes.Buffer
+	writes []int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes = append(w.writes, len(p))
+	return w.Buffer.Write(p)
+}
+
+func TestEncoderStreamString(t *testing.T) {
+	body := strings.Repeat("0123456789abcdef", 10000)
+	var w countingWriter
+	enc := NewEncoder(&w)
+	if err := enc.Encode(map[string]StreamString{"body": {strings.NewReader(body)}}); err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	if want := `{"body":"` + body + "\"}\n"; w.String() != want {
+		t.Errorf("Encode output does not match input")
+	}
+	if len(w.writes) < 2 {
+		t.Errorf("Encode wrote output in %d writes, want it streamed in several", len(w.writes))
+	}
+	for _, n := range w.writes {
+		if n > 2*streamFlushSize {
+			t.Errorf("Encode wrote %d bytes at once, want at most %d", n, 2*streamFlushSize)
+		}
+	}
+}
+
+func TestEncoderStreamStringError(t *testing.T) {
+	type payload struct {
+		Body  io.Reader
+		Ratio float64
+	}
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	v := payload{strings.NewReader(strings.Repeat("x", 100<<10)), math.NaN()}
+	var uve *UnsupportedValueError
+	if err := enc.Encode(v); !errors.As(err, &uve) {
+		t.Fatalf("Encode error: got %v, want *UnsupportedValueError", err)
+	}
+	if buf.Len() == 0 {
+		t.Fatalf("Encode wrote nothing before the error, want part of the value streamed")
+	}
+	n := buf.Len()
+	if err := enc.Encode(1); !errors.As(err, &uve) {
+		t.Errorf("Encode after partial output: got %v, want the earlier error", err)
+	}
+	if buf.Len() != n {
+		t.Errorf("Encode after partial output wrote %q", buf.Bytes()[n:])
+	}
+
+	// An error before anything is written leaves the Encoder usable.
+	buf.Reset()
+	enc = NewEncoder(&buf)
+	if err := enc.Encode(payload{strings.NewReader("x"), math.NaN()}); !errors.As(err, &uve) {
+		t.Fatalf("Encode error: got %v, want *UnsupportedValueError", err)
+	}
+	if err := enc.Encode(1); err != nil || buf.String() != "1\n" {
+		t.Errorf("Encode after error = %v, wrote %q, want nil, %q", err, buf.String(), "1\n")
+	}
+}
@@ -6,21 +6,57 @@ import (
 )
 
 // checkStructField checks:
-// - optional and nullable tags are not used with omitempty tag
+// - optional and nullable tags are not used with omitempty, omitdeepempty, or omitnil tags
 // - optional and nullable fields have enough indirection to represent optional and nullable values
+// - enum tags are only used on string fields
+// - string tags are only used on fields they apply to
+// - Null and Maybe fields do not have the nullable and optional tags they imply
 func checkStructField(structType reflect.Type, f *field) error {
+	if f.stringOpt && !f.quoted {
+		return fmt.Errorf("json: string option is ignored by field %q of type %q; it only applies to strings, floating point, integer, and boolean fields", f.name, typeByIndex(structType, f.index).String())
+	}
+	if f.enum != nil {
+		ft := typeByIndex(structType, f.index)
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.String {
+			return fmt.Errorf("json: enum field %q requires a string type, type = %q", f.name, typeByIndex(structType, f.index).String())
+		}
+	}
+
+	if null, maybe := wrapperKind(typeByIndex(structType, f.index)); null && f.nullable || maybe && f.optional {
+		opt := "nullable"
+		if maybe {
+			opt = "optional"
+		}
+		return fmt.Errorf("json: %s field %q cannot have the %s tag, which its type implies", typeByIndex(structType, f.index).Name(), f.name, opt)
+	}
+
 	requiredIndirectLevel := 0
 	if f.optional {
 		requiredIndirectLevel++
 		if f.omitEmpty {
 			return fmt.Errorf("json: field %q cannot have both omitempty and optional tags", f.name)
 		}
+		if f.omitDeepEmpty {
+			return fmt.Errorf("json: field %q cannot have both omitdeepempty and optional tags", f.name)
+		}
+		if f.omitNil {
+			return fmt.Errorf("json: field %q cannot have both omitnil and optional tags", f.name)
+		}
 	}
 	if f.nullable {
 		requiredIndirectLevel++
 		if f.omitEmpty {
 			return fmt.Errorf("json: field %q cannot have both omitempty and nullable tags", f.name)
 		}
+		if f.omitDeepEmpty {
+			return fmt.Errorf("json: field %q cannot have both omitdeepempty and nullable tags", f.name)
+		}
+		if f.omitNil {
+			return fmt.Errorf("json: field %q cannot have both omitnil and nullable tags", f.name)
+		}
 	}
 	if requiredIndirectLevel == 0 {
 		return nil // no required indirection for optional/nullable handling
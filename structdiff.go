@@ -0,0 +1,234 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A ChangeKind identifies the kind of a [Change].
+type ChangeKind byte
+
+const (
+	_ ChangeKind = iota
+
+	ChangeAdded    // a member or element only in the second document
+	ChangeRemoved  // a member or element only in the first document
+	ChangeModified // a value that differs between the documents
+)
+
+var changeKindNames = [...]string{
+	ChangeAdded:    "added",
+	ChangeRemoved:  "removed",
+	ChangeModified: "changed",
+}
+
+func (k ChangeKind) String() string {
+	if int(k) < len(changeKindNames) && changeKindNames[k] != "" {
+		return changeKindNames[k]
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Change is a difference between two JSON documents reported by
+// [StructuralDiff].
+type Change struct {
+	Kind   ChangeKind
+	Path   string     // JSON Pointer (RFC 6901) of the value
+	Before RawMessage // the value in the first document, or nil if added
+	After  RawMessage // the value in the second document, or nil if removed
+}
+
+// String returns a one-line description of the change, such as
+//
+//	changed at "/user/name": "Ann" -> "Anne"
+func (c Change) String() string {
+	s := c.Kind.String() + " at " + strconv.Quote(c.Path) + ": "
+	switch c.Kind {
+	case ChangeAdded:
+		return s + compactString(c.After)
+	case ChangeRemoved:
+		return s + compactString(c.Before)
+	}
+	return s + compactString(c.Before) + " -> " + compactString(c.After)
+}
+
+func compactString(v RawMessage) string {
+	var buf bytes.Buffer
+	if err := Compact(&buf, v); err != nil {
+		return string(v)
+	}
+	return buf.String()
+}
+
+// StructuralDiff compares the JSON documents a and b and returns their
+// differences, for change logs and for tests of the shape of a document.
+// Unlike [Diff], which returns a patch to apply, StructuralDiff reports
+// each difference with the values on both sides.
+//
+// Objects are compared member by member and arrays element by element,
+// recursively. A member or element present in only one document is
+// reported as added or removed, and any other value that differs,
+// including one that is an object in a and an array in b, as changed.
+// Strings are compared after unquoting and numbers by their exact decimal
+// value, not rounded to a float64, so differences in formatting, escaping,
+// and the order of object members are not reported, but 9007199254740993
+// and 9007199254740992 differ. Changes are listed in the order of the
+// documents, with members only in b after those in a. If an object has
+// duplicate keys, the first member with the key is compared, as by [Get].
+//
+// The values in the changes refer to a and b; they are not copied.
+func StructuralDiff(a, b []byte) ([]Change, error) {
+	if err := Validate(a); err != nil {
+		return nil, fmt.Errorf("json: invalid first document: %w", err)
+	}
+	if err := Validate(b); err != nil {
+		return nil, fmt.Errorf("json: invalid second document: %w", err)
+	}
+	var changes []Change
+	if err := structuralDiff(&changes, "", a, skipSpace(a, 0), b, skipSpace(b, 0)); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// structuralDiff appends the changes between the values at a[i] and b[j],
+// whose path is path.
+func structuralDiff(changes *[]Change, path string, a []byte, i int, b []byte, j int) error {
+	ca, cb := a[i], b[j]
+	if ca == cb && (ca == '{' || ca == '[') {
+		ea, _, err := containerEntries(a, i)
+		if err != nil {
+			return err
+		}
+		eb, _, err := containerEntries(b, j)
+		if err != nil {
+			return err
+		}
+		if ca == '{' {
+			return diffMembers(changes, path, a, ea, b, eb)
+		}
+		n := min(len(ea), len(eb))
+		for k := range n {
+			if err := structuralDiff(changes, path+"/"+strconv.Itoa(k), a, ea[k].value, b, eb[k].value); err != nil {
+				return err
+			}
+		}
+		for k := n; k < len(ea); k++ {
+			*changes = append(*changes, Change{ChangeRemoved, path + "/" + strconv.Itoa(k), entryValue(a, ea[k]), nil})
+		}
+		for k := n; k < len(eb); k++ {
+			*changes = append(*changes, Change{ChangeAdded, path + "/" + strconv.Itoa(k), nil, entryValue(b, eb[k])})
+		}
+		return nil
+	}
+	va, err := rawValueAt(a, i)
+	if err != nil {
+		return err
+	}
+	vb, err := rawValueAt(b, j)
+	if err != nil {
+		return err
+	}
+	if !equalScalars(va, vb) {
+		*changes = append(*changes, Change{ChangeModified, path, va, vb})
+	}
+	return nil
+}
+
+func diffMembers(changes *[]Change, path string, a []byte, ea []rawEntry, b []byte, eb []rawEntry) error {
+	inB := make(map[string]rawEntry, len(eb))
+	for _, e := range eb {
+		if _, dup := inB[e.key]; !dup {
+			inB[e.key] = e
+		}
+	}
+	inA := make(map[string]bool, len(ea))
+	for _, e := range ea {
+		if inA[e.key] {
+			continue
+		}
+		inA[e.key] = true
+		kpath := path + "/" + escapePointerToken(e.key)
+		f, ok := inB[e.key]
+		if !ok {
+			*changes = append(*changes, Change{ChangeRemoved, kpath, entryValue(a, e), nil})
+			continue
+		}
+		if err := structuralDiff(changes, kpath, a, e.value, b, f.value); err != nil {
+			return err
+		}
+	}
+	for _, e := range eb {
+		if !inA[e.key] {
+			inA[e.key] = true
+			*changes = append(*changes, Change{ChangeAdded, path + "/" + escapePointerToken(e.key), nil, entryValue(b, e)})
+		}
+	}
+	return nil
+}
+
+func entryValue(data []byte, e rawEntry) RawMessage {
+	return data[e.value:e.end:e.end]
+}
+
+func rawValueAt(data []byte, i int) (RawMessage, error) {
+	end, err := valueEnd(data, i)
+	if err != nil {
+		return nil, err
+	}
+	return data[i:end:end], nil
+}
+
+// equalScalars reports whether the JSON values a and b, which are not both
+// objects or both arrays, are equal.
+func equalScalars(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	switch {
+	case a[0] == '"' && b[0] == '"':
+		sa, _ := unquoteBytes(a)
+		sb, _ := unquoteBytes(b)
+		return bytes.Equal(sa, sb)
+	case isNumberStart(a[0]) && isNumberStart(b[0]):
+		negA, digitsA, expA, okA := decimalValue(a)
+		negB, digitsB, expB, okB := decimalValue(b)
+		return okA && okB && negA == negB && digitsA == digitsB && expA == expB
+	}
+	return false
+}
+
+// decimalValue returns the value of the number literal b as its sign and
+// the digits d and exponent e of d×10^e, with no leading or trailing zeros
+// in d, so that equal numbers have equal results. Zero has no digits and
+// no sign. ok is false if the exponent does not fit in an int64.
+func decimalValue(b []byte) (neg bool, d string, e int64, ok bool) {
+	s := string(b)
+	if s[0] == '-' {
+		neg, s = true, s[1:]
+	}
+	mant := s
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mant = s[:i]
+		exp, err := strconv.ParseInt(strings.TrimPrefix(s[i+1:], "+"), 10, 64)
+		if err != nil {
+			return false, "", 0, false
+		}
+		e = exp
+	}
+	if i := strings.IndexByte(mant, '.'); i >= 0 {
+		frac := mant[i+1:]
+		mant = mant[:i] + frac
+		e -= int64(len(frac))
+	}
+	mant = strings.TrimLeft(mant, "0")
+	if mant == "" {
+		return false, "", 0, true
+	}
+	trimmed := strings.TrimRight(mant, "0")
+	return neg, trimmed, e + int64(len(mant)-len(trimmed)), true
+}
+
+func isNumberStart(c byte) bool { return c == '-' || isDigit(c) }
@@ -0,0 +1,96 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructuralDiff(t *testing.T) {
+	tests := []struct {
+		CaseName
+		a, b string
+		want []string
+	}{{
+		CaseName: Name("equal"),
+		a:        `{"a": [1, "x", {"b": null}], "c": 1.0}`,
+		b:        "{\"c\":1,\n \"a\":[1e0,\"\\u0078\",{\"b\":null}]}",
+	}, {
+		CaseName: Name("scalar"),
+		a:        `1`,
+		b:        `"1"`,
+		want:     []string{`changed at "": 1 -> "1"`},
+	}, {
+		CaseName: Name("members"),
+		a:        `{"keep": 1, "gone": {"x": [1, 2]}, "same": true, "a/b": 1}`,
+		b:        `{"new": [], "keep": 2, "same": true, "a/b": 2}`,
+		want: []string{
+			`changed at "/keep": 1 -> 2`,
+			`removed at "/gone": {"x":[1,2]}`,
+			`changed at "/a~1b": 1 -> 2`,
+			`added at "/new": []`,
+		},
+	}, {
+		CaseName: Name("elements"),
+		a:        `[[1, 2, 3], [1], {}]`,
+		b:        `[[1, 5], [1, 2], []]`,
+		want: []string{
+			`changed at "/0/1": 2 -> 5`,
+			`removed at "/0/2": 3`,
+			`added at "/1/1": 2`,
+			`changed at "/2": {} -> []`,
+		},
+	}, {
+		CaseName: Name("numbers"),
+		a:        `[9007199254740993, 0, 150, 1.5e-3, 1e400]`,
+		b:        `[9007199254740992, -0.0e9, 1.50E+2, 0.0015, 10e399]`,
+		want:     []string{`changed at "/0": 9007199254740993 -> 9007199254740992`},
+	}, {
+		CaseName: Name("duplicate keys"),
+		a:        `{"a": 1, "a": 2}`,
+		b:        `{"a": 1, "b": 1, "b": 2}`,
+		want:     []string{`added at "/b": 1`},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			changes, err := StructuralDiff([]byte(tt.a), []byte(tt.b))
+			if err != nil {
+				t.Fatalf("%s: StructuralDiff error: %v", tt.Where, err)
+			}
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s: StructuralDiff:\n\tgot:  %s\n\twant: %s", tt.Where, strings.Join(got, "\n\t      "), strings.Join(tt.want, "\n\t      "))
+			}
+		})
+	}
+}
+
+func TestStructuralDiffValues(t *testing.T) {
+	a := []byte(`{"a": {"x" : 1}, "b": 2}`)
+	b := []byte("{\"a\": [\n  1\n]}")
+	changes, err := StructuralDiff(a, b)
+	if err != nil {
+		t.Fatalf("StructuralDiff error: %v", err)
+	}
+	want := []Change{
+		{ChangeModified, "/a", RawMessage(`{"x" : 1}`), RawMessage("[\n  1\n]")},
+		{ChangeRemoved, "/b", RawMessage(`2`), nil},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("StructuralDiff:\n\tgot:  %q\n\twant: %q", changes, want)
+	}
+}
+
+func TestStructuralDiffError(t *testing.T) {
+	var se *SyntaxError
+	if _, err := StructuralDiff([]byte(`{}`), []byte(`{"a":}`)); !errors.As(err, &se) || !strings.Contains(err.Error(), "second document") {
+		t.Errorf("StructuralDiff error: got %v, want *SyntaxError for the second document", err)
+	}
+	if _, err := StructuralDiff([]byte(`[1] 2`), []byte(`[]`)); !errors.As(err, &se) || !strings.Contains(err.Error(), "first document") {
+		t.Errorf("StructuralDiff error: got %v, want *SyntaxError for the first document", err)
+	}
+}
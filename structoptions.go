@@ -0,0 +1,104 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options is a marker that sets defaults for the tags of the fields of a
+// struct. Embed it in the struct, with a json tag whose name is a naming
+// style and whose options are the default options of every field:
+//
+//	type User struct {
+//		json.Options `json:"snake_case,omitempty"`
+//
+//		UserID    int       // encoded as "user_id", omitted if zero
+//		FirstName string    // encoded as "first_name", omitted if empty
+//		Created   time.Time `json:"created_at,"` // never omitted
+//	}
+//
+// The naming style names the fields whose tags do not give a name. It is one
+// of "snake_case", "kebab-case", or "camelCase", which convert a Go name such
+// as HTTPServerID to "http_server_id", "http-server-id", and "httpServerID",
+// or empty to keep Go names. The default options apply to the fields whose
+// tags have no comma; a field tagged `json:"name,"` or `json:",string"`, for
+// example, uses only the options in its own tag.
+//
+// The defaults apply only to the fields declared in the struct itself, not
+// to those promoted from embedded structs, which may have defaults of their
+// own. Options is never encoded or decoded.
+type Options struct{}
+
+var optionsType = reflect.TypeFor[Options]()
+
+// structDefaults returns the naming style and default field options set by
+// an embedded [Options] field of the struct type t, if any.
+func structDefaults(t reflect.Type) (naming string, opts tagOptions, ok bool, err error) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.Anonymous || sf.Type != optionsType {
+			continue
+		}
+		naming, opts = parseTag(sf.Tag.Get("json"))
+		switch naming {
+		case "", "snake_case", "kebab-case", "camelCase":
+		default:
+			return "", "", false, fmt.Errorf("json: unknown naming style %q in Options of type %q", naming, t.String())
+		}
+		return naming, opts, true, nil
+	}
+	return "", "", false, nil
+}
+
+// applyNaming converts the Go name of a field to the naming style.
+func applyNaming(naming, name string) string {
+	if naming == "" {
+		return name
+	}
+	words := splitWords(name)
+	switch naming {
+	case "snake_case":
+		return strings.ToLower(strings.Join(words, "_"))
+	case "kebab-case":
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+	// camelCase
+	if len(words) > 0 {
+		words[0] = strings.ToLower(words[0])
+	}
+	return strings.Join(words, "")
+}
+
+// splitWords splits a Go name into words: a word starts with an upper case
+// letter following a lower case letter or digit, or with the last of a run
+// of upper case letters followed by a lower case letter, as in HTTP|Server.
+// Underscores separate words and are dropped.
+func splitWords(name string) []string {
+	var words []string
+	start := 0
+	var prev rune
+	for i, r := range name {
+		if r == '_' {
+			if i > start {
+				words = append(words, name[start:i])
+			}
+			start, prev = i+1, r
+			continue
+		}
+		if i > start && unicode.IsUpper(r) {
+			next, _ := utf8.DecodeRuneInString(name[i+utf8.RuneLen(r):])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && unicode.IsLower(next) {
+				words = append(words, name[start:i])
+				start = i
+			}
+		}
+		prev = r
+	}
+	if start < len(name) {
+		words = append(words, name[start:])
+	}
+	return words
+}
@@ -0,0 +1,106 @@
+package json
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Name", []string{"Name"}},
+		{"UserID", []string{"User", "ID"}},
+		{"HTTPServerID", []string{"HTTP", "Server", "ID"}},
+		{"Base64Data", []string{"Base64", "Data"}},
+		{"V2", []string{"V2"}},
+		{"Already_Split", []string{"Already", "Split"}},
+		{"ÀlaCarte", []string{"Àla", "Carte"}},
+	}
+	for _, tt := range tests {
+		if got := splitWords(tt.in); !slices.Equal(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+type structOptionsInner struct {
+	Options `json:"kebab-case"`
+
+	InnerValue int
+}
+
+type structOptionsOuter struct {
+	Options `json:"snake_case,omitempty"`
+	structOptionsInner
+
+	UserID    int
+	HTTPHost  string
+	Tagged    string `json:"Custom"`
+	KeepEmpty string `json:"keep,"`
+	Quoted    int    `json:",string"`
+}
+
+func TestStructOptions(t *testing.T) {
+	tests := []struct {
+		CaseName
+		in   any
+		want string
+	}{{
+		CaseName: Name("zero"),
+		in:       structOptionsOuter{},
+		want:     `{"inner-value":0,"keep":"","quoted":"0"}`,
+	}, {
+		CaseName: Name("set"),
+		in:       structOptionsOuter{structOptionsInner: structOptionsInner{InnerValue: 1}, UserID: 2, HTTPHost: "h", Tagged: "t", KeepEmpty: "k", Quoted: 3},
+		want:     `{"inner-value":1,"user_id":2,"http_host":"h","Custom":"t","keep":"k","quoted":"3"}`,
+	}, {
+		CaseName: Name("camelCase"),
+		in: struct {
+			Options `json:"camelCase"`
+			UserID  int
+			URLPath string
+		}{},
+		want: `{"userID":0,"urlPath":""}`,
+	}, {
+		CaseName: Name("options only"),
+		in: struct {
+			Options `json:",omitempty"`
+			A, B    int
+		}{B: 1},
+		want: `{"B":1}`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			b, err := Marshal(tt.in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, b, tt.want)
+			}
+		})
+	}
+
+	var got structOptionsOuter
+	in := `{"inner-value": 1, "user_id": 2, "HTTP_HOST": "h", "Custom": "t", "keep": "k", "Quoted": "3", "Options": {}}`
+	if err := Unmarshal([]byte(in), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := structOptionsOuter{structOptionsInner: structOptionsInner{InnerValue: 1}, UserID: 2, HTTPHost: "h", Tagged: "t", KeepEmpty: "k", Quoted: 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal:\n\tgot:  %+v\n\twant: %+v", got, want)
+	}
+}
+
+func TestStructOptionsError(t *testing.T) {
+	_, err := Marshal(struct {
+		Options `json:"PascalCase"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), `unknown naming style "PascalCase"`) {
+		t.Errorf("Marshal error: got %v, want unknown naming style", err)
+	}
+}
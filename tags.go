@@ -36,3 +36,17 @@ func (o tagOptions) Contains(optionName string) bool {
 	}
 	return false
 }
+
+// Lookup returns the value of a "name=value" or "name:value" option in a
+// comma-separated list of options, and whether such an option is present.
+func (o tagOptions) Lookup(optionName string) (string, bool) {
+	s := string(o)
+	for s != "" {
+		var opt string
+		opt, s, _ = strings.Cut(s, ",")
+		if i := strings.IndexAny(opt, "=:"); i >= 0 && opt[:i] == optionName {
+			return opt[i+1:], true
+		}
+	}
+	return "", false
+}
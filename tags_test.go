@@ -26,3 +26,21 @@ func TestTagParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestTagLookup(t *testing.T) {
+	_, opts := parseTag("field,omitempty,enum=a|b,x=")
+	for _, tt := range []struct {
+		opt       string
+		wantValue string
+		wantOK    bool
+	}{
+		{"enum", "a|b", true},
+		{"x", "", true},
+		{"omitempty", "", false},
+		{"missing", "", false},
+	} {
+		if value, ok := opts.Lookup(tt.opt); value != tt.wantValue || ok != tt.wantOK {
+			t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.opt, value, ok, tt.wantValue, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package json
+
+import (
+	"io"
+	"strconv"
+)
+
+// A TokenKind identifies the kind of a token returned by [Tokenizer.Next].
+type TokenKind byte
+
+const (
+	_ TokenKind = iota
+
+	BeginObjectToken // {
+	EndObjectToken   // }
+	BeginArrayToken  // [
+	EndArrayToken    // ]
+	StringToken      // a string literal, including object keys
+	NumberToken      // a number literal
+	TrueToken        // true
+	FalseToken       // false
+	NullToken        // null
+)
+
+var tokenKindNames = [...]string{
+	BeginObjectToken: "BeginObject",
+	EndObjectToken:   "EndObject",
+	BeginArrayToken:  "BeginArray",
+	EndArrayToken:    "EndArray",
+	StringToken:      "String",
+	NumberToken:      "Number",
+	TrueToken:        "True",
+	FalseToken:       "False",
+	NullToken:        "Null",
+}
+
+func (k TokenKind) String() string {
+	if int(k) < len(tokenKindNames) && tokenKindNames[k] != "" {
+		return tokenKindNames[k]
+	}
+	return "TokenKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// A Tokenizer splits JSON text held in memory into tokens. It is a low-level
+// building block for parsers that need more control or speed than
+// [Decoder.Token]: tokens are returned as slices of the input, so
+// tokenizing does not allocate, and strings are not unquoted.
+// Commas and colons are checked but not returned as tokens.
+//
+// The input may hold a sequence of JSON values separated by white space,
+// as read by a [Decoder].
+type Tokenizer struct {
+	data []byte
+	off  int // start of unread data
+	scan scanner
+	done bool // whether no top-level value is in progress
+	err  error
+}
+
+// NewTokenizer returns a Tokenizer that reads from data.
+func NewTokenizer(data []byte) *Tokenizer {
+	t := new(Tokenizer)
+	t.Reset(data)
+	return t
+}
+
+// Reset discards the Tokenizer's state and makes it read from data,
+// reusing its internal storage.
+func (t *Tokenizer) Reset(data []byte) {
+	t.data = data
+	t.off = 0
+	t.scan.reset()
+	t.done = true
+	t.err = nil
+}
+
+// Next returns the kind of the next token and its bytes in the input.
+// For strings, the bytes include the quotes and any escape sequences;
+// use [Unmarshal] or [strconv.Unquote] to obtain the value, after checking
+// with [bytes.IndexByte] for a backslash if speed matters.
+//
+// At the end of the input, Next returns io.EOF. Syntax errors are reported
+// as a [SyntaxError], after which Next keeps returning the same error.
+func (t *Tokenizer) Next() (TokenKind, []byte, error) {
+	if t.err != nil {
+		return 0, nil, t.err
+	}
+	for ; t.off < len(t.data); t.off++ {
+		c := t.data[t.off]
+		if t.done && !isSpace(c) {
+			t.scan.reset() // start the next value in the sequence
+			t.done = false
+		}
+		t.scan.bytes++
+		switch t.scan.step(&t.scan, c) {
+		case scanError:
+			t.err = t.scan.err
+			return 0, nil, t.err
+		case scanBeginObject:
+			return t.delim(BeginObjectToken)
+		case scanEndObject:
+			return t.delim(EndObjectToken)
+		case scanBeginArray:
+			return t.delim(BeginArrayToken)
+		case scanEndArray:
+			return t.delim(EndArrayToken)
+		case scanBeginLiteral:
+			return t.literal()
+		}
+	}
+	if !t.done && t.scan.eof() == scanError {
+		t.err = t.scan.err
+		return 0, nil, t.err
+	}
+	return 0, nil, io.EOF
+}
+
+// Offset returns the input offset just past the most recently returned token.
+func (t *Tokenizer) Offset() int {
+	return t.off
+}
+
+func (t *Tokenizer) delim(k TokenKind) (TokenKind, []byte, error) {
+	t.off++
+	t.done = len(t.scan.parseState) == 0
+	return k, t.data[t.off-1 : t.off : t.off], nil
+}
+
+// literal returns the literal starting at t.data[t.off],
+// whose first byte has been stepped through the scanner.
+func (t *Tokenizer) literal() (TokenKind, []byte, error) {
+	start := t.off
+	var kind TokenKind
+	switch t.data[start] {
+	case '"':
+		kind = StringToken
+	case 't':
+		kind = TrueToken
+	case 'f':
+		kind = FalseToken
+	case 'n':
+		kind = NullToken
+	default:
+		kind = NumberToken
+	}
+	// Step through the rest of the literal. Except for numbers, its end is
+	// known without looking at the following byte, which belongs to the
+	// next token.
+	complete := false
+	escaped := false
+	for t.off++; t.off < len(t.data) && !complete; t.off++ {
+		c := t.data[t.off]
+		if kind == NumberToken && !isNumberByte(c) {
+			break
+		}
+		t.scan.bytes++
+		if t.scan.step(&t.scan, c) == scanError {
+			t.err = t.scan.err
+			return 0, nil, t.err
+		}
+		switch kind {
+		case StringToken:
+			complete = c == '"' && !escaped
+			escaped = c == '\\' && !escaped
+		case TrueToken, FalseToken, NullToken:
+			complete = t.off-start+1 == len(literalNames[kind])
+		}
+	}
+	if t.off == len(t.data) && !complete {
+		// A number may end at the end of the input, even if the
+		// enclosing value does not; anything else is truncated.
+		if kind != NumberToken || t.scan.step(&t.scan, ' ') == scanError {
+			t.err = &SyntaxError{"unexpected end of JSON input", t.scan.bytes}
+			return 0, nil, t.err
+		}
+	}
+	t.done = len(t.scan.parseState) == 0
+	return kind, t.data[start:t.off:t.off], nil
+}
+
+var literalNames = [...]string{TrueToken: "true", FalseToken: "false", NullToken: "null"}
@@ -0,0 +1,142 @@
+package json
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestTokenizer(t *testing.T) {
+	type token struct {
+		kind TokenKind
+		raw  string
+	}
+	tests := []struct {
+		CaseName
+		in     string
+		want   []token
+		errOff int64 // offset of the SyntaxError, or 0 for io.EOF
+	}{{
+		CaseName: Name("Empty"),
+		in:       " \n",
+	}, {
+		CaseName: Name("Literals"),
+		in:       `[true,false,null,-1.5e+3,0]`,
+		want: []token{
+			{BeginArrayToken, "["},
+			{TrueToken, "true"},
+			{FalseToken, "false"},
+			{NullToken, "null"},
+			{NumberToken, "-1.5e+3"},
+			{NumberToken, "0"},
+			{EndArrayToken, "]"},
+		},
+	}, {
+		CaseName: Name("Nested"),
+		in:       ` { "a" : [ {} , "x\"\\" ], "bé":{"c":1} } `,
+		want: []token{
+			{BeginObjectToken, "{"},
+			{StringToken, `"a"`},
+			{BeginArrayToken, "["},
+			{BeginObjectToken, "{"},
+			{EndObjectToken, "}"},
+			{StringToken, `"x\"\\"`},
+			{EndArrayToken, "]"},
+			{StringToken, `"bé"`},
+			{BeginObjectToken, "{"},
+			{StringToken, `"c"`},
+			{NumberToken, "1"},
+			{EndObjectToken, "}"},
+			{EndObjectToken, "}"},
+		},
+	}, {
+		CaseName: Name("Sequence"),
+		in:       "1 \"a\"{}[]\nnull 2",
+		want: []token{
+			{NumberToken, "1"},
+			{StringToken, `"a"`},
+			{BeginObjectToken, "{"},
+			{EndObjectToken, "}"},
+			{BeginArrayToken, "["},
+			{EndArrayToken, "]"},
+			{NullToken, "null"},
+			{NumberToken, "2"},
+		},
+	}, {
+		CaseName: Name("InvalidLiteral"),
+		in:       `[tru]`,
+		want:     []token{{BeginArrayToken, "["}},
+		errOff:   5,
+	}, {
+		CaseName: Name("MissingColon"),
+		in:       `{"a" 1}`,
+		want:     []token{{BeginObjectToken, "{"}, {StringToken, `"a"`}},
+		errOff:   6,
+	}, {
+		CaseName: Name("TruncatedNumber"),
+		in:       `[1.`,
+		want:     []token{{BeginArrayToken, "["}},
+		errOff:   3,
+	}, {
+		CaseName: Name("TruncatedString"),
+		in:       `"abc`,
+		errOff:   4,
+	}, {
+		CaseName: Name("Unclosed"),
+		in:       `[1`,
+		want:     []token{{BeginArrayToken, "["}, {NumberToken, "1"}},
+		errOff:   2,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tk := NewTokenizer([]byte(tt.in))
+			var got []token
+			var err error
+			for {
+				var kind TokenKind
+				var raw []byte
+				if kind, raw, err = tk.Next(); err != nil {
+					break
+				}
+				got = append(got, token{kind, string(raw)})
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("%s: tokens:\n\tgot:  %v\n\twant: %v", tt.Where, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("%s: tokens:\n\tgot:  %v\n\twant: %v", tt.Where, got, tt.want)
+				}
+			}
+			if tt.errOff == 0 {
+				if err != io.EOF {
+					t.Fatalf("%s: Next error: %v, want io.EOF", tt.Where, err)
+				}
+				return
+			}
+			var serr *SyntaxError
+			if !errors.As(err, &serr) || serr.Offset != tt.errOff {
+				t.Fatalf("%s: Next error: %v, want SyntaxError at offset %d", tt.Where, err, tt.errOff)
+			}
+			if _, _, err2 := tk.Next(); err2 != err {
+				t.Errorf("%s: Next after error: %v, want %v", tt.Where, err2, err)
+			}
+		})
+	}
+}
+
+func TestTokenizerAllocs(t *testing.T) {
+	data := []byte(`{"a":[1,true,null,"x\ny"],"b":{"c":-2.5}}`)
+	tk := NewTokenizer(data)
+	allocs := testing.AllocsPerRun(100, func() {
+		tk.Reset(data)
+		for {
+			if _, _, err := tk.Next(); err != nil {
+				break
+			}
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("Next allocated %v times, want 0", allocs)
+	}
+}
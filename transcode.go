@@ -0,0 +1,39 @@
+package json
+
+import "io"
+
+// A TokenReader is a source of JSON tokens, such as a [Decoder], or an
+// adapter reading another format with the same data model.
+// At the end of its input, Token returns nil, [io.EOF].
+type TokenReader interface {
+	Token() (Token, error)
+}
+
+// A TokenWriter is a destination of JSON tokens, such as an [Encoder], or
+// an adapter writing another format with the same data model.
+type TokenWriter interface {
+	WriteToken(Token) error
+}
+
+// Transcode copies the tokens of src to dst until src reaches the end of its
+// input, so that a stream of values is converted from one format to another
+// without being decoded into Go values. To convert Go values, encode them
+// with [Marshal] and transcode from a [Decoder], so that their struct tags
+// decide the result.
+//
+// A Decoder returns numbers as float64 unless [Decoder.UseNumber] is set,
+// which keeps large integers exact.
+func Transcode(dst TokenWriter, src TokenReader) error {
+	for {
+		t, err := src.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := dst.WriteToken(t); err != nil {
+			return err
+		}
+	}
+}
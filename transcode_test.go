@@ -0,0 +1,88 @@
+package json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncoderWriteToken(t *testing.T) {
+	tests := []struct {
+		CaseName
+		tokens  []Token
+		want    string
+		wantErr string
+	}{{
+		CaseName: Name("scalars"),
+		tokens:   []Token{nil, true, 1.5, Number("12345678901234567890"), "<a>"},
+		want:     "null\ntrue\n1.5\n12345678901234567890\n\"\\u003ca\\u003e\"\n",
+	}, {
+		CaseName: Name("nested"),
+		tokens: []Token{
+			Delim('{'), "a", Delim('['), 1.0, Delim('{'), Delim('}'), Delim('['), Delim(']'), Delim(']'),
+			"b", "c", Delim('}'),
+			Delim('['), Delim(']'),
+		},
+		want: "{\"a\":[1,{},[]],\"b\":\"c\"}\n[]\n",
+	}, {
+		CaseName: Name("non-token value"),
+		tokens:   []Token{Delim('['), struct{ X int }{1}, map[string]int{"y": 2}, Delim(']')},
+		want:     "[{\"X\":1},{\"y\":2}]\n",
+	}, {
+		CaseName: Name("non-string key"),
+		tokens:   []Token{Delim('{'), 1.0},
+		want:     "{",
+		wantErr:  "json: object key token must be a string, got float64",
+	}, {
+		CaseName: Name("mismatched delimiter"),
+		tokens:   []Token{Delim('['), Delim('}')},
+		want:     "[",
+		wantErr:  "json: unexpected } token",
+	}, {
+		CaseName: Name("missing object value"),
+		tokens:   []Token{Delim('{'), "a", Delim('}')},
+		want:     "{\"a\":",
+		wantErr:  "json: unexpected } token",
+	}, {
+		CaseName: Name("top-level closer"),
+		tokens:   []Token{Delim(']')},
+		wantErr:  "json: unexpected ] token",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var buf strings.Builder
+			enc := NewEncoder(&buf)
+			var err error
+			for _, tok := range tt.tokens {
+				if err = enc.WriteToken(tok); err != nil {
+					break
+				}
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("%s: WriteToken:\n\tgot:  %q\n\twant: %q", tt.Where, got, tt.want)
+			}
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("%s: WriteToken error: got %v, want %v", tt.Where, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranscode(t *testing.T) {
+	in := `{"a": [1, 12345678901234567890, {"b": null}], "c": "é"} [true] "x"`
+	dec := NewDecoder(strings.NewReader(in))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := Transcode(NewEncoder(&buf), dec); err != nil {
+		t.Fatalf("Transcode error: %v", err)
+	}
+	want := "{\"a\":[1,12345678901234567890,{\"b\":null}],\"c\":\"é\"}\n[true]\n\"x\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Transcode:\n\tgot:  %q\n\twant: %q", got, want)
+	}
+
+	dec = NewDecoder(strings.NewReader(`[1, }`))
+	if err := Transcode(NewEncoder(&buf), dec); err == nil {
+		t.Error("Transcode error: got nil, want error for invalid input")
+	}
+}
@@ -0,0 +1,171 @@
+package json
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Warmup compiles the encoders and struct field lists of the types of the
+// given values, and of the types they contain, so that the first [Marshal]
+// or [Unmarshal] of a value of one of those types does not pay for it.
+// It is meant to be called at startup, with a value of each type that will
+// be encoded or decoded, such as Warmup(Request{}, (*Response)(nil)).
+// A [reflect.Type] stands for itself rather than for its own type.
+//
+// Warmup is safe to call concurrently with other operations of the package.
+func Warmup(values ...any) {
+	for _, v := range values {
+		t, ok := v.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(v)
+		}
+		if t == nil {
+			continue
+		}
+		// Encoding a *T compiles the encoder of T, and with it the fields
+		// that decoding a T looks up.
+		typeEncoder(reflect.PointerTo(t))
+	}
+}
+
+// SetTypeCacheLimit bounds the number of types for which compiled encoders
+// and struct field lists are kept, and returns the previous bound. Past the
+// bound, the types least recently encoded or decoded are forgotten, to be
+// compiled again when they are next used. A bound of zero or less, the
+// default, keeps every type.
+//
+// The caches otherwise grow with every type encoded or decoded, which
+// matters for programs that create many types at run time, such as with
+// [reflect.StructOf]. A bound slightly slows every lookup in the caches,
+// and one smaller than the number of types in regular use makes them be
+// compiled repeatedly.
+func SetTypeCacheLimit(n int) int {
+	if n < 0 {
+		n = 0
+	}
+	prev := int(typeCacheLimit.Swap(int64(n)))
+	if n > 0 {
+		for _, c := range []*typeCache{&encoderCache, &fieldCache, &sizerCache} {
+			c.evict()
+		}
+	}
+	return prev
+}
+
+var (
+	typeCacheLimit atomic.Int64 // SetTypeCacheLimit bound, or 0
+	typeCacheClock atomic.Int64 // ticks once for each lookup in a bounded cache
+)
+
+// A typeCache is a map from types to what is compiled for them, such as
+// their encoderFunc, enforcing the bound set by SetTypeCacheLimit.
+// The zero value is an empty cache.
+type typeCache struct {
+	m        sync.Map     // map[reflect.Type]*typeCacheEntry
+	n        atomic.Int64 // number of entries in m
+	evicting sync.Mutex
+}
+
+type typeCacheEntry struct {
+	v       any
+	pending bool         // v stands in for a value being compiled
+	used    atomic.Int64 // typeCacheClock as of the last lookup
+}
+
+// Load returns the value cached for t, marking it as recently used.
+func (c *typeCache) Load(t reflect.Type) (any, bool) {
+	e, ok := c.m.Load(t)
+	if !ok {
+		return nil, false
+	}
+	ce := e.(*typeCacheEntry)
+	if typeCacheLimit.Load() > 0 && !ce.pending {
+		ce.used.Store(typeCacheClock.Add(1))
+	}
+	return ce.v, true
+}
+
+// LoadOrStore returns the value cached for t, if any, and otherwise caches
+// and returns v. It reports whether the value was already cached.
+func (c *typeCache) LoadOrStore(t reflect.Type, v any) (any, bool) {
+	return c.loadOrStore(t, newTypeCacheEntry(v, false))
+}
+
+// LoadOrStorePending is like LoadOrStore but caches v only until it is
+// replaced by Store, with the value that v stands in for while it is
+// compiled. Until then, v is not evicted: that would let a recursive type
+// be compiled endlessly.
+func (c *typeCache) LoadOrStorePending(t reflect.Type, v any) (any, bool) {
+	return c.loadOrStore(t, newTypeCacheEntry(v, true))
+}
+
+func (c *typeCache) loadOrStore(t reflect.Type, ce *typeCacheEntry) (any, bool) {
+	e, loaded := c.m.LoadOrStore(t, ce)
+	if !loaded {
+		c.added()
+	}
+	return e.(*typeCacheEntry).v, loaded
+}
+
+// Store caches v for t, replacing any value cached for it.
+func (c *typeCache) Store(t reflect.Type, v any) {
+	if _, loaded := c.m.Swap(t, newTypeCacheEntry(v, false)); !loaded {
+		c.added()
+	}
+}
+
+func newTypeCacheEntry(v any, pending bool) *typeCacheEntry {
+	ce := &typeCacheEntry{v: v, pending: pending}
+	ce.used.Store(typeCacheClock.Add(1))
+	return ce
+}
+
+// added counts a new entry, evicting entries past the bound.
+func (c *typeCache) added() {
+	if n := c.n.Add(1); n > typeCacheLimit.Load() && typeCacheLimit.Load() > 0 {
+		c.evict()
+	}
+}
+
+// evict removes the least recently used entries until the cache is within
+// the bound. Finding each one takes time linear in the size of the cache,
+// but only entries beyond the bound are found.
+func (c *typeCache) evict() {
+	c.evicting.Lock()
+	defer c.evicting.Unlock()
+	for limit := typeCacheLimit.Load(); limit > 0 && c.n.Load() > limit; {
+		var (
+			oldest      any
+			oldestEntry *typeCacheEntry
+		)
+		c.m.Range(func(t, e any) bool {
+			ce := e.(*typeCacheEntry)
+			if !ce.pending && (oldestEntry == nil || ce.used.Load() < oldestEntry.used.Load()) {
+				oldest, oldestEntry = t, ce
+			}
+			return true
+		})
+		if oldestEntry == nil {
+			return
+		}
+		// The entry is kept if it has been replaced in the meantime.
+		if c.m.CompareAndDelete(oldest, oldestEntry) {
+			c.n.Add(-1)
+		}
+	}
+}
+
+// len returns the number of types in the cache.
+func (c *typeCache) len() int {
+	return int(c.n.Load())
+}
+
+// clear empties the cache. Other operations must not be running.
+func (c *typeCache) clear() {
+	c.m.Range(func(t, _ any) bool {
+		c.m.Delete(t)
+		return true
+	})
+	c.n.Store(0)
+}
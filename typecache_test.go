@@ -0,0 +1,71 @@
+package json
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// newCacheTestType returns a struct type that has not been used before.
+func newCacheTestType(name string) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{{
+		Name: name,
+		Type: reflect.TypeFor[int](),
+		Tag:  `json:"v"`,
+	}})
+}
+
+func TestWarmup(t *testing.T) {
+	t1 := newCacheTestType("Warmup1")
+	t2 := newCacheTestType("Warmup2")
+	Warmup(reflect.New(t1).Elem().Interface(), t2, nil)
+	for _, typ := range []reflect.Type{t1, t2} {
+		if _, ok := encoderCache.Load(typ); !ok {
+			t.Errorf("Warmup(%v): encoder not cached", typ)
+		}
+		if _, ok := fieldCache.Load(typ); !ok {
+			t.Errorf("Warmup(%v): fields not cached", typ)
+		}
+	}
+}
+
+type cacheTestList struct {
+	V    int
+	Next *cacheTestList
+}
+
+func TestTypeCacheLimit(t *testing.T) {
+	defer SetTypeCacheLimit(SetTypeCacheLimit(8))
+
+	hot := newCacheTestType("Hot")
+	for i := range 50 {
+		typ := newCacheTestType(fmt.Sprintf("Cold%d", i))
+		v := reflect.New(typ)
+		v.Elem().Field(0).SetInt(int64(i))
+		b, err := Marshal(v.Interface())
+		if want := fmt.Sprintf(`{"v":%d}`, i); err != nil || string(b) != want {
+			t.Fatalf("Marshal = %s, %v, want %s", b, err, want)
+		}
+		if err := Unmarshal(b, v.Interface()); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if _, err := Marshal(reflect.New(hot).Interface()); err != nil {
+			t.Fatalf("Marshal error: %v", err)
+		}
+	}
+	for _, c := range []*typeCache{&encoderCache, &fieldCache} {
+		if n := c.len(); n > 8 {
+			t.Errorf("cache has %d types, want at most 8", n)
+		}
+	}
+	if _, ok := encoderCache.Load(reflect.PointerTo(hot)); !ok {
+		t.Error("recently used type was evicted")
+	}
+
+	// Recursive types are compiled once, however small the bound.
+	SetTypeCacheLimit(1)
+	b, err := Marshal(&cacheTestList{1, &cacheTestList{V: 2}})
+	if want := `{"V":1,"Next":{"V":2,"Next":null}}`; err != nil || string(b) != want {
+		t.Errorf("Marshal = %s, %v, want %s", b, err, want)
+	}
+}
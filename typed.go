@@ -0,0 +1,172 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var typeRegistry struct {
+	sync.RWMutex
+	byName map[string]reflect.Type
+	byType map[reflect.Type]string
+}
+
+// RegisterType records the concrete type of value under name, so that
+// values of that type stored in interface values can be encoded and decoded
+// with [MarshalOptions.TypedInterfaces] and [UnmarshalOptions.TypedInterfaces].
+// Values of pointer and non-pointer types are distinct: register &T{} to
+// decode interface values that hold a *T.
+//
+// RegisterType is meant to be called from init functions.
+// It panics if name or the type is already registered with a different
+// counterpart, or if value is nil.
+func RegisterType(name string, value any) {
+	if value == nil {
+		panic("json: RegisterType of nil value")
+	}
+	t := reflect.TypeOf(value)
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	if prev, ok := typeRegistry.byName[name]; ok && prev != t {
+		panic(fmt.Sprintf("json: registering duplicate types for %q: %s != %s", name, prev, t))
+	}
+	if prev, ok := typeRegistry.byType[t]; ok && prev != name {
+		panic(fmt.Sprintf("json: registering duplicate names for %s: %q != %q", t, prev, name))
+	}
+	if typeRegistry.byName == nil {
+		typeRegistry.byName = make(map[string]reflect.Type)
+		typeRegistry.byType = make(map[reflect.Type]string)
+	}
+	typeRegistry.byName[name] = t
+	typeRegistry.byType[t] = name
+}
+
+func registeredName(t reflect.Type) (string, bool) {
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+	name, ok := typeRegistry.byType[t]
+	return name, ok
+}
+
+func registeredType(name string) (reflect.Type, bool) {
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+	t, ok := typeRegistry.byName[name]
+	return t, ok
+}
+
+// typedInterfaceEncoder encodes the non-nil interface value v as an object
+// naming the registered type of its dynamic value. Values of unregistered
+// types in empty interfaces are encoded as usual.
+func typedInterfaceEncoder(e *encodeState, v reflect.Value, opts encOpts) {
+	elem := v.Elem()
+	name, ok := registeredName(elem.Type())
+	if !ok {
+		if v.NumMethod() == 0 {
+			// Decodes back into an empty interface as usual.
+			e.reflectValue(elem, opts)
+			return
+		}
+		e.error(&SemanticError{Type: elem.Type(), Err: ErrUnregisteredType, msg: fmt.Sprintf("json: type %s stored in %s is not registered", elem.Type(), v.Type())})
+	}
+	e.WriteString(`{"$type":`)
+	e.Write(appendString(e.AvailableBuffer(), name, opts.escapeHTML))
+	e.WriteString(`,"value":`)
+	e.reflectValue(elem, opts)
+	e.WriteByte('}')
+}
+
+// typedInterface decodes the object starting at d.data[d.readIndex()] into
+// the interface value v if the object has a "$type" member, and reports
+// whether it did. Otherwise, the object is left for the caller to decode.
+func (d *decodeState) typedInterface(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Interface || !v.CanSet() {
+		return false
+	}
+	start := d.readIndex()
+	raw, err := Get(d.data[start:], "$type")
+	if err != nil {
+		return false
+	}
+	d.skip()
+	obj := d.data[start:d.off]
+
+	var name string
+	if err := Unmarshal(raw, &name); err != nil {
+		d.saveError(newSemanticError(start, v.Type(), err, "json: invalid $type %s", raw))
+		return true
+	}
+	t, ok := registeredType(name)
+	if !ok {
+		d.saveError(newSemanticError(start, v.Type(), ErrUnregisteredType, "json: type name %q is not registered", name))
+		return true
+	}
+	if !t.AssignableTo(v.Type()) {
+		d.saveError(newSemanticError(start, v.Type(), errors.New("registered type not assignable"), "json: registered type %s for %q cannot be stored in %s", t, name, v.Type()))
+		return true
+	}
+	value, err := Get(obj, "value")
+	if err != nil {
+		d.saveError(newSemanticError(start, v.Type(), err, "json: object with $type %q has no value member", name))
+		return true
+	}
+
+	off, _ := findPath(obj, []any{"value"})
+	p := d.decodeElsewhere(value, start+off, t)
+	v.Set(p.Elem())
+	return true
+}
+
+// decodeElsewhere decodes data, which is found at offset off in d.data,
+// into a new value of type t, with the settings of d but without disturbing
+// the value being decoded, and returns a pointer to the new value.
+func (d *decodeState) decodeElsewhere(data []byte, off int, t reflect.Type) reflect.Value {
+	sub := *d
+	sub.scan = scanner{}
+	sub.errorContext = nil
+	sub.fieldMask = d.mask
+	sub.init(data)
+	p := reflect.New(t)
+	var warned, missing int
+	if d.warnings != nil {
+		warned = len(*d.warnings)
+	}
+	if d.missingFields != nil {
+		missing = len(*d.missingFields)
+	}
+	err := sub.unmarshal(p.Interface())
+	// Make the offsets of errors, warnings, and missing fields relative to d.data.
+	base := int64(off)
+	if d.warnings != nil {
+		for i := range (*d.warnings)[warned:] {
+			(*d.warnings)[warned+i].Offset += base
+		}
+	}
+	if d.missingFields != nil {
+		for i := range (*d.missingFields)[missing:] {
+			(*d.missingFields)[missing+i].Offset += base
+		}
+	}
+	if err != nil {
+		switch err := err.(type) {
+		case *UnmarshalTypeError:
+			err.Offset += base
+		case *SemanticError:
+			err.Offset += base
+		}
+		d.saveError(err)
+	}
+	return p
+}
+
+// typedInterfaceValue is like typedInterface but returns the decoded value.
+func (d *decodeState) typedInterfaceValue() (any, bool) {
+	var x any
+	ok := d.typedInterface(reflect.ValueOf(&x).Elem())
+	return x, ok
+}
@@ -0,0 +1,137 @@
+package json
+
+import (
+	"reflect"
+	"testing"
+)
+
+type typedShape interface{ Area() float64 }
+
+type typedSquare struct{ Side float64 }
+
+func (s typedSquare) Area() float64 { return s.Side * s.Side }
+
+type typedCircle struct{ R float64 }
+
+func (c *typedCircle) Area() float64 { return 3 * c.R * c.R }
+
+type typedUnregistered struct{}
+
+func (typedUnregistered) Area() float64 { return 0 }
+
+func init() {
+	RegisterType("square", typedSquare{})
+	RegisterType("circle", &typedCircle{})
+}
+
+type typedEvent struct {
+	Shape   typedShape
+	Shapes  []typedShape
+	Payload any
+	None    any
+}
+
+func TestTypedInterfacesRoundTrip(t *testing.T) {
+	in := typedEvent{
+		Shape:   typedSquare{2},
+		Shapes:  []typedShape{&typedCircle{1}, typedSquare{3}},
+		Payload: map[string]any{"s": typedSquare{4}},
+	}
+	b, err := (MarshalOptions{TypedInterfaces: true}).Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	const want = `{"Shape":{"$type":"square","value":{"Side":2}},` +
+		`"Shapes":[{"$type":"circle","value":{"R":1}},{"$type":"square","value":{"Side":3}}],` +
+		`"Payload":{"s":{"$type":"square","value":{"Side":4}}},"None":null}`
+	if string(b) != want {
+		t.Errorf("Marshal:\n\tgot:  %s\n\twant: %s", b, want)
+	}
+
+	var out typedEvent
+	if err := (UnmarshalOptions{TypedInterfaces: true}).Unmarshal(b, &out); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("Unmarshal:\n\tgot:  %#v\n\twant: %#v", out, in)
+	}
+}
+
+func TestTypedInterfacesTopLevel(t *testing.T) {
+	var s typedShape
+	if err := (UnmarshalOptions{TypedInterfaces: true}).Unmarshal([]byte(`{"value":{"R":2},"$type":"circle"}`), &s); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if !reflect.DeepEqual(s, &typedCircle{2}) {
+		t.Errorf("Unmarshal = %#v, want %#v", s, &typedCircle{2})
+	}
+
+	// Without the option, the envelope is an ordinary object.
+	var v any
+	if err := Unmarshal([]byte(`{"$type":"circle","value":1}`), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if want := map[string]any{"$type": "circle", "value": 1.0}; !reflect.DeepEqual(v, want) {
+		t.Errorf("Unmarshal = %#v, want %#v", v, want)
+	}
+}
+
+func TestTypedInterfacesErrors(t *testing.T) {
+	_, err := (MarshalOptions{TypedInterfaces: true}).Marshal(typedEvent{Shape: typedUnregistered{}})
+	if want := "json: type json.typedUnregistered stored in json.typedShape is not registered"; errString(err) != want {
+		t.Errorf("Marshal error: %v, want unregistered type error", err)
+	}
+
+	tests := []struct {
+		CaseName
+		in      string
+		wantErr string
+	}{
+		{Name("UnknownName"), `{"Shape":{"$type":"triangle","value":{}}}`, `json: type name "triangle" is not registered`},
+		{Name("NotAssignable"), `{"Shape":{"$type":"circle","value":{}},"Shapes":[{"$type":"square","value":{"Side":1}}]}`, ""},
+		{Name("MissingValue"), `{"Shape":{"$type":"square"}}`, `json: object with $type "square" has no value member`},
+		{Name("BadName"), `{"Shape":{"$type":1,"value":{}}}`, `json: invalid $type 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			var out typedEvent
+			err := (UnmarshalOptions{TypedInterfaces: true}).Unmarshal([]byte(tt.in), &out)
+			if got := errString(err); got != tt.wantErr {
+				t.Errorf("%s: Unmarshal error:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.wantErr)
+			}
+		})
+	}
+
+	var sq struct {
+		S interface{ Perimeter() float64 }
+	}
+	err = (UnmarshalOptions{TypedInterfaces: true}).Unmarshal([]byte(`{"S":{"$type":"square","value":{}}}`), &sq)
+	if want := `json: registered type json.typedSquare for "square" cannot be stored in interface { Perimeter() float64 }`; errString(err) != want {
+		t.Errorf("Unmarshal error:\n\tgot:  %v\n\twant: %s", err, want)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestRegisterTypeConflicts(t *testing.T) {
+	RegisterType("square", typedSquare{}) // same registration again is fine
+	for _, f := range []func(){
+		func() { RegisterType("square", typedCircle{}) },
+		func() { RegisterType("square2", typedSquare{}) },
+		func() { RegisterType("nil", nil) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterType did not panic")
+				}
+			}()
+			f()
+		}()
+	}
+}
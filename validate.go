@@ -0,0 +1,278 @@
+package json
+
+import "strconv"
+
+// ValidateOptions configures [ValidateOptions.Validate].
+// The zero value validates exactly like [Validate].
+type ValidateOptions struct {
+	// MaxDepth, if positive, is the number of levels of objects and arrays
+	// that may be nested. The limit is never more than 10000, the depth
+	// beyond which [Unmarshal] reports an error as well.
+	MaxDepth int
+
+	// MaxLength, if positive, is the length in bytes of the longest input
+	// that is accepted. Longer input is rejected without being read.
+	MaxLength int
+}
+
+// Validate reports whether data is a valid JSON encoding, returning nil or
+// a [SyntaxError] describing the first error, as [Unmarshal] reports it.
+//
+// Validate is equivalent to ValidateOptions{}.Validate(data).
+func Validate(data []byte) error {
+	return ValidateOptions{}.Validate(data)
+}
+
+// Valid is like [ValidateOptions.Validate] but only reports whether data is
+// valid.
+func (o ValidateOptions) Valid(data []byte) bool {
+	return o.Validate(data) == nil
+}
+
+// Validate is like the package-level [Validate] but enforces the limits
+// of o.
+//
+// Validate is meant to be called directly on untrusted input: it runs in
+// time linear in the length of data, keeps the state of every level of
+// nesting in a fixed-size array rather than on the heap, and allocates
+// nothing for valid input. Invalid input costs a constant number of
+// allocations for the returned error.
+func (o ValidateOptions) Validate(data []byte) error {
+	if o.MaxLength > 0 && len(data) > o.MaxLength {
+		return &SyntaxError{"input exceeded max length of " + strconv.Itoa(o.MaxLength) + " bytes", int64(o.MaxLength)}
+	}
+	maxDepth := maxNestingDepth
+	if o.MaxDepth > 0 && o.MaxDepth < maxDepth {
+		maxDepth = o.MaxDepth
+	}
+	return validate(data, maxDepth)
+}
+
+// validate checks the syntax of data as checkValid does, with the same
+// errors, but without a scanner: the nesting is kept as one bit per level,
+// recording whether it is an object, so validate allocates only for an
+// error.
+//
+// As the scanner is told of the end of the input by a trailing space,
+// errors at the end of data are reported for the character ' '.
+func validate(data []byte, maxDepth int) error {
+	var objects [maxNestingDepth/64 + 1]uint64
+	depth := 0
+	i := 0
+	var err error
+	for {
+		// Read a value, or the start of an object or array.
+		i = skipSpace(data, i)
+		if i == len(data) {
+			return errEOF(data)
+		}
+		switch c := data[i]; {
+		case c == '{' || c == '[':
+			if depth == maxDepth {
+				return errorAt(data, i, "exceeded max depth")
+			}
+			if c == '{' {
+				objects[depth/64] |= 1 << (depth % 64)
+			} else {
+				objects[depth/64] &^= 1 << (depth % 64)
+			}
+			depth++
+			i = skipSpace(data, i+1)
+			if i == len(data) {
+				return errEOF(data)
+			}
+			if data[i] == c+2 { // '}' or ']'
+				depth--
+				i++
+				break
+			}
+			if c == '{' {
+				if i, err = validKey(data, i); err != nil {
+					return err
+				}
+			}
+			continue
+		case c == '"':
+			i, err = validString(data, i)
+		case c == '-' || '0' <= c && c <= '9':
+			i, err = validNumber(data, i)
+		case c == 't':
+			i, err = validLiteral(data, i, "true")
+		case c == 'f':
+			i, err = validLiteral(data, i, "false")
+		case c == 'n':
+			i, err = validLiteral(data, i, "null")
+		default:
+			return errorAt(data, i, "looking for beginning of value")
+		}
+		if err != nil {
+			return err
+		}
+
+		// Close the objects and arrays that end after the value.
+		for {
+			i = skipSpace(data, i)
+			if depth == 0 {
+				if i < len(data) {
+					return errorAt(data, i, "after top-level value")
+				}
+				return nil
+			}
+			if i == len(data) {
+				return errEOF(data)
+			}
+			inObject := objects[(depth-1)/64]&(1<<((depth-1)%64)) != 0
+			c := data[i]
+			if c == ',' {
+				i = skipSpace(data, i+1)
+				if inObject {
+					if i == len(data) {
+						return errEOF(data)
+					}
+					if i, err = validKey(data, i); err != nil {
+						return err
+					}
+				}
+				break
+			}
+			switch {
+			case inObject && c == '}', !inObject && c == ']':
+				depth--
+				i++
+				continue
+			case inObject:
+				return errorAt(data, i, "after object key:value pair")
+			default:
+				return errorAt(data, i, "after array element")
+			}
+		}
+	}
+}
+
+// validKey checks the object key starting at data[i] and the colon after it,
+// returning the offset after the colon.
+func validKey(data []byte, i int) (int, error) {
+	if data[i] != '"' {
+		return i, errorAt(data, i, "looking for beginning of object key string")
+	}
+	i, err := validString(data, i)
+	if err != nil {
+		return i, err
+	}
+	i = skipSpace(data, i)
+	if i == len(data) {
+		return i, errEOF(data)
+	}
+	if data[i] != ':' {
+		return i, errorAt(data, i, "after object key")
+	}
+	return i + 1, nil
+}
+
+// validString checks the string starting at data[i], returning the offset
+// after it.
+func validString(data []byte, i int) (int, error) {
+	for i++; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\':
+			i++
+			switch byteAt(data, i) {
+			case 'b', 'f', 'n', 'r', 't', '\\', '/', '"':
+			case 'u':
+				for range 4 {
+					i++
+					if !isHex(byteAt(data, i)) {
+						return i, errorAt(data, i, "in \\u hexadecimal character escape")
+					}
+				}
+			default:
+				return i, errorAt(data, i, "in string escape code")
+			}
+		case c < 0x20:
+			return i, errorAt(data, i, "in string literal")
+		}
+	}
+	return i, errEOF(data)
+}
+
+// validNumber checks the number starting at data[i], returning the offset
+// after it.
+func validNumber(data []byte, i int) (int, error) {
+	if data[i] == '-' {
+		i++
+	}
+	switch c := byteAt(data, i); {
+	case c == '0':
+		i++
+	case '1' <= c && c <= '9':
+		i = skipDigits(data, i+1)
+	default:
+		return i, errorAt(data, i, "in numeric literal")
+	}
+	if byteAt(data, i) == '.' {
+		i++
+		if !isDigit(byteAt(data, i)) {
+			return i, errorAt(data, i, "after decimal point in numeric literal")
+		}
+		i = skipDigits(data, i)
+	}
+	if c := byteAt(data, i); c == 'e' || c == 'E' {
+		i++
+		if c := byteAt(data, i); c == '+' || c == '-' {
+			i++
+		}
+		if !isDigit(byteAt(data, i)) {
+			return i, errorAt(data, i, "in exponent of numeric literal")
+		}
+		i = skipDigits(data, i)
+	}
+	return i, nil
+}
+
+// validLiteral checks that the literal lit starts at data[i], returning the
+// offset after it.
+func validLiteral(data []byte, i int, lit string) (int, error) {
+	for j := 1; j < len(lit); j++ {
+		if byteAt(data, i+j) != lit[j] {
+			return i + j, errorAt(data, i+j, "in literal "+lit+" (expecting "+quoteChar(lit[j])+")")
+		}
+	}
+	return i + len(lit), nil
+}
+
+// byteAt returns data[i], or a space at the end of data.
+func byteAt(data []byte, i int) byte {
+	if i < len(data) {
+		return data[i]
+	}
+	return ' '
+}
+
+func skipDigits(data []byte, i int) int {
+	for i < len(data) && isDigit(data[i]) {
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// errorAt returns the error the scanner reports for the character at
+// data[i], or for the space it is given at the end of data.
+func errorAt(data []byte, i int, context string) error {
+	off := len(data)
+	if i < len(data) {
+		off = i + 1
+	}
+	return &SyntaxError{"invalid character " + quoteChar(byteAt(data, i)) + " " + context, int64(off)}
+}
+
+func errEOF(data []byte) error {
+	return &SyntaxError{"unexpected end of JSON input", int64(len(data))}
+}
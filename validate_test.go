@@ -0,0 +1,152 @@
+package json
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var validateTests = []string{
+	``,
+	` `,
+	`null`,
+	` true `,
+	`false`,
+	`0`,
+	`-12.5e+3`,
+	`1E-0`,
+	`"a\"\\\/\b\f\n\r\té😀"`,
+	`{}`,
+	`[]`,
+	` { "a" : [ 1 , { "b" : null } ] , "c" : "" } `,
+	`[[[[]]]]`,
+	"\"\xff\"",
+
+	`{`,
+	`[`,
+	`[1`,
+	`[1,`,
+	`{"a"`,
+	`{"a":`,
+	`{"a":1`,
+	`{"a":1,`,
+	`"abc`,
+	`"\`,
+	`"\u12`,
+	`"\x"`,
+	`"\u12g4"`,
+	"\"\n\"",
+	`-`,
+	`-a`,
+	`01`,
+	`1.`,
+	`1.e1`,
+	`1e`,
+	`1e+`,
+	`1ex`,
+	`t`,
+	`tr`,
+	`trux`,
+	`nul`,
+	`fals`,
+	`truex`,
+	`1 2`,
+	`[1 2]`,
+	`[1,]`,
+	`[,1]`,
+	`{,}`,
+	`{"a" 1}`,
+	`{"a":1 "b":2}`,
+	`{"a":1,}`,
+	`{1:2}`,
+	`[}`,
+	`{]`,
+	`{"a":[}`,
+	`]`,
+	`'a'`,
+	"\x00",
+	strings.Repeat("[", maxNestingDepth) + strings.Repeat("]", maxNestingDepth),
+	strings.Repeat("[", maxNestingDepth+1) + strings.Repeat("]", maxNestingDepth+1),
+	strings.Repeat(`{"a":`, maxNestingDepth+1),
+}
+
+func TestValidate(t *testing.T) {
+	for _, in := range validateTests {
+		want := checkValid([]byte(in), newScanner())
+		got := Validate([]byte(in))
+		if !equalSyntaxError(got, want) {
+			t.Errorf("Validate(%.40q):\n\tgot:  %#v\n\twant: %#v", in, got, want)
+		}
+		if ok := Valid([]byte(in)); ok != (want == nil) {
+			t.Errorf("Valid(%.40q) = %v, want %v", in, ok, want == nil)
+		}
+	}
+}
+
+func equalSyntaxError(got, want error) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	var gotErr, wantErr *SyntaxError
+	return errors.As(got, &gotErr) && errors.As(want, &wantErr) && *gotErr == *wantErr
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		CaseName
+		opts    ValidateOptions
+		in      string
+		wantErr error
+	}{{
+		CaseName: Name("depth within limit"),
+		opts:     ValidateOptions{MaxDepth: 2},
+		in:       `[{"a": 1}, []]`,
+	}, {
+		CaseName: Name("depth over limit"),
+		opts:     ValidateOptions{MaxDepth: 2},
+		in:       `[{"a": []}]`,
+		wantErr:  &SyntaxError{"invalid character '[' exceeded max depth", 8},
+	}, {
+		CaseName: Name("depth limit capped"),
+		opts:     ValidateOptions{MaxDepth: 1 << 30},
+		in:       strings.Repeat("[", maxNestingDepth+1),
+		wantErr:  &SyntaxError{"invalid character '[' exceeded max depth", maxNestingDepth + 1},
+	}, {
+		CaseName: Name("length within limit"),
+		opts:     ValidateOptions{MaxLength: 4},
+		in:       `null`,
+	}, {
+		CaseName: Name("length over limit"),
+		opts:     ValidateOptions{MaxLength: 4},
+		in:       `true `,
+		wantErr:  &SyntaxError{"input exceeded max length of 4 bytes", 4},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.opts.Validate([]byte(tt.in))
+			if !equalSyntaxError(err, tt.wantErr) {
+				t.Errorf("%s: Validate error:\n\tgot:  %v\n\twant: %v", tt.Where, err, tt.wantErr)
+			}
+			if ok := tt.opts.Valid([]byte(tt.in)); ok != (tt.wantErr == nil) {
+				t.Errorf("%s: Valid = %v, want %v", tt.Where, ok, tt.wantErr == nil)
+			}
+		})
+	}
+}
+
+func TestValidAllocs(t *testing.T) {
+	deep := []byte(strings.Repeat(`[{"a":`, maxNestingDepth/2) + "1" + strings.Repeat("}]", maxNestingDepth/2))
+	if !Valid(deep) {
+		t.Fatal("Valid = false, want true")
+	}
+	if n := testing.AllocsPerRun(10, func() { Valid(deep) }); n != 0 {
+		t.Errorf("Valid: got %v allocs, want 0", n)
+	}
+	// The error for invalid input costs the same however deep it is.
+	shallow := []byte(`[x`)
+	deep = []byte(strings.Repeat("[", maxNestingDepth) + "x")
+	want := testing.AllocsPerRun(10, func() { Validate(shallow) })
+	if n := testing.AllocsPerRun(10, func() { Validate(deep) }); n != want {
+		t.Errorf("Validate: got %v allocs, want %v", n, want)
+	}
+}
@@ -0,0 +1,45 @@
+package json
+
+import "slices"
+
+// View returns a copy of o that encodes the struct fields restricted to
+// the named view by the view tag option, as well as the fields without
+// the option. By default, and with a view named by no field, only the
+// fields without the option are encoded. See [Marshal].
+func (o MarshalOptions) View(name string) MarshalOptions {
+	o.view = name
+	return o
+}
+
+// fieldViews returns the fields of list to encode in each view named by
+// the view option of one of them, and under "", the fields to encode in
+// any other view, which are those without the option. It returns nil if
+// no field has the option, as then every view encodes all of list.
+//
+// The lists are built once for each struct type, so that choosing a view
+// costs a map lookup per struct encoded rather than a check per field.
+func fieldViews(list []field) map[string][]field {
+	var views map[string][]field
+	for _, f := range list {
+		for _, name := range f.views {
+			if _, ok := views[name]; !ok {
+				if views == nil {
+					views = make(map[string][]field)
+				}
+				views[name] = nil
+			}
+		}
+	}
+	if views == nil {
+		return nil
+	}
+	views[""] = nil
+	for _, f := range list {
+		for name := range views {
+			if f.views == nil || name != "" && slices.Contains(f.views, name) {
+				views[name] = append(views[name], f)
+			}
+		}
+	}
+	return views
+}
@@ -0,0 +1,70 @@
+package json
+
+import (
+	"strings"
+	"testing"
+)
+
+type viewUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email,view=admin|internal"`
+	Notes string `json:"notes,omitempty,view=internal"`
+	viewAudit
+}
+
+type viewAudit struct {
+	CreatedBy string `json:"createdBy,view=internal"`
+}
+
+func TestView(t *testing.T) {
+	in := []viewUser{{1, "ann", "ann@example.com", "vip", viewAudit{"bob"}}}
+	tests := []struct {
+		CaseName
+		opts MarshalOptions
+		want string
+	}{
+		{Name("default"), MarshalOptions{}, `[{"id":1,"name":"ann"}]`},
+		{Name("admin"), MarshalOptions{}.View("admin"), `[{"id":1,"name":"ann","email":"ann@example.com"}]`},
+		{Name("internal"), MarshalOptions{}.View("internal"), `[{"id":1,"name":"ann","email":"ann@example.com","notes":"vip","createdBy":"bob"}]`},
+		{Name("unknown view"), MarshalOptions{}.View("guest"), `[{"id":1,"name":"ann"}]`},
+		{Name("with mask"), MarshalOptions{Mask: FieldMask{"email", "name"}}.View("admin"), `[{"name":"ann","email":"ann@example.com"}]`},
+		{Name("last wins"), MarshalOptions{}.View("internal").View("admin"), `[{"id":1,"name":"ann","email":"ann@example.com"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			got, err := tt.opts.Marshal(in)
+			if err != nil {
+				t.Fatalf("%s: Marshal error: %v", tt.Where, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("%s: Marshal:\n\tgot:  %s\n\twant: %s", tt.Where, got, tt.want)
+			}
+		})
+	}
+
+	// Views do not restrict decoding.
+	var got viewUser
+	if err := Unmarshal([]byte(`{"id":2,"email":"e","createdBy":"c"}`), &got); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got.Email != "e" || got.CreatedBy != "c" {
+		t.Errorf("Unmarshal: got %+v, want Email and CreatedBy set", got)
+	}
+}
+
+func TestViewErrors(t *testing.T) {
+	for _, in := range []any{
+		struct {
+			A int `json:",view="`
+		}{},
+		struct {
+			A int `json:",view=a||b"`
+		}{},
+	} {
+		_, err := Marshal(in)
+		if err == nil || !strings.Contains(err.Error(), "empty view name in tag of field A") {
+			t.Errorf("Marshal(%T) error: got %v, want empty view name error", in, err)
+		}
+	}
+}
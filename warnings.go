@@ -0,0 +1,101 @@
+package json
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Sentinel errors found in a [Warning], other than [ErrUnknownField].
+var (
+	// ErrCaseInsensitiveMatch is reported for an object member whose key
+	// matches the name of a struct field only case-insensitively.
+	ErrCaseInsensitiveMatch = errors.New("json: key matched field name case-insensitively")
+
+	// ErrInvalidUTF8 is reported for an invalid UTF-8 encoding in a string,
+	// which decodes as U+FFFD.
+	ErrInvalidUTF8 = errors.New("json: invalid UTF-8 replaced by U+FFFD")
+
+	// ErrUnpairedSurrogate is reported for a \u escape for an unpaired UTF-16
+	// surrogate in a string, which decodes as U+FFFD.
+	ErrUnpairedSurrogate = errors.New("json: unpaired surrogate escape replaced by U+FFFD")
+
+	// ErrNumberOverflow is reported for a number that does not fit the type
+	// it is decoded into, which is clamped or wrapped to fit as selected by
+	// [UnmarshalOptions.Overflow].
+	ErrNumberOverflow = errors.New("json: number out of range adjusted to fit")
+)
+
+// A Warning describes an issue with the input to [UnmarshalOptions.Unmarshal]
+// that did not make decoding fail, recorded when the Warnings option is set.
+type Warning struct {
+	Path   string // JSON Pointer (RFC 6901) of the value in the input
+	Offset int64  // offset in the input of the value or of its object key
+	Err    error  // the issue, such as ErrUnknownField
+}
+
+func (w Warning) String() string {
+	return strings.TrimPrefix(w.Err.Error(), "json: ") + " at " + strconv.Quote(w.Path)
+}
+
+// warn records a warning for the value, or object key, at offset off.
+// Its path is filled in by resolveWarnings.
+func (d *decodeState) warn(off int, err error) {
+	if d.warnings != nil {
+		*d.warnings = append(*d.warnings, Warning{Offset: int64(off), Err: err})
+	}
+}
+
+// resolveWarnings sorts the warnings by offset and fills in their paths
+// in the valid JSON text data.
+func resolveWarnings(data []byte, warnings []Warning) {
+	slices.SortStableFunc(warnings, func(a, b Warning) int { return int(a.Offset - b.Offset) })
+	offs := make([]int, len(warnings))
+	for i, w := range warnings {
+		offs[i] = int(w.Offset)
+	}
+	for i, p := range pointersAt(data, offs) {
+		warnings[i].Path = p
+	}
+}
+
+// replacedText returns the offsets, in increasing order, of the \u escapes
+// for unpaired UTF-16 surrogates and of the invalid UTF-8 encodings in the
+// strings of the valid JSON text data, which decode as U+FFFD.
+func replacedText(data []byte) (surrogates, invalid []int) {
+	inString := false
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '"':
+			inString = !inString
+		case !inString:
+		case c == '\\' && data[i+1] == 'u':
+			r := getu4(data[i:])
+			if utf16.IsSurrogate(r) {
+				if r < 0xdc00 && utf16.DecodeRune(r, getu4(data[i+6:])) != utf8.RuneError {
+					i += 6
+				} else {
+					surrogates = append(surrogates, i)
+				}
+			}
+			i += 6
+			continue
+		case c == '\\':
+			i += 2
+			continue
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(data[i:])
+			if r == utf8.RuneError && size == 1 {
+				invalid = append(invalid, i)
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	return surrogates, invalid
+}
@@ -0,0 +1,78 @@
+package json
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWarnings(t *testing.T) {
+	type T struct {
+		Name  string `json:"name"`
+		Items []struct {
+			ID int `json:"id"`
+		} `json:"items"`
+	}
+	in := "{\"Name\": \"a\xffb\", \"extra\": 1, \"items\": [{\"id\": 1}, {\"ID\": 2, \"s\": \"\\ud800\"}]}"
+
+	var warnings []Warning
+	var v T
+	if err := (UnmarshalOptions{Warnings: &warnings}).Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := []Warning{
+		{"/Name", 1, ErrCaseInsensitiveMatch},
+		{"/Name", 11, ErrInvalidUTF8},
+		{"/extra", 16, ErrUnknownField},
+		{"/items/1/ID", 50, ErrCaseInsensitiveMatch},
+		{"/items/1/s", 59, ErrUnknownField},
+		{"/items/1/s", 65, ErrUnpairedSurrogate},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("Unmarshal warnings:\n\tgot:  %v\n\twant: %v", warnings, want)
+	}
+	if v.Name != "a\ufffdb" || len(v.Items) != 2 || v.Items[1].ID != 2 {
+		t.Errorf("Unmarshal: got %+v", v)
+	}
+
+	// Unknown fields are an error, not a warning, when disallowed.
+	warnings = warnings[:0]
+	opts := UnmarshalOptions{Warnings: &warnings, DisallowUnknownFields: true, RepairSurrogates: true}
+	if err := opts.Unmarshal([]byte(in), &v); !errors.Is(err, ErrUnknownField) {
+		t.Errorf("Unmarshal error: got %v, want %v", err, ErrUnknownField)
+	}
+	for _, w := range warnings {
+		if w.Err == ErrUnknownField {
+			t.Errorf("Unmarshal warning: got %v, want none for unknown fields", w)
+		}
+	}
+	if len(warnings) != 4 {
+		t.Errorf("Unmarshal warnings: got %d, want 4", len(warnings))
+	}
+}
+
+func TestUnmarshalWarningsTyped(t *testing.T) {
+	RegisterType("json.warningsTyped", warningsTyped{})
+	in := `{"x": {"$type": "json.warningsTyped", "value": {"a": 1, "b": 2}}}`
+	var warnings []Warning
+	var v map[string]any
+	opts := UnmarshalOptions{Warnings: &warnings, TypedInterfaces: true}
+	if err := opts.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := []Warning{{"/x/value/b", 56, ErrUnknownField}}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("Unmarshal warnings:\n\tgot:  %v\n\twant: %v", warnings, want)
+	}
+}
+
+type warningsTyped struct {
+	A int `json:"a"`
+}
+
+func TestWarningString(t *testing.T) {
+	w := Warning{"/a~1b", 3, ErrUnknownField}
+	if got, want := w.String(), `unknown field at "/a~1b"`; got != want {
+		t.Errorf("String:\n\tgot:  %s\n\twant: %s", got, want)
+	}
+}